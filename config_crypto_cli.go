@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+)
+
+// configCommands are helper commands run directly from the plugin binary, outside of Packer,
+// to manage passphrase encrypted KSM config files.
+var configCommands = map[string]func(content []byte, passphrase string) ([]byte, error){
+	"encrypt-config": encryptConfig,
+	"decrypt-config": keeper_datasource.DecryptConfig,
+}
+
+// runConfigCommand runs one of the config commands and returns the process exit code.
+// The passphrase is read from the same environment variables the datasources use.
+func runConfigCommand(name string, args []string) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	in := flags.String("in", "-", "path of the config to read, - reads from stdin")
+	out := flags.String("out", "-", "path to write the result to with 0600 permissions, - writes to stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [-in path] [-out path]\n\n", os.Args[0], name)
+		fmt.Fprintf(flags.Output(), "The passphrase is read from %s or the file descriptor set in %s.\n\n",
+			keeper_datasource.KEEPER_CONFIG_PASSPHRASE_ENV_KEY, keeper_datasource.KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := convertConfig(name, *in, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// convertConfig reads the config from in, runs the named command on it and writes the result to out.
+func convertConfig(name string, in string, out string) error {
+	var content []byte
+	var err error
+	if in == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(in)
+	}
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := keeper_datasource.GetConfigPassphrase()
+	if err != nil {
+		return err
+	}
+
+	result, err := configCommands[name](content, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if out == "-" {
+		_, err = os.Stdout.Write(result)
+		return err
+	}
+
+	return writePrivateFile(out, result)
+}
+
+// writePrivateFile writes content to path with 0600 permissions, whatever the permissions of the
+// file it replaces. The content is written to a temporary file next to path, which os.CreateTemp
+// creates with 0600 permissions, and renamed over it once complete. When path is a symlink its
+// target is replaced.
+func writePrivateFile(path string, content []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// encryptConfig encrypts a config, refusing to encrypt a config twice.
+func encryptConfig(content []byte, passphrase string) ([]byte, error) {
+	if keeper_datasource.IsEncryptedConfig(content) {
+		return nil, errors.New("config is already encrypted")
+	}
+
+	return keeper_datasource.EncryptConfig(content, passphrase)
+}
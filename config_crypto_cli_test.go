@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{"clientId": "client-id", "appKey": "app-key"}`
+
+// TestRunConfigCommandRoundTrip tests that a config encrypted with encrypt-config is restored by decrypt-config.
+func TestRunConfigCommandRoundTrip(t *testing.T) {
+	t.Setenv(keeper_datasource.KEEPER_CONFIG_PASSPHRASE_ENV_KEY, "correct horse")
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "config.json")
+	encrypted := filepath.Join(dir, "config.enc")
+	decrypted := filepath.Join(dir, "config.dec.json")
+	require.NoError(t, os.WriteFile(plain, []byte(testConfig), 0600))
+
+	require.Equal(t, 0, runConfigCommand("encrypt-config", []string{"-in", plain, "-out", encrypted}))
+
+	content, err := os.ReadFile(encrypted)
+	require.NoError(t, err)
+	assert.True(t, keeper_datasource.IsEncryptedConfig(content))
+
+	info, err := os.Stat(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.Equal(t, 0, runConfigCommand("decrypt-config", []string{"-in", encrypted, "-out", decrypted}))
+
+	content, err = os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testConfig, string(content))
+}
+
+// TestRunConfigCommandReplacesPermissions tests that decrypting over an existing world-readable file leaves the
+// plaintext config readable by the owner only.
+func TestRunConfigCommandReplacesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows doesn't have Unix permissions")
+	}
+	t.Setenv(keeper_datasource.KEEPER_CONFIG_PASSPHRASE_ENV_KEY, "correct horse")
+
+	dir := t.TempDir()
+	encrypted := filepath.Join(dir, "config.enc")
+	decrypted := filepath.Join(dir, "config.json")
+	content, err := keeper_datasource.EncryptConfig([]byte(testConfig), "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(encrypted, content, 0600))
+	require.NoError(t, os.WriteFile(decrypted, []byte("previous config"), 0644))
+	require.NoError(t, os.Chmod(decrypted, 0644))
+
+	require.Equal(t, 0, runConfigCommand("decrypt-config", []string{"-in", encrypted, "-out", decrypted}))
+
+	info, err := os.Stat(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	content, err = os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testConfig, string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+// TestRunConfigCommandFailures tests the exit codes of invalid flags, configs encrypted twice and wrong passphrases.
+func TestRunConfigCommandFailures(t *testing.T) {
+	t.Setenv(keeper_datasource.KEEPER_CONFIG_PASSPHRASE_ENV_KEY, "correct horse")
+
+	dir := t.TempDir()
+	encrypted := filepath.Join(dir, "config.enc")
+	content, err := keeper_datasource.EncryptConfig([]byte(testConfig), "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(encrypted, content, 0600))
+
+	assert.Equal(t, 2, runConfigCommand("encrypt-config", []string{"-unknown"}))
+	assert.Equal(t, 1, runConfigCommand("encrypt-config", []string{"-in", filepath.Join(dir, "missing.json")}))
+	assert.Equal(t, 1, runConfigCommand("encrypt-config", []string{"-in", encrypted, "-out", filepath.Join(dir, "twice.enc")}))
+	assert.NoFileExists(t, filepath.Join(dir, "twice.enc"))
+
+	t.Setenv(keeper_datasource.KEEPER_CONFIG_PASSPHRASE_ENV_KEY, "wrong")
+	assert.Equal(t, 1, runConfigCommand("decrypt-config", []string{"-in", encrypted, "-out", filepath.Join(dir, "config.json")}))
+	assert.NoFileExists(t, filepath.Join(dir, "config.json"))
+}
@@ -0,0 +1,267 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// certificateExtensions are the attachments read as certificates when no certificate_file is set.
+var certificateExtensions = []string{".pem", ".crt", ".cer", ".der", ".pfx", ".p12"}
+
+// Errors for reading certificates from attachments.
+var (
+	ErrCertificateNotFound    = errors.New("certificate attachment not found")
+	ErrInvalidCertificate     = errors.New("invalid certificate")
+	ErrCertificateExpired     = errors.New("certificate expired")
+	ErrCertificateExpiresSoon = errors.New("certificate expires within min_days_valid")
+)
+
+// CertificateOptions select the certificate attachment of a record and how long it must stay valid.
+type CertificateOptions struct {
+	// File is the name or glob of the attachment holding the certificate. When empty the first
+	// attachment with a certificate extension is used.
+	File string
+	// MinDaysValid fails reading certificates expiring within this many days.
+	MinDaysValid int
+}
+
+// certificateBundle is a certificate parsed from an attachment along with the certificates and
+// private key stored with it.
+type certificateBundle struct {
+	leaf  *x509.Certificate
+	chain []*x509.Certificate
+	// keyPEM is the PEM encoded private key, kept as stored when it is encrypted.
+	keyPEM []byte
+}
+
+// GetCertificate retrieves the certificate attached to a Keeper record. The password field of the
+// record decrypts PKCS#12 bundles.
+func (k *KSMClient) GetCertificate(record *ksm.Record, options CertificateOptions) (*KeeperCertificate, error) {
+	file, err := findCertificateFile(record, options.File)
+	if err != nil {
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	data, err := downloadFile(k.transport, file, 0)
+	if err != nil {
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	bundle, err := parseCertificate(data, getFieldValue(record, PASSWORD_FIELD_TYPE))
+	if err != nil {
+		return nil, fmt.Errorf("record %s: attachment %s: %w", record.Uid, file.Name, err)
+	}
+
+	now := time.Now
+	if k.now != nil {
+		now = k.now
+	}
+
+	at := now()
+	if err := checkExpiry(bundle.leaf, at, options.MinDaysValid); err != nil {
+		return nil, fmt.Errorf("record %s: attachment %s: %w", record.Uid, file.Name, err)
+	}
+
+	return newKeeperCertificate(record, file.Name, bundle, at), nil
+}
+
+// findCertificateFile returns the attachment matching pattern, or the first attachment with a
+// certificate extension when pattern is empty.
+func findCertificateFile(r *ksm.Record, pattern string) (*ksm.KeeperFile, error) {
+	for _, f := range r.Files {
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, f.Name); ok || f.Name == pattern {
+				return f, nil
+			}
+			continue
+		}
+
+		if slices.Contains(certificateExtensions, strings.ToLower(filepath.Ext(f.Name))) {
+			return f, nil
+		}
+	}
+
+	if pattern != "" {
+		return nil, fmt.Errorf("%w: no attachment matches certificate_file %q", ErrCertificateNotFound, pattern)
+	}
+	return nil, fmt.Errorf("%w: no attachment has a %s extension, set certificate_file", ErrCertificateNotFound, strings.Join(certificateExtensions, ", "))
+}
+
+// parseCertificate parses PEM, DER or PKCS#12 encoded certificates. PKCS#12 bundles are decrypted
+// with the password.
+func parseCertificate(data []byte, password string) (*certificateBundle, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		var blocks []*pem.Block
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			blocks = append(blocks, block)
+		}
+
+		return parsePEMBlocks(blocks)
+	}
+
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		return &certificateBundle{leaf: certs[0], chain: certs[1:]}, nil
+	}
+
+	return parsePKCS12(data, password)
+}
+
+// parsePKCS12 decrypts a PKCS#12 bundle with the password. Bundles holding a private key return
+// the certificate of the key as the leaf, bundles of certificates only return the first one.
+func parsePKCS12(data []byte, password string) (*certificateBundle, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil && !errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		// Bundles without a private key can only be read as trust stores.
+		if certs, trustErr := pkcs12.DecodeTrustStore(data, password); trustErr == nil && len(certs) > 0 {
+			return &certificateBundle{leaf: certs[0], chain: certs[1:]}, nil
+		}
+	}
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, fmt.Errorf("%w: the password field of the record doesn't decrypt the PKCS#12 bundle", ErrInvalidCertificate)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a PEM, DER or PKCS#12 certificate: %s", ErrInvalidCertificate, err)
+	}
+
+	// PKCS#12 keys are returned in PKCS#8 format whatever their type.
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported private key: %s", ErrInvalidCertificate, err)
+	}
+
+	return &certificateBundle{
+		leaf:   leaf,
+		chain:  chain,
+		keyPEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// parsePEMBlocks parses the certificates and private key of PEM blocks. The leaf is the
+// certificate of the private key, or the first certificate when there's no key.
+func parsePEMBlocks(blocks []*pem.Block) (*certificateBundle, error) {
+	var certs []*x509.Certificate
+	var key crypto.PrivateKey
+	bundle := &certificateBundle{}
+
+	for _, block := range blocks {
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidCertificate, err)
+			}
+			certs = append(certs, cert)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY") && bundle.keyPEM == nil:
+			bundle.keyPEM = pem.EncodeToMemory(block)
+			key = parsePEMPrivateKey(block)
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: no certificate in the attachment", ErrInvalidCertificate)
+	}
+
+	bundle.leaf = certs[0]
+	if signer, ok := key.(crypto.Signer); ok {
+		for _, cert := range certs {
+			if pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); ok && pub.Equal(cert.PublicKey) {
+				bundle.leaf = cert
+				break
+			}
+		}
+	}
+
+	for _, cert := range certs {
+		if cert != bundle.leaf {
+			bundle.chain = append(bundle.chain, cert)
+		}
+	}
+
+	return bundle, nil
+}
+
+// parsePEMPrivateKey parses an unencrypted private key block, nil when it can't be parsed.
+func parsePEMPrivateKey(block *pem.Block) crypto.PrivateKey {
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	return nil
+}
+
+// checkExpiry fails for certificates expired at now or expiring within minDaysValid days of it.
+func checkExpiry(cert *x509.Certificate, now time.Time, minDaysValid int) error {
+	notAfter := cert.NotAfter.UTC().Format(time.RFC3339)
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("%w on %s", ErrCertificateExpired, notAfter)
+	}
+
+	if minDaysValid > 0 && cert.NotAfter.Before(now.AddDate(0, 0, minDaysValid)) {
+		return fmt.Errorf("%w: it expires on %s, less than %d days from now", ErrCertificateExpiresSoon, notAfter, minDaysValid)
+	}
+
+	return nil
+}
+
+// newKeeperCertificate returns the outputs of a certificate read from the attachment of a record.
+func newKeeperCertificate(r *ksm.Record, fileName string, bundle *certificateBundle, now time.Time) *KeeperCertificate {
+	leaf := bundle.leaf
+
+	sans := slices.Clone(leaf.DNSNames)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, leaf.EmailAddresses...)
+	for _, uri := range leaf.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	var chain bytes.Buffer
+	for _, cert := range bundle.chain {
+		chain.Write(encodeCertificate(cert))
+	}
+
+	sha1Sum := sha1.Sum(leaf.Raw)
+	sha256Sum := sha256.Sum256(leaf.Raw)
+
+	return &KeeperCertificate{
+		KeeperRecordField: *getRecordFields(r),
+		FileName:          fileName,
+		Subject:           leaf.Subject.String(),
+		Issuer:            leaf.Issuer.String(),
+		SerialNumber:      fmt.Sprintf("%X", leaf.SerialNumber),
+		SANs:              sans,
+		NotBefore:         leaf.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:          leaf.NotAfter.UTC().Format(time.RFC3339),
+		DaysRemaining:     int(leaf.NotAfter.Sub(now).Hours() / 24),
+		ThumbprintSHA1:    fmt.Sprintf("%X", sha1Sum),
+		ThumbprintSHA256:  fmt.Sprintf("%X", sha256Sum),
+		Certificate:       string(encodeCertificate(leaf)),
+		Chain:             chain.String(),
+		PrivateKey:        string(bundle.keyPEM),
+	}
+}
+
+// encodeCertificate PEM encodes a certificate.
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
@@ -0,0 +1,218 @@
+package keeper_datasource
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPKCS12 is a PKCS#12 bundle of a self-signed certificate for winrm.example.com expiring in 2126, encrypted with
+// the password hunter2 (openssl pkcs12 -export -legacy).
+const testPKCS12 = "MIID2gIBAzCCA6AGCSqGSIb3DQEHAaCCA5EEggONMIIDiTCCAn8GCSqGSIb3DQEHBqCCAnAwggJsAgEAMIICZQYJKoZIhvcNAQcB" +
+	"MBwGCiqGSIb3DQEMAQYwDgQI0YcLbIMJRoMCAggAgIICOCpfWlD7PuuYRxUwJbVtuqtHruASphf/N40ZtSdwLXa7Rtc9AD796j7U" +
+	"i+nnQ96hIX6Xj/HMZJp/d8TFYOvJKPO/ujg0XFI2k+GK+ewuW1/5CYiRQNBKjEIC8vMmRcI4ydN50w33nmeZhqj1wbfw8+drEb/u" +
+	"O9yjfPYg2vByGjGQIipCJetsv5V+x2zTns2R3Adf6T+kMUE1KxLY/HkZCWEyHV0cqbe4wSwQiUYJrDhcvHbc0IgoPreqGn7nJi3/" +
+	"VmRo7juwhIyJ6zF8NC1PubUxutOBGkoNMoxT3LhovsuTVCFcKha7VFXCwUdfQRmqqc6uyCpSyJLxjA2qbcmhjDCn6RW6EQ17yZb3" +
+	"LHGDtTEQ0l4/ApteMgL6Dn3gg1k/IjfbFI+nG/KFiPCeUUcX12fRiSdfmFIkMmegH264C7okPJV8+Lj/rsnJzVW/P5FPVu4B3B3m" +
+	"1ZqJEPktpUrZHhqlOZz+fsuionrlreko5vXr5zHc0LWwyEaB+esl3Kau0+AESuG81pqy5mxhOWLmQJmfzOiXReVZt2TY26gbOjYU" +
+	"VWKWyR0nBSmQ0JlXN3ZV0GTux6l+Uj3V4chKHuXzGB9JDRJy17p+kJZJPIVtVHzUkxb5PXeW1NOoBWxFHfoWnG2xBf9e4UpH9HAz" +
+	"V0dOdKSoGQYFJG5L/Qpgrm7CIEOfYst+VWedQh/jwJrMXh8IuiWNd0ioKX1okawAFfcPjyR45XN/BkDwjHARKGGYCAh9qXj0+xsX" +
+	"gMAwggECBgkqhkiG9w0BBwGggfQEgfEwge4wgesGCyqGSIb3DQEMCgECoIG0MIGxMBwGCiqGSIb3DQEMAQMwDgQIGzuAeTOEP00C" +
+	"AggABIGQbo5KfySCZ4JJelo4049M5Z1jIf3CyvpZS7At9jVAAwA3SIYntlg+gOoWRUNBpsNFEoSN7QYJRsDScsAHRrptr8FRsGB7" +
+	"N0iJxv5UQGqQFb5RobgsFm0LMRbgd+87GnFNZvk7QiEqQ7gUZVMyXLqXZBmb7tzGecyCML+bhdfZ8dBYprpGI0E7r8gvlNiiALew" +
+	"MSUwIwYJKoZIhvcNAQkVMRYEFNl/4kFwGNMnSjZIeljUsN/AbDNnMDEwITAJBgUrDgMCGgUABBRs8+5xR1hTFnlzectOHIsfca68" +
+	"3wQIrE4PhLwJfegCAggA"
+
+// testPKCS12AES is the same bundle encrypted with the default algorithms of OpenSSL 3 (openssl pkcs12 -export):
+// AES-256-CBC with PBKDF2 and a SHA-256 MAC.
+const testPKCS12AES = "MIIEbAIBAzCCBCIGCSqGSIb3DQEHAaCCBBMEggQPMIIECzCCAsIGCSqGSIb3DQEHBqCCArMwggKvAgEAMIICqAYJKoZIhvcNAQcB" +
+	"MFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAgncgf5TByIEwICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEECBx" +
+	"eFwKOryaGB+c+qzEr2yAggJAw8ZrQNVW/tOisxOLJbHybC0OyaPAVXkQLMXixt8e4OU+ZwUKKq2D8e4c7uTwdqpXIeg7tjaOLb6A" +
+	"KKXSBXskZDTrxZ7k8Nqzus8yp5OFx7PsqFBoVMV6TFHk1z3U4N88lvClnm5Rl48dfKSpFUEdUqKc10lq+4pG3EFWZBl4JYIEs2d1" +
+	"qIQQxoAhKaM5WQsllb8cJ4j4vvE0Ay+47HB8JTjQ/UOGUQgg524lkKL4YXxRjQBfu6Olnu/JVHNRp4KkfMVzYMHlygovKGLSoexe" +
+	"70egxzv9hYymSt067H2AP4TU0Y6U5ruRpaFLquxtwZy8jLK/rSwPQFfw3KDu8o5oN8U0i/WzXUpl/Lrr4Wgdj4INIBTLFwlLHYdt" +
+	"MDI4Gvaz4hkmaubnhEuahKPGtHrrv4nAPje33aejC5UxiOIBewoDcJk1IVfj5D51c7WQk1J+TAGVpYpt6IHgJpOFPS7EmgoaMwUd" +
+	"hm6Dh4HDXnmHm/CtccGt9qg4eG3NfSizZmVY//D72BG7oTlR81vFi+RcMe4VUIoC0bvKcOPylphZkdQE42nNbLWARl9mIY+ycnKT" +
+	"fHj82qc1azKviFCOsIPei5azmiJCZMwWjaYTQvLEdLRWRbfKrY2/pMlZYIxM/3Owm0ck+lGKMvje/a7tM+IzJLQtvCzEN8x6KK2L" +
+	"fjhPuhI1JVa4SCiHQfBLwCiqwgLUUVsuMyVTvj83Fyvyxp3IgL+ixiffkLIUXP2lVzc2GeMTXIgOPe0uDgtW/M9aHnhiMIIBQQYJ" +
+	"KoZIhvcNAQcBoIIBMgSCAS4wggEqMIIBJgYLKoZIhvcNAQwKAQKgge8wgewwVwYJKoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwE" +
+	"CF8sK3E5RNh2AgIIADAMBggqhkiG9w0CCQUAMB0GCWCGSAFlAwQBKgQQ8Ky5w3a9o700ftzv+gPR/ASBkCpAzUgFY63O/xfi1tM6" +
+	"1+0debunwL4zi5fGTcfggnmiQU4uPKt90v2QYeyYzWki27ViPz50nOalF2Mxr1e/HRC4/Hh6ccEFbWLUrULe7byzG9geBXsVfB0u" +
+	"9J9AMIQL6TyRk7vXqCsK1RYIAHM8g3jE3nsy2v081MRCLqAM3ERficlCgxNA4vby9gVpK3rn1zElMCMGCSqGSIb3DQEJFTEWBBTZ" +
+	"f+JBcBjTJ0o2SHpY1LDfwGwzZzBBMDEwDQYJYIZIAWUDBAIBBQAEILqJNTFd4TAce3B3BzP4x+6bcVOthPoTASFHkdmE8zfNBAhv" +
+	"hB3tQ8jG5gICCAA="
+
+// testCertificateNow is the time certificates are checked at in the tests.
+var testCertificateNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestCertificate returns a certificate for build.example.com expiring at notAfter, issued by the CA when it is set.
+func newTestCertificate(t *testing.T, notAfter time.Time, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x1a2b),
+		Subject:      pkix.Name{CommonName: "build.example.com", Organization: []string{"Example"}},
+		NotBefore:    testCertificateNow.AddDate(0, -1, 0),
+		NotAfter:     notAfter,
+		DNSNames:     []string{"build.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.5")},
+	}
+	if ca == nil {
+		template.Subject = pkix.Name{CommonName: "Example CA"}
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca, caKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// newCertificateClient returns a client reading a record with the attachments, keyed by name, and password.
+func newCertificateClient(attachments map[string][]byte, password string) *PackerKeeperClient {
+	record := recordFromJSON(fmt.Sprintf(`{"uid": "cert-uid", "title": "winrm", "type": "login", "fields": [{"type": "password", "value": [%q]}]}`, password))
+	for name, content := range attachments {
+		record.Files = append(record.Files, &ksm.KeeperFile{Uid: name + "-uid", Name: name, Size: len(content), FileData: content})
+	}
+
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{now: func() time.Time { return testCertificateNow }}}
+	mockClient.On("GetSecret").Return(record, nil)
+	return NewClient(mockClient)
+}
+
+func pemCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// TestGetCertificatePEM tests that the leaf, chain and private key of a PEM bundle are extracted, whatever their order.
+func TestGetCertificatePEM(t *testing.T) {
+	ca, caKey := newTestCertificate(t, testCertificateNow.AddDate(10, 0, 0), nil, nil)
+	leaf, key := newTestCertificate(t, testCertificateNow.AddDate(0, 0, 90), ca, caKey)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	bundle := append(append(pemCertificate(ca), pemCertificate(leaf)...), keyPEM...)
+	client := newCertificateClient(map[string][]byte{"readme.txt": []byte("readme"), "winrm.pem": bundle}, "")
+
+	cert, err := client.GetCertificate(RecordQuery{Uid: "cert-uid"}, CertificateOptions{MinDaysValid: 30})
+	require.NoError(t, err)
+
+	assert.Equal(t, "winrm.pem", cert.FileName)
+	assert.Equal(t, "CN=build.example.com,O=Example", cert.Subject)
+	assert.Equal(t, "CN=Example CA", cert.Issuer)
+	assert.Equal(t, "1A2B", cert.SerialNumber)
+	assert.Equal(t, []string{"build.example.com", "10.0.0.5"}, cert.SANs)
+	assert.Equal(t, "2025-12-01T00:00:00Z", cert.NotBefore)
+	assert.Equal(t, "2026-04-01T00:00:00Z", cert.NotAfter)
+	assert.Equal(t, 90, cert.DaysRemaining)
+	assert.Equal(t, fmt.Sprintf("%X", sha1.Sum(leaf.Raw)), cert.ThumbprintSHA1)
+	assert.Len(t, cert.ThumbprintSHA256, 64)
+	assert.Equal(t, string(pemCertificate(leaf)), cert.Certificate)
+	assert.Equal(t, string(pemCertificate(ca)), cert.Chain)
+	assert.Equal(t, string(keyPEM), cert.PrivateKey)
+	assert.Equal(t, "winrm", cert.Title)
+}
+
+// TestGetCertificateDERAndPKCS12 tests that DER certificates and PKCS#12 bundles decrypted with the password of the
+// record are read.
+func TestGetCertificateDERAndPKCS12(t *testing.T) {
+	leaf, _ := newTestCertificate(t, testCertificateNow.AddDate(1, 0, 0), nil, nil)
+
+	client := newCertificateClient(map[string][]byte{"winrm.cer": leaf.Raw}, "")
+	cert, err := client.GetCertificate(RecordQuery{Uid: "cert-uid"}, CertificateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, string(pemCertificate(leaf)), cert.Certificate)
+	assert.Empty(t, cert.PrivateKey)
+
+	// Bundles in the legacy and the default OpenSSL 3 formats are both read.
+	for name, bundle := range map[string]string{"legacy": testPKCS12, "aes": testPKCS12AES} {
+		pfx, err := base64.StdEncoding.DecodeString(bundle)
+		require.NoError(t, err)
+
+		client = newCertificateClient(map[string][]byte{"winrm.cer": leaf.Raw, "winrm.pfx": pfx}, "hunter2")
+		cert, err = client.GetCertificate(RecordQuery{Uid: "cert-uid"}, CertificateOptions{File: "*.pfx"})
+		require.NoError(t, err, name)
+		assert.Equal(t, "CN=winrm.example.com,O=Example", cert.Subject, name)
+		assert.Equal(t, []string{"winrm.example.com", "10.0.0.5"}, cert.SANs, name)
+		assert.Equal(t, "D97FE2417018D3274A36487A58D4B0DFC06C3367", cert.ThumbprintSHA1, name)
+		assert.Equal(t, "2126-09-24T09:11:53Z", cert.NotAfter, name)
+
+		// PKCS#12 keys are returned in PKCS#8 format.
+		block, _ := pem.Decode([]byte(cert.PrivateKey))
+		require.NotNil(t, block, name)
+		assert.Equal(t, "PRIVATE KEY", block.Type, name)
+		_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		require.NoError(t, err, name)
+
+		client = newCertificateClient(map[string][]byte{"winrm.pfx": pfx}, "wrong")
+		_, err = client.GetCertificate(RecordQuery{Uid: "cert-uid"}, CertificateOptions{})
+		require.ErrorIs(t, err, ErrInvalidCertificate, name)
+	}
+}
+
+// TestGetCertificateErrors tests that missing, invalid and expiring certificates fail the datasource.
+func TestGetCertificateErrors(t *testing.T) {
+	expiring, _ := newTestCertificate(t, testCertificateNow.AddDate(0, 0, 10), nil, nil)
+	expired, _ := newTestCertificate(t, testCertificateNow.AddDate(0, 0, -1), nil, nil)
+
+	client := newCertificateClient(map[string][]byte{
+		"expiring.crt": pemCertificate(expiring),
+		"expired.crt":  pemCertificate(expired),
+		"invalid.crt":  []byte("not a certificate"),
+		"notes.txt":    []byte("notes"),
+	}, "")
+
+	tests := map[string]struct {
+		options CertificateOptions
+		err     error
+	}{
+		"expired":           {CertificateOptions{File: "expired.crt"}, ErrCertificateExpired},
+		"expires soon":      {CertificateOptions{File: "expiring.crt", MinDaysValid: 30}, ErrCertificateExpiresSoon},
+		"invalid":           {CertificateOptions{File: "invalid.crt"}, ErrInvalidCertificate},
+		"not a cert":        {CertificateOptions{File: "notes.txt"}, ErrInvalidCertificate},
+		"missing":           {CertificateOptions{File: "*.pfx"}, ErrCertificateNotFound},
+		"valid long enough": {CertificateOptions{File: "expiring.crt", MinDaysValid: 5}, nil},
+	}
+
+	for name, test := range tests {
+		_, err := client.GetCertificate(RecordQuery{Uid: "cert-uid"}, test.options)
+		if test.err == nil {
+			assert.NoError(t, err, name)
+			continue
+		}
+		assert.ErrorIs(t, err, test.err, name)
+	}
+
+	noCertificates := newCertificateClient(map[string][]byte{"notes.txt": []byte("notes")}, "")
+	_, err := noCertificates.GetCertificate(RecordQuery{Uid: "cert-uid"}, CertificateOptions{})
+	require.ErrorIs(t, err, ErrCertificateNotFound)
+
+	// Expired certificates are reported with a hint for renewing them.
+	kind, hint := classifyError(fmt.Errorf("%w", ErrCertificateExpired), "")
+	assert.Equal(t, ErrCertificateExpired, kind)
+	assert.Contains(t, hint, "renew the certificate")
+
+	kind, hint = classifyError(fmt.Errorf("%w", ErrCertificateExpiresSoon), "")
+	assert.Equal(t, ErrCertificateExpiresSoon, kind)
+	assert.Contains(t, hint, "lower min_days_valid")
+}
@@ -0,0 +1,105 @@
+package keeper_datasource
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// FileCleanupCommand is the argument the plugin binary is started with to run the process removing
+// the attachments written by datasources that didn't set keep.
+const FileCleanupCommand = "cleanup-files"
+
+// fileCleanup is the process removing the attachments written without keep. Packer kills plugin
+// processes at the end of the build without letting them clean up, so the attachments are removed
+// by a separate process instead. It reads the paths to remove from a pipe and removes them once the
+// pipe is closed, which the operating system does when the plugin exits however it is stopped.
+var fileCleanup = struct {
+	sync.Mutex
+	cmd   *exec.Cmd
+	paths io.WriteCloser
+}{}
+
+// registerCleanup sends the path of a file to the cleanup process, starting it on first use.
+func registerCleanup(p string) error {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+
+	fileCleanup.Lock()
+	defer fileCleanup.Unlock()
+
+	if fileCleanup.cmd == nil {
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.Command(executable, FileCleanupCommand)
+		paths, err := cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		fileCleanup.cmd, fileCleanup.paths = cmd, paths
+	}
+
+	// Paths are quoted so names holding line breaks are read back whole.
+	_, err = fmt.Fprintln(fileCleanup.paths, strconv.Quote(p))
+	return err
+}
+
+// CleanupFiles removes the attachments written to disk by datasources that didn't set keep right
+// away, waiting for the cleanup process to remove them. It is called when the plugin exits normally.
+func CleanupFiles() {
+	fileCleanup.Lock()
+	defer fileCleanup.Unlock()
+
+	if fileCleanup.cmd == nil {
+		return
+	}
+
+	fileCleanup.paths.Close()
+	if err := fileCleanup.cmd.Wait(); err != nil {
+		log.Printf("[WARN] unable to remove the attachments written to disk: %s", err)
+	}
+	fileCleanup.cmd, fileCleanup.paths = nil, nil
+}
+
+// RunFileCleanup runs the cleanup process: the paths read from r, one per line, are removed once r
+// is closed. It returns the process exit code.
+func RunFileCleanup(r io.Reader) int {
+	// Interrupts are sent to every process of the terminal, the plugin is still running then.
+	signal.Ignore(os.Interrupt, syscall.SIGTERM)
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if p, err := strconv.Unquote(scanner.Text()); err == nil {
+			paths = append(paths, p)
+		}
+	}
+
+	code := 0
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "unable to remove attachment %s: %s\n", p, err)
+			code = 1
+		}
+	}
+
+	return code
+}
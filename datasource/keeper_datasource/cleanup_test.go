@@ -0,0 +1,63 @@
+package keeper_datasource
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as the cleanup process when started by registerCleanup, as the
+// plugin binary does.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == FileCleanupCommand {
+		os.Exit(RunFileCleanup(os.Stdin))
+	}
+
+	os.Exit(m.Run())
+}
+
+// TestFileCleanupHelperProcess isn't a real test, it acts as a plugin writing an attachment for the test below.
+func TestFileCleanupHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	client := newAttachmentsClient(map[string]string{"id_rsa": "private key"})
+	if _, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: os.Args[len(os.Args)-1], Mode: defaultFileMode}}); err != nil {
+		os.Exit(1)
+	}
+
+	// Tell the test the attachment was written, then wait to be killed like Packer does.
+	os.Stdout.WriteString("written\n")
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// TestCleanupFilesAfterKill tests that attachments are removed once the plugin that wrote them is killed.
+func TestCleanupFilesAfterKill(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=TestFileCleanupHelperProcess", "--", dir)
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "written\n", line)
+	assert.FileExists(t, filepath.Join(dir, "id_rsa"))
+
+	require.NoError(t, cmd.Process.Kill())
+	cmd.Wait()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "id_rsa"))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 50*time.Millisecond, "the attachment must be removed once the plugin is killed")
+}
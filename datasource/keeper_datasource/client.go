@@ -1,122 +1,357 @@
 package keeper_datasource
 
 import (
-	"sync"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
 )
 
+// Errors for resolving a record from a query.
 var (
-	globalPackerSecretsManager *PackerKeeperClient
-	once                       sync.Once
+	ErrRecordNotFound = errors.New("no record found")
+	ErrAmbiguousTitle = errors.New("title matches more than one record, set folder_uid or record_type to narrow the match or use uid instead")
 )
 
+// RecordQuery identifies the record a datasource reads. Either Uid or Title is set,
+// FolderUid and RecordType narrow down the records matching Title.
+type RecordQuery struct {
+	Uid        string
+	Title      string
+	FolderUid  string
+	RecordType string
+	// Field selects the entry of a multi-value field feeding the scalar output of typed datasources.
+	Field FieldSelector
+	// AcceptTypes are the record types typed datasources accept besides their own.
+	AcceptTypes []string
+	// AnyType makes typed datasources accept records of any type, reading whichever of their fields the record has.
+	AnyType bool
+	// Files controls how the attachments of the record are written to disk.
+	Files FileOptions
+
+	// expectedType is the record type of the typed datasource reading the record.
+	expectedType string
+}
+
 // PackerKeeperClient is a wrapper around the KeeperClient interface
 type PackerKeeperClient struct {
 	KeeperClient KeeperClient
+	// fetcher batches the records requested by datasources running at the same time.
+	fetcher *recordFetcher
+	// transport downloads attachments, the default HTTP transport is used when nil.
+	transport http.RoundTripper
 }
 
 // NewClient creates a new PackerKeeperClient
 func NewClient(c KeeperClient) *PackerKeeperClient {
+	return newClient(c, nil)
+}
+
+// newClient creates a new PackerKeeperClient downloading attachments through transport.
+func newClient(c KeeperClient, transport http.RoundTripper) *PackerKeeperClient {
 	return &PackerKeeperClient{
 		KeeperClient: c,
+		fetcher:      newRecordFetcher(c),
+		transport:    transport,
 	}
 }
 
-// GetSecretClient is a singleton Keeper client that is shared
-// across datasources. This is to prevent each datasource call in
+// GetSecretClient returns a Keeper client for the given config from the default registry.
+// Clients are shared across datasources. This is to prevent each datasource call in
 // a Packer build to reinitialize the client.
-func GetSecretClient() (*PackerKeeperClient, error) {
-	// error to return if initialization fails
-	var initError error
-	once.Do(func() {
-		// We initialize a real client here, but we could also modify this function
-		// to pass in a mock client for testing purposes.
-		sc, err := NewKeeperSecretClient()
+func GetSecretClient(config ClientConfig) (*PackerKeeperClient, error) {
+	return DefaultRegistry.Get(config)
+}
+
+// withType narrows a title lookup to the record types a typed datasource of recordType accepts,
+// and makes GetRecord reject records of other types.
+func (q RecordQuery) withType(recordType string) RecordQuery {
+	q.expectedType = recordType
+	return q
+}
+
+// acceptsType reports whether the datasource reading the query accepts records of the type.
+func (q RecordQuery) acceptsType(recordType string) bool {
+	if q.expectedType == "" || q.AnyType || recordType == q.expectedType {
+		return true
+	}
+
+	return slices.Contains(q.AcceptTypes, recordType)
+}
+
+// typeFilter describes the record types a title lookup matches, for errors.
+func (q RecordQuery) typeFilter() string {
+	if q.RecordType != "" || q.expectedType == "" || q.AnyType {
+		return q.RecordType
+	}
+
+	return strings.Join(append([]string{q.expectedType}, q.AcceptTypes...), ", ")
+}
+
+// GetRecord retrieves the record matching the query. Records looked up by title must
+// resolve to exactly one record, otherwise the error lists the candidate UIDs. Typed
+// datasources only get records of a type they accept.
+func (c *PackerKeeperClient) GetRecord(query RecordQuery) (*ksm.Record, error) {
+	if query.Uid != "" {
+		records, err := c.fetcher.Get(query.Uid)
 		if err != nil {
-			initError = err
-			return
+			return nil, err
+		}
+
+		r := records[0]
+		if !query.acceptsType(r.Type()) {
+			return nil, fmt.Errorf("%w Uid: %s ExpectedType: %s, ActualType: %s", ErrWrongRecordType, r.Uid, query.expectedType, r.Type())
+		}
+		return r, nil
+	}
+
+	records, err := c.KeeperClient.GetSecretsByTitle(query.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*ksm.Record{}
+	for _, r := range records {
+		if query.FolderUid != "" && r.FolderUid() != query.FolderUid {
+			continue
+		}
+		if query.RecordType != "" && r.Type() != query.RecordType {
+			continue
+		}
+		if !query.acceptsType(r.Type()) {
+			continue
+		}
+		matches = append(matches, r)
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		// Records with the title that were filtered out are likely what the user meant.
+		if len(records) > 0 {
+			return nil, fmt.Errorf("%w with title %q in folder_uid %q with record_type %q, candidates: %s", ErrRecordNotFound, query.Title, query.FolderUid, query.typeFilter(), describeRecords(records))
 		}
+		return nil, fmt.Errorf("%w with title %q", ErrRecordNotFound, query.Title)
+	default:
+		return nil, fmt.Errorf("%w, title %q matches: %s", ErrAmbiguousTitle, query.Title, describeRecords(matches))
+	}
+}
+
+// describeRecords lists the UIDs of records along with their folder and type.
+func describeRecords(records []*ksm.Record) string {
+	descriptions := make([]string, 0, len(records))
+	for _, r := range records {
+		descriptions = append(descriptions, fmt.Sprintf("%s (folder_uid: %q, record_type: %q)", r.Uid, r.FolderUid(), r.Type()))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// GetServerCredentials retrieves the server credentials for the record matching the query
+func (c *PackerKeeperClient) GetServerCredentials(query RecordQuery) (*KeeperServerCredentials, error) {
+	r, err := c.GetRecord(query.withType(SERVER_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := c.KeeperClient.GetServerCredentials(r, query.Field)
+	if err != nil {
+		return nil, err
+	}
+	return creds, c.downloadFiles(r, &creds.KeeperRecordField, query.Files)
+}
 
-		// Set the global variable
-		globalPackerSecretsManager = &PackerKeeperClient{KeeperClient: sc}
-	})
+// GetDatabaseCredentials retrieves the database credentials for the record matching the query
+func (c *PackerKeeperClient) GetDatabaseCredentials(query RecordQuery) (*KeeperDataBaseCredentials, error) {
+	r, err := c.GetRecord(query.withType(DATABASE_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	creds, err := c.KeeperClient.GetDatabaseCredentials(r, query.Field)
+	if err != nil {
+		return nil, err
+	}
+	return creds, c.downloadFiles(r, &creds.KeeperRecordField, query.Files)
+}
+
+// GetAPIKey retrieves the API key for the record matching the query
+func (c *PackerKeeperClient) GetAPIKey(query RecordQuery) (*KeeperAPIKey, error) {
+	r, err := c.GetRecord(query.withType(API_KEY_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := c.KeeperClient.GetAPIKey(r)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, c.downloadFiles(r, &apiKey.KeeperRecordField, query.Files)
+}
 
-	// If there were any errors initializing the client return nil and fail.
-	if initError != nil {
-		return nil, initError
+// GetEncryptedNote retrieves the encrypted note for the record matching the query
+func (c *PackerKeeperClient) GetEncryptedNote(query RecordQuery) (*KeeperEncryptedNote, error) {
+	r, err := c.GetRecord(query.withType(ENCRYPTED_NOTE_FIELD_TYPE))
+	if err != nil {
+		return nil, err
 	}
+	note, err := c.KeeperClient.GetEncryptedNote(r)
+	if err != nil {
+		return nil, err
+	}
+	return note, c.downloadFiles(r, &note.KeeperRecordField, query.Files)
+}
 
-	return globalPackerSecretsManager, nil
+// GetFile retrieves the file for the record matching the query
+func (c *PackerKeeperClient) GetFile(query RecordQuery) (*KeeperFile, error) {
+	r, err := c.GetRecord(query.withType(FILE_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.KeeperClient.GetFile(r)
+	if err != nil {
+		return nil, err
+	}
+	return file, c.downloadFiles(r, &file.KeeperRecordField, query.Files)
 }
 
-// GetServerCredentials retrieves the server credentials for a given uid
-func (c *PackerKeeperClient) GetServerCredentials(uid string) (*KeeperServerCredentials, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetSoftwareLicense retrieves the software license for the record matching the query
+func (c *PackerKeeperClient) GetSoftwareLicense(query RecordQuery) (*KeeperSoftwareLicense, error) {
+	r, err := c.GetRecord(query.withType(SOFTWARE_LICENSE_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	license, err := c.KeeperClient.GetSoftwareLicense(r)
 	if err != nil {
 		return nil, err
 	}
+	return license, c.downloadFiles(r, &license.KeeperRecordField, query.Files)
+}
 
-	return c.KeeperClient.GetServerCredentials(r)
+// GetLogin retrieves the login for the record matching the query
+func (c *PackerKeeperClient) GetLogin(query RecordQuery) (*KeeperLogin, error) {
+	r, err := c.GetRecord(query.withType(LOGIN_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	login, err := c.KeeperClient.GetLogin(r, query.Field)
+	if err != nil {
+		return nil, err
+	}
+	return login, c.downloadFiles(r, &login.KeeperRecordField, query.Files)
 }
 
-// GetDatabaseCredentials retrieves the database credentials for a given uid
-func (c *PackerKeeperClient) GetDatabaseCredentials(uid string) (*KeeperDataBaseCredentials, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetSSHKey retrieves the SSH key for the record matching the query
+func (c *PackerKeeperClient) GetSSHKey(query RecordQuery) (*KeeperSSHKey, error) {
+	r, err := c.GetRecord(query.withType(SSH_KEY_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	sshKey, err := c.KeeperClient.GetSSHKey(r, query.Field)
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetDatabaseCredentials(r)
+	return sshKey, c.downloadFiles(r, &sshKey.KeeperRecordField, query.Files)
 }
 
-// GetAPIKey retrieves the API key for a given uid
-func (c *PackerKeeperClient) GetAPIKey(uid string) (*KeeperAPIKey, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetGenericRecord retrieves any record type, with all of its fields, for the record matching the query
+func (c *PackerKeeperClient) GetGenericRecord(query RecordQuery) (*KeeperRecord, error) {
+	r, err := c.GetRecord(query)
+	if err != nil {
+		return nil, err
+	}
+	record, err := c.KeeperClient.GetGenericRecord(r)
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetAPIKey(r)
+	return record, c.downloadFiles(r, &record.KeeperRecordField, query.Files)
 }
 
-// GetEncryptedNote retrieves the encrypted note for a given uid
-func (c *PackerKeeperClient) GetEncryptedNote(uid string) (*KeeperEncryptedNote, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetPamMachine retrieves the PAM machine, along with its linked users, for the record matching the query
+func (c *PackerKeeperClient) GetPamMachine(query RecordQuery) (*KeeperPamMachine, error) {
+	r, err := c.GetRecord(query.withType(PAM_MACHINE_FIELD_TYPE))
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetEncryptedNote(r)
+
+	machine, err := c.KeeperClient.GetPamMachine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.downloadFiles(r, &machine.KeeperRecordField, query.Files); err != nil {
+		return nil, err
+	}
+
+	machine.Users, err = c.getLinkedUsers(r)
+	return machine, err
 }
 
-// GetFile retrieves the file for a given uid
-func (c *PackerKeeperClient) GetFile(uid string) (*KeeperFile, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetPamDatabase retrieves the PAM database, along with its linked users, for the record matching the query
+func (c *PackerKeeperClient) GetPamDatabase(query RecordQuery) (*KeeperPamDatabase, error) {
+	r, err := c.GetRecord(query.withType(PAM_DATABASE_FIELD_TYPE))
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetFile(r)
+
+	database, err := c.KeeperClient.GetPamDatabase(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.downloadFiles(r, &database.KeeperRecordField, query.Files); err != nil {
+		return nil, err
+	}
+
+	database.Users, err = c.getLinkedUsers(r)
+	return database, err
 }
 
-// GetSoftwareLicense retrieves the software license for a given uid
-func (c *PackerKeeperClient) GetSoftwareLicense(uid string) (*KeeperSoftwareLicense, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetPamDirectory retrieves the PAM directory, along with its linked users, for the record matching the query
+func (c *PackerKeeperClient) GetPamDirectory(query RecordQuery) (*KeeperPamDirectory, error) {
+	r, err := c.GetRecord(query.withType(PAM_DIRECTORY_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+
+	directory, err := c.KeeperClient.GetPamDirectory(r)
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetSoftwareLicense(r)
+
+	if err := c.downloadFiles(r, &directory.KeeperRecordField, query.Files); err != nil {
+		return nil, err
+	}
+
+	directory.Users, err = c.getLinkedUsers(r)
+	return directory, err
 }
 
-// GetLogin retrieves the login for a given uid
-func (c *PackerKeeperClient) GetLogin(uid string) (*KeeperLogin, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetPamUser retrieves the PAM user for the record matching the query
+func (c *PackerKeeperClient) GetPamUser(query RecordQuery) (*KeeperPamUser, error) {
+	r, err := c.GetRecord(query.withType(PAM_USER_FIELD_TYPE))
+	if err != nil {
+		return nil, err
+	}
+	user, err := c.KeeperClient.GetPamUser(r)
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetLogin(r)
+	return user, c.downloadFiles(r, &user.KeeperRecordField, query.Files)
 }
 
-// GetSSHKey retrieves the SSH key for a given uid
-func (c *PackerKeeperClient) GetSSHKey(uid string) (*KeeperSSHKey, error) {
-	r, err := c.KeeperClient.GetSecret(uid)
+// GetCertificate retrieves the certificate attached to the record matching the query, which can
+// be of any type.
+func (c *PackerKeeperClient) GetCertificate(query RecordQuery, options CertificateOptions) (*KeeperCertificate, error) {
+	r, err := c.GetRecord(query)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := c.KeeperClient.GetCertificate(r, options)
 	if err != nil {
 		return nil, err
 	}
-	return c.KeeperClient.GetSSHKey(r)
+	return cert, c.downloadFiles(r, &cert.KeeperRecordField, query.Files)
 }
@@ -2,8 +2,11 @@ package keeper_datasource
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -32,49 +35,49 @@ func TestInvalidTypeThrowsError(t *testing.T) {
 		{
 			TestName: "GetLogin",
 			function: func(uid string) (interface{}, error) {
-				return client.GetLogin(uid)
+				return client.GetLogin(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetAPIKey",
 			function: func(uid string) (interface{}, error) {
-				return client.GetAPIKey(uid)
+				return client.GetAPIKey(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetSoftwareLicense",
 			function: func(uid string) (interface{}, error) {
-				return client.GetSoftwareLicense(uid)
+				return client.GetSoftwareLicense(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetFile",
 			function: func(uid string) (interface{}, error) {
-				return client.GetFile(uid)
+				return client.GetFile(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetEncryptedNote",
 			function: func(uid string) (interface{}, error) {
-				return client.GetEncryptedNote(uid)
+				return client.GetEncryptedNote(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetDatabaseCredentials",
 			function: func(uid string) (interface{}, error) {
-				return client.GetDatabaseCredentials(uid)
+				return client.GetDatabaseCredentials(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetServerCredentials",
 			function: func(uid string) (interface{}, error) {
-				return client.GetServerCredentials(uid)
+				return client.GetServerCredentials(RecordQuery{Uid: uid})
 			},
 		},
 		{
 			TestName: "GetSSHKey",
 			function: func(uid string) (interface{}, error) {
-				return client.GetSSHKey(uid)
+				return client.GetSSHKey(RecordQuery{Uid: uid})
 			},
 		},
 	}
@@ -149,7 +152,7 @@ func TestGetLogin(t *testing.T) {
 	// Create a mocked client with the example JSON data.
 	// The client will return the JSON data as a Keeper record.
 	client := getMockedClient(loginRecordJson)
-	loginRecord, err := client.GetLogin(uid)
+	loginRecord, err := client.GetLogin(RecordQuery{Uid: uid})
 
 	// Assert that the returned record matches the expected values.
 	require.NoError(t, err)
@@ -205,7 +208,7 @@ func TestGetAPIKey(t *testing.T) {
 
 	// Create a mocked client with the example JSON data.
 	client := getMockedClient(apiKeyJson)
-	apiKeyRecord, err := client.GetAPIKey(uid)
+	apiKeyRecord, err := client.GetAPIKey(RecordQuery{Uid: uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values.
@@ -274,7 +277,7 @@ func TestGetSoftwareLicense(t *testing.T) {
 
 	// Create a mocked client with the example JSON data.
 	client := getMockedClient(jsonData)
-	softwareLicenseRecord, err := client.GetSoftwareLicense(uid)
+	softwareLicenseRecord, err := client.GetSoftwareLicense(RecordQuery{Uid: uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values.
@@ -366,7 +369,7 @@ func TestGetFile(t *testing.T) {
 
 	// Create a mocked client with the generated JSON data
 	client := getMockedClient(bfrWriter.String())
-	fileRecord, err := client.GetFile(data.Uid)
+	fileRecord, err := client.GetFile(RecordQuery{Uid: data.Uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values
@@ -434,7 +437,7 @@ func TestGetEncryptedNote(t *testing.T) {
 
 	// Create a mocked client with the example JSON data.
 	client := getMockedClient(jsonData)
-	encryptedNoteRecord, err := client.GetEncryptedNote(uid)
+	encryptedNoteRecord, err := client.GetEncryptedNote(RecordQuery{Uid: uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values.
@@ -495,7 +498,7 @@ func TestGetServerCredentials(t *testing.T) {
 
 	// Create a mocked client with the example JSON data.
 	client := getMockedClient(jsonData)
-	serverCredentialsRecord, err := client.GetServerCredentials(uid)
+	serverCredentialsRecord, err := client.GetServerCredentials(RecordQuery{Uid: uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values.
@@ -558,7 +561,7 @@ func TestGetDatabaseCredentials(t *testing.T) {
 
 	// Create a mocked client with the example JSON data.
 	client := getMockedClient(jsonData)
-	databaseCredentialsRecord, err := client.GetDatabaseCredentials(uid)
+	databaseCredentialsRecord, err := client.GetDatabaseCredentials(RecordQuery{Uid: uid})
 	require.NoError(t, err)
 
 	// Assert that the returned record matches the expected values.
@@ -593,6 +596,80 @@ func TestConvertDateStr(t *testing.T) {
 
 }
 
+// TestGetClientOptions tests that KSM configs set in HCL are used and take precedence over the environment.
+func TestGetClientOptions(t *testing.T) {
+	configJson := `{"appKey": "hcl-app-key", "clientId": "hcl-client-id", "privateKey": "hcl-private-key"}`
+	envConfigJson := `{"appKey": "env-app-key", "clientId": "env-client-id", "privateKey": "env-private-key"}`
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(configJson), 0600))
+
+	// Set the environment config so we can check the HCL config is preferred.
+	t.Setenv(KEEPER_CONFIG_ENV_KEY, base64.StdEncoding.EncodeToString([]byte(envConfigJson)))
+
+	type tc struct {
+		TestName       string
+		Config         ClientConfig
+		ExpectedAppKey string
+		ExpectedSource string
+	}
+
+	tcs := []tc{
+		{
+			TestName:       "config_file",
+			Config:         ClientConfig{ConfigFile: configFile},
+			ExpectedAppKey: "hcl-app-key",
+			ExpectedSource: "config_file",
+		},
+		{
+			TestName:       "config_base64",
+			Config:         ClientConfig{ConfigBase64: base64.StdEncoding.EncodeToString([]byte(configJson))},
+			ExpectedAppKey: "hcl-app-key",
+			ExpectedSource: "config_base64",
+		},
+		{
+			TestName:       "config_json",
+			Config:         ClientConfig{ConfigJSON: configJson},
+			ExpectedAppKey: "hcl-app-key",
+			ExpectedSource: "config_json",
+		},
+		{
+			TestName:       "environment fallback",
+			Config:         ClientConfig{},
+			ExpectedAppKey: "env-app-key",
+			ExpectedSource: KEEPER_CONFIG_ENV_KEY,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.TestName, func(t *testing.T) {
+			options, source, err := getClientOptions(tc.Config)
+			require.NoError(t, err)
+			assert.Contains(t, source, tc.ExpectedSource)
+			assert.Equal(t, tc.ExpectedAppKey, options.Config.Get(ksm.KEY_APP_KEY))
+			assert.NoError(t, validateClientOptions(options, source))
+		})
+	}
+}
+
+// TestValidateClientOptionsReportsSource tests that an invalid config names the source it was loaded from.
+func TestValidateClientOptionsReportsSource(t *testing.T) {
+	options, source, err := getClientOptions(ClientConfig{ConfigJSON: `{"clientId": "only-a-client-id"}`})
+	require.NoError(t, err)
+
+	err = validateClientOptions(options, source)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config_json")
+}
+
+// TestGetClientOptionsMissingFile tests that a missing config file returns an error naming the file.
+func TestGetClientOptionsMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	_, _, err := getClientOptions(ClientConfig{ConfigFile: missing})
+	require.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), missing)
+}
+
 func getMockedClient(secretRecordJson string) *PackerKeeperClient {
 	mockClient := &MockKeeperClient{
 		TestClient: &KSMClient{},
@@ -601,16 +678,16 @@ func getMockedClient(secretRecordJson string) *PackerKeeperClient {
 	record := recordFromJSON(secretRecordJson)
 	mockClient.On("GetSecret").Return(record, nil)
 
-	return &PackerKeeperClient{
-		KeeperClient: mockClient,
-	}
+	return NewClient(mockClient)
 }
 
 func recordFromJSON(data string) *ksm.Record {
 	recordFieldsDict := ksm.JsonToDict(data)
-	record := &ksm.Record{
-		RecordDict: recordFieldsDict,
-	}
+
+	// The folder uid can only be set through the Keeper constructor.
+	folderUid, _ := recordFieldsDict["folder_uid"].(string)
+	record := ksm.NewRecordFromJson(map[string]interface{}{}, nil, folderUid)
+	record.RecordDict = recordFieldsDict
 
 	// JSONToDict only builds the dictionary so we need to setup
 	// mock some fields ourselves based off the JSON payload.
@@ -650,3 +727,105 @@ func recordFromJSON(data string) *ksm.Record {
 	// Return our built record.
 	return record
 }
+
+// TestGetRecordByTitle tests that a title lookup resolves to exactly one record and otherwise
+// returns an error listing the candidate uids.
+func TestGetRecordByTitle(t *testing.T) {
+	records := []*ksm.Record{
+		recordFromJSON(`{"uid": "prod-uid", "type": "login", "title": "db", "folder_uid": "prod"}`),
+		recordFromJSON(`{"uid": "staging-uid", "type": "login", "title": "db", "folder_uid": "staging"}`),
+		recordFromJSON(`{"uid": "note-uid", "type": "encryptedNotes", "title": "db", "folder_uid": "prod"}`),
+	}
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecretsByTitle", "db").Return(records, nil)
+	mockClient.On("GetSecretsByTitle", "missing").Return([]*ksm.Record{}, nil)
+	client := NewClient(mockClient)
+
+	record, err := client.GetRecord(RecordQuery{Title: "db", FolderUid: "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, "staging-uid", record.Uid)
+
+	// Typed getters only match records of their own type.
+	note, err := client.GetEncryptedNote(RecordQuery{Title: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, "note-uid", note.Uid)
+
+	_, err = client.GetLogin(RecordQuery{Title: "db"})
+	require.ErrorIs(t, err, ErrAmbiguousTitle)
+	assert.Contains(t, err.Error(), "prod-uid")
+	assert.Contains(t, err.Error(), "staging-uid")
+	assert.NotContains(t, err.Error(), "note-uid")
+
+	_, err = client.GetLogin(RecordQuery{Title: "db", FolderUid: "dev"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	assert.Contains(t, err.Error(), "prod-uid")
+
+	_, err = client.GetLogin(RecordQuery{Title: "missing"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+// TestLenientRecordTypes tests that typed datasources read records of the types they accept, or of any type when not strict.
+func TestLenientRecordTypes(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "web",
+		"type": "login",
+		"fields": [
+			{"type": "login", "value": ["admin"]},
+			{"type": "password", "value": ["hunter2"]}
+		],
+		"custom": [
+			{"type": "host", "value": [{"hostName": "web.example.com", "port": "22"}]}
+		]
+	}`)
+
+	_, err := client.GetServerCredentials(RecordQuery{Uid: "record-uid"})
+	require.ErrorIs(t, err, ErrWrongRecordType)
+
+	_, err = client.GetServerCredentials(RecordQuery{Uid: "record-uid", AcceptTypes: []string{"databaseCredentials"}})
+	require.ErrorIs(t, err, ErrWrongRecordType)
+
+	creds, err := client.GetServerCredentials(RecordQuery{Uid: "record-uid", AcceptTypes: []string{"login"}})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", creds.Login)
+	assert.Equal(t, "hunter2", creds.Password)
+	assert.Equal(t, HostConnection{HostName: "web.example.com", Port: 22}, creds.HostConnection)
+
+	// Fields the record doesn't have are left empty.
+	note, err := client.GetEncryptedNote(RecordQuery{Uid: "record-uid", AnyType: true})
+	require.NoError(t, err)
+	assert.Equal(t, "", note.Note)
+	assert.Equal(t, "web", note.Title)
+}
+
+// TestLenientRecordTypesByTitle tests that title lookups match every type the datasource accepts.
+func TestLenientRecordTypesByTitle(t *testing.T) {
+	records := []*ksm.Record{
+		recordFromJSON(`{"uid": "login-uid", "type": "login", "title": "web"}`),
+		recordFromJSON(`{"uid": "note-uid", "type": "encryptedNotes", "title": "web"}`),
+	}
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecretsByTitle", "web").Return(records, nil)
+	client := NewClient(mockClient)
+
+	_, err := client.GetServerCredentials(RecordQuery{Title: "web"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	assert.Contains(t, err.Error(), `record_type "serverCredentials"`)
+
+	creds, err := client.GetServerCredentials(RecordQuery{Title: "web", AcceptTypes: []string{"login"}})
+	require.NoError(t, err)
+	assert.Equal(t, "login-uid", creds.Uid)
+
+	_, err = client.GetServerCredentials(RecordQuery{Title: "web", AnyType: true})
+	require.ErrorIs(t, err, ErrAmbiguousTitle)
+}
+
+// TestStrictTypeConfig tests that strict_type and accept_types are passed to the record query.
+func TestStrictTypeConfig(t *testing.T) {
+	strict := false
+	query := (&Config{StrictType: &strict, AcceptTypes: []string{"login"}}).RecordQuery()
+	assert.True(t, query.AnyType)
+	assert.Equal(t, []string{"login"}, query.AcceptTypes)
+
+	assert.False(t, (&Config{}).RecordQuery().AnyType)
+}
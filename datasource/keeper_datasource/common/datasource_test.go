@@ -5,10 +5,17 @@ import (
 
 	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
 	keeper_api_key "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-api-key"
+	keeper_certificate "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-certificate"
 	keeper_database_credentials "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-database-credentials"
 	keeper_encrypted_note "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-encrypted-note"
 	keeper_file "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-file"
 	keeper_login "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-login"
+	keeper_notation "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-notation"
+	keeper_pam_database "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-database"
+	keeper_pam_directory "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-directory"
+	keeper_pam_machine "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-machine"
+	keeper_pam_user "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-user"
+	keeper_record "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-record"
 	keeper_server_credentials "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-server-credentials"
 	keeper_software_license "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-software-license"
 	keeper_ssh_key "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-ssh-key"
@@ -57,6 +64,30 @@ func TestInvalidConfigReturnsError(t *testing.T) {
 			DataSource: &keeper_ssh_key.Datasource{},
 			TestName:   "keeper_ssh_key",
 		},
+		{
+			DataSource: &keeper_record.Datasource{},
+			TestName:   "keeper_record",
+		},
+		{
+			DataSource: &keeper_pam_machine.Datasource{},
+			TestName:   "keeper_pam_machine",
+		},
+		{
+			DataSource: &keeper_pam_database.Datasource{},
+			TestName:   "keeper_pam_database",
+		},
+		{
+			DataSource: &keeper_pam_directory.Datasource{},
+			TestName:   "keeper_pam_directory",
+		},
+		{
+			DataSource: &keeper_pam_user.Datasource{},
+			TestName:   "keeper_pam_user",
+		},
+		{
+			DataSource: &keeper_certificate.Datasource{},
+			TestName:   "keeper_certificate",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -122,6 +153,42 @@ func TestValidConfigReturnsNoError(t *testing.T) {
 			},
 			TestName: "keeper_ssh_key",
 		},
+		{
+			DataSource: &keeper_record.Datasource{
+				Config: *config,
+			},
+			TestName: "keeper_record",
+		},
+		{
+			DataSource: &keeper_pam_machine.Datasource{
+				Config: *config,
+			},
+			TestName: "keeper_pam_machine",
+		},
+		{
+			DataSource: &keeper_pam_database.Datasource{
+				Config: *config,
+			},
+			TestName: "keeper_pam_database",
+		},
+		{
+			DataSource: &keeper_pam_directory.Datasource{
+				Config: *config,
+			},
+			TestName: "keeper_pam_directory",
+		},
+		{
+			DataSource: &keeper_pam_user.Datasource{
+				Config: *config,
+			},
+			TestName: "keeper_pam_user",
+		},
+		{
+			DataSource: &keeper_certificate.Datasource{
+				Config: keeper_certificate.Config{Config: *config},
+			},
+			TestName: "keeper_certificate",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -131,3 +198,65 @@ func TestValidConfigReturnsNoError(t *testing.T) {
 		})
 	}
 }
+
+// TestMultipleConfigSourcesReturnsError tests that the Configure method returns an error when more than one
+// KSM config source is set in HCL.
+func TestMultipleConfigSourcesReturnsError(t *testing.T) {
+	testUid := "test-uid"
+	config := keeper_datasource.Config{
+		Uid: &testUid,
+		ClientConfig: keeper_datasource.ClientConfig{
+			ConfigFile: "/path/to/config.json",
+			ConfigJSON: "{}",
+		},
+	}
+
+	err := (&keeper_login.Datasource{Config: config}).Configure()
+	require.ErrorIs(t, err, keeper_datasource.ErrMultipleConfigSources)
+}
+
+// TestUidAndTitleValidation tests that exactly one of uid or title must be set and that the
+// title filters can't be used with uid.
+func TestUidAndTitleValidation(t *testing.T) {
+	testUid := "test-uid"
+
+	err := (&keeper_login.Datasource{Config: keeper_datasource.Config{Title: "db", FolderUid: "folder"}}).Configure()
+	require.NoError(t, err)
+
+	err = (&keeper_login.Datasource{Config: keeper_datasource.Config{Uid: &testUid, Title: "db"}}).Configure()
+	require.ErrorIs(t, err, keeper_datasource.ErrUidAndTitle)
+
+	err = (&keeper_login.Datasource{Config: keeper_datasource.Config{Uid: &testUid, RecordType: "login"}}).Configure()
+	require.ErrorIs(t, err, keeper_datasource.ErrTitleFilterWithoutTitle)
+}
+
+// TestNotationConfigValidation tests that the notation datasource requires at least one valid notation.
+func TestNotationConfigValidation(t *testing.T) {
+	err := (&keeper_notation.Datasource{}).Configure()
+	require.ErrorIs(t, err, keeper_notation.ErrNotationsRequired)
+
+	err = (&keeper_notation.Datasource{Config: keeper_notation.Config{
+		Notations: map[string]string{"password": "keeper://uid/password"},
+	}}).Configure()
+	require.ErrorIs(t, err, keeper_datasource.ErrInvalidNotation)
+
+	err = (&keeper_notation.Datasource{Config: keeper_notation.Config{
+		Notations: map[string]string{"password": "keeper://uid/field/password"},
+	}}).Configure()
+	require.NoError(t, err)
+}
+
+// TestCertificateConfigValidation tests that the certificate datasource rejects invalid certificate settings.
+func TestCertificateConfigValidation(t *testing.T) {
+	testUid := "test-uid"
+	config := keeper_datasource.Config{Uid: &testUid}
+
+	err := (&keeper_certificate.Datasource{Config: keeper_certificate.Config{Config: config, MinDaysValid: -1}}).Configure()
+	require.ErrorIs(t, err, keeper_certificate.ErrInvalidMinDaysValid)
+
+	err = (&keeper_certificate.Datasource{Config: keeper_certificate.Config{Config: config, CertificateFile: "[a-"}}).Configure()
+	require.ErrorIs(t, err, keeper_certificate.ErrInvalidCertificateFile)
+
+	err = (&keeper_certificate.Datasource{Config: keeper_certificate.Config{Config: config, CertificateFile: "*.pfx", MinDaysValid: 30}}).Configure()
+	require.NoError(t, err)
+}
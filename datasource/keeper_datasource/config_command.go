@@ -0,0 +1,113 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Environment variables used to load the KSM config from an external command.
+const (
+	KEEPER_CONFIG_COMMAND_ENV_KEY         = "KEEPER_CONFIG_COMMAND"
+	KEEPER_CONFIG_COMMAND_TIMEOUT_ENV_KEY = "KEEPER_CONFIG_COMMAND_TIMEOUT"
+)
+
+const (
+	// defaultConfigCommandTimeout is how long the config command can run when no timeout is configured.
+	defaultConfigCommandTimeout = 30 * time.Second
+	// maxCommandStderr is the maximum amount of stderr included in error messages.
+	maxCommandStderr = 4096
+	// configCommandWaitDelay is how long to wait for the command output to be closed once the command
+	// is killed, processes it started may still hold it open.
+	configCommandWaitDelay = time.Second
+)
+
+// Errors for handling the config command.
+var (
+	ErrConfigCommandFailed  = errors.New("config command failed")
+	ErrConfigCommandTimeout = errors.New("config command timed out")
+)
+
+// getCommandOptions returns the config command and its timeout, preferring the HCL config over
+// the environment. ok is false when no command is set. The environment variable is split on
+// whitespace, wrap the command in a script if it needs quoting.
+func getCommandOptions(c ClientConfig) (command []string, timeout time.Duration, ok bool, err error) {
+	command = c.ConfigCommand
+	if len(command) == 0 {
+		command = strings.Fields(os.Getenv(KEEPER_CONFIG_COMMAND_ENV_KEY))
+	}
+
+	if len(command) == 0 {
+		return nil, 0, false, nil
+	}
+
+	timeout = c.ConfigCommandTimeout
+	if timeout == 0 {
+		if env := os.Getenv(KEEPER_CONFIG_COMMAND_TIMEOUT_ENV_KEY); env != "" {
+			timeout, err = time.ParseDuration(env)
+			if err != nil {
+				return nil, 0, false, fmt.Errorf("invalid duration in %s: %w", KEEPER_CONFIG_COMMAND_TIMEOUT_ENV_KEY, err)
+			}
+		}
+	}
+
+	if timeout <= 0 {
+		timeout = defaultConfigCommandTimeout
+	}
+
+	return command, timeout, true, nil
+}
+
+// getCommandClientOptions runs the config command and initializes the client options from its
+// stdout, which must be raw JSON or base64 encoded JSON. The config is only ever kept in memory
+// and is never logged or included in errors, stderr is included in errors to help debugging.
+func getCommandClientOptions(command []string, timeout time.Duration) (*ksm.ClientOptions, string, error) {
+	source := fmt.Sprintf("config command (%s)", command[0])
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	// Leave stdin detached, the command runs in the background of the terminal so prompts can't be
+	// answered and reads from stdin fail fast instead of stopping it.
+	cmd.Stdin = nil
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = configCommandWaitDelay
+	setProcessGroup(cmd)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, "", fmt.Errorf("%w: %s did not finish within %s", ErrConfigCommandTimeout, source, timeout)
+		}
+
+		return nil, "", fmt.Errorf("%w: %s: %s: %s", ErrConfigCommandFailed, source, err, truncateStderr(stderr.String()))
+	}
+
+	config, _, err := decodeConfig(stdout.Bytes())
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s: %s", ErrConfigCommandFailed, source, err)
+	}
+
+	return &ksm.ClientOptions{
+		Config: ksm.NewMemoryKeyValueStorage(config),
+	}, source, nil
+}
+
+// truncateStderr trims the command stderr so a noisy command can't flood the Packer log.
+func truncateStderr(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if len(stderr) > maxCommandStderr {
+		return stderr[:maxCommandStderr] + "..."
+	}
+
+	return stderr
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package keeper_datasource
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups aren't available. Only the command is killed when
+// it is cancelled, the wait delay stops waiting on processes it started.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,132 @@
+package keeper_datasource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperCommand returns a config command that re-runs the test binary as TestConfigCommandHelperProcess.
+func helperCommand(t *testing.T, mode string) []string {
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	return []string{os.Args[0], "-test.run=TestConfigCommandHelperProcess", "--", mode}
+}
+
+// TestConfigCommandHelperProcess isn't a real test, it acts as the config command for the tests below.
+func TestConfigCommandHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	switch os.Args[len(os.Args)-1] {
+	case "json":
+		fmt.Print(testConfigA)
+	case "base64":
+		fmt.Println(base64.StdEncoding.EncodeToString([]byte(testConfigA)))
+	case "count":
+		// Record each run in the file set by the test before printing the config.
+		f, err := os.OpenFile(os.Getenv("TEST_CONFIG_COMMAND_RUNS"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			os.Exit(1)
+		}
+		fmt.Fprintln(f, "run")
+		f.Close()
+		fmt.Print(testConfigA)
+	case "invalid":
+		fmt.Print("private-key-a is not a config")
+	case "fail":
+		fmt.Fprint(os.Stderr, "vault is sealed")
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	case "prompt":
+		// Ask for a passphrase the way an interactive helper would.
+		fmt.Fprint(os.Stderr, "passphrase: ")
+		if _, err := fmt.Fscanln(os.Stdin, new(string)); err != nil {
+			fmt.Fprint(os.Stderr, "no passphrase")
+			os.Exit(1)
+		}
+		fmt.Print(testConfigA)
+	}
+	os.Exit(0)
+}
+
+// TestConfigCommand tests that the config can be read from the command stdout as JSON or base64.
+func TestConfigCommand(t *testing.T) {
+	for _, mode := range []string{"json", "base64"} {
+		t.Run(mode, func(t *testing.T) {
+			options, source, err := getClientOptions(ClientConfig{ConfigCommand: helperCommand(t, mode)})
+			require.NoError(t, err)
+			assert.Contains(t, source, "config command")
+			assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+		})
+	}
+}
+
+// TestConfigCommandErrors tests that failures include stderr but never the command output, and that
+// commands can't wait for input.
+func TestConfigCommandErrors(t *testing.T) {
+	_, _, err := getClientOptions(ClientConfig{ConfigCommand: helperCommand(t, "fail")})
+	require.ErrorIs(t, err, ErrConfigCommandFailed)
+	assert.Contains(t, err.Error(), "vault is sealed")
+
+	_, _, err = getClientOptions(ClientConfig{ConfigCommand: helperCommand(t, "invalid")})
+	require.ErrorIs(t, err, ErrConfigCommandFailed)
+	assert.NotContains(t, err.Error(), "private-key-a")
+
+	// Commands prompting for input read from a detached stdin and fail instead of waiting.
+	start := time.Now()
+	_, _, err = getClientOptions(ClientConfig{ConfigCommand: helperCommand(t, "prompt")})
+	require.ErrorIs(t, err, ErrConfigCommandFailed)
+	assert.Contains(t, err.Error(), "no passphrase")
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	_, _, err = getClientOptions(ClientConfig{
+		ConfigCommand:        helperCommand(t, "sleep"),
+		ConfigCommandTimeout: 100 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, ErrConfigCommandTimeout)
+}
+
+// TestConfigCommandTimeoutKillsChildren tests that the timeout applies when the command started
+// processes that keep its output open.
+func TestConfigCommandTimeoutKillsChildren(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	start := time.Now()
+	_, _, err := getClientOptions(ClientConfig{
+		ConfigCommand:        []string{"sh", "-c", "sleep 30; echo done"},
+		ConfigCommandTimeout: 100 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, ErrConfigCommandTimeout)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+// TestConfigCommandFromEnvironment tests that the command and timeout fall back to the environment.
+func TestConfigCommandFromEnvironment(t *testing.T) {
+	for _, key := range []string{KEEPER_CONFIG_FILE_ENV_KEY, KEEPER_CONFIG_ENV_KEY} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv(KEEPER_CONFIG_COMMAND_ENV_KEY, strings.Join(helperCommand(t, "json"), " "))
+	t.Setenv(KEEPER_CONFIG_COMMAND_TIMEOUT_ENV_KEY, "1m")
+
+	_, timeout, ok, err := getCommandOptions(ClientConfig{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, timeout)
+
+	options, _, err := getClientOptions(ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+}
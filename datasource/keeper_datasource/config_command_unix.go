@@ -0,0 +1,17 @@
+//go:build unix
+
+package keeper_datasource
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs the command in its own process group and kills the whole group when the
+// command is cancelled, so processes it started (ex: through sh -c) don't keep it running.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
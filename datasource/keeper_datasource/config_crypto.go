@@ -0,0 +1,238 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables used to provide the passphrase for encrypted KSM config files.
+const (
+	KEEPER_CONFIG_PASSPHRASE_ENV_KEY    = "KEEPER_CONFIG_PASSPHRASE"
+	KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY = "KEEPER_CONFIG_PASSPHRASE_FD"
+)
+
+// encryptedConfigPEMType is the PEM block type encrypted KSM configs are stored in.
+const encryptedConfigPEMType = "KSM ENCRYPTED CONFIG"
+
+// scrypt parameters used when encrypting a config. They are stored alongside the
+// ciphertext so they can be raised later without breaking existing files.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	scryptSalt   = 16
+)
+
+// Highest scrypt parameters accepted when decrypting a config, so a tampered file can't make the
+// plugin allocate gigabytes of memory or spin for minutes. They leave room to raise the parameters
+// above the ones used when encrypting, up to 256 MiB of memory.
+const (
+	maxScryptN = 1 << 18
+	maxScryptR = 8
+	maxScryptP = 4
+)
+
+// Errors for handling encrypted config files.
+var (
+	ErrNoPassphrase       = errors.New("config is encrypted but no passphrase was provided, set " + KEEPER_CONFIG_PASSPHRASE_ENV_KEY + " or " + KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY)
+	ErrInvalidPassphrase  = errors.New("unable to decrypt config, the passphrase is incorrect or the file is corrupted")
+	ErrMalformedEncrypted = errors.New("malformed encrypted config")
+)
+
+var (
+	// Pipes passed in KEEPER_CONFIG_PASSPHRASE_FD can only be read once, so the passphrase is cached for the process.
+	passphraseMu     sync.Mutex
+	cachedPassphrase string
+)
+
+// IsEncryptedConfig returns true if content is a passphrase encrypted KSM config.
+func IsEncryptedConfig(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("-----BEGIN "+encryptedConfigPEMType+"-----"))
+}
+
+// EncryptConfig encrypts a KSM config with AES-256-GCM using a key derived from passphrase with scrypt.
+// The result is PEM encoded, with the KDF parameters stored in the PEM headers.
+func EncryptConfig(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	salt := make([]byte, scryptSalt)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newConfigCipher(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	block := &pem.Block{
+		Type: encryptedConfigPEMType,
+		Headers: map[string]string{
+			"KDF":   "scrypt",
+			"N":     strconv.Itoa(scryptN),
+			"R":     strconv.Itoa(scryptR),
+			"P":     strconv.Itoa(scryptP),
+			"Salt":  base64.StdEncoding.EncodeToString(salt),
+			"Nonce": base64.StdEncoding.EncodeToString(nonce),
+		},
+		Bytes: gcm.Seal(nil, nonce, plaintext, nil),
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// DecryptConfig decrypts a KSM config encrypted with EncryptConfig.
+func DecryptConfig(content []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	block, _ := pem.Decode(bytes.TrimSpace(content))
+	if block == nil || block.Type != encryptedConfigPEMType {
+		return nil, ErrMalformedEncrypted
+	}
+
+	if block.Headers["KDF"] != "scrypt" {
+		return nil, fmt.Errorf("%w: unsupported KDF %q", ErrMalformedEncrypted, block.Headers["KDF"])
+	}
+
+	params := map[string]int{}
+	limits := map[string]int{"N": maxScryptN, "R": maxScryptR, "P": maxScryptP}
+	for _, name := range []string{"N", "R", "P"} {
+		v, err := strconv.Atoi(block.Headers[name])
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("%w: invalid scrypt parameter %s", ErrMalformedEncrypted, name)
+		}
+		if v > limits[name] {
+			return nil, fmt.Errorf("%w: scrypt parameter %s is %d, at most %d is supported", ErrMalformedEncrypted, name, v, limits[name])
+		}
+		params[name] = v
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid salt", ErrMalformedEncrypted)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrMalformedEncrypted)
+	}
+
+	gcm, err := newConfigCipher(passphrase, salt, params["N"], params["R"], params["P"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedEncrypted, err)
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrMalformedEncrypted)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, block.Bytes, nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return plaintext, nil
+}
+
+// newConfigCipher derives the config key from the passphrase and returns an AES-GCM cipher for it.
+func newConfigCipher(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// GetConfigPassphrase returns the passphrase for encrypted configs. The passphrase is read from
+// KEEPER_CONFIG_PASSPHRASE, or from the file descriptor number set in KEEPER_CONFIG_PASSPHRASE_FD
+// so it never has to be exported into the environment. Packer runs every datasource in its own
+// plugin process sharing the file descriptor: files are read from their start so each datasource
+// gets the passphrase, while a pipe is drained by the first datasource reading it.
+func GetConfigPassphrase() (string, error) {
+	if passphrase := os.Getenv(KEEPER_CONFIG_PASSPHRASE_ENV_KEY); passphrase != "" {
+		return passphrase, nil
+	}
+
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+
+	fdStr := os.Getenv(KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY)
+	if fdStr == "" {
+		return "", ErrNoPassphrase
+	}
+
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid file descriptor in %s: %w", KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY, err)
+	}
+
+	f := os.NewFile(uintptr(fd), "passphrase")
+	if f == nil {
+		return "", fmt.Errorf("invalid file descriptor %d in %s", fd, KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY)
+	}
+	defer f.Close()
+
+	passphrase, err := readPassphraseFile(f)
+	if err != nil {
+		return "", fmt.Errorf("file descriptor %d: %w", fd, err)
+	}
+
+	cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// readPassphraseFile reads the passphrase from f. Regular files are read from their start without
+// moving the offset shared with the other plugin processes, anything else is read to the end.
+func readPassphraseFile(f *os.File) (string, error) {
+	var content []byte
+	info, err := f.Stat()
+	if err == nil && info.Mode().IsRegular() {
+		content = make([]byte, info.Size())
+		_, err = f.ReadAt(content, 0)
+	} else {
+		content, err = io.ReadAll(f)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("unable to read passphrase: %w", err)
+	}
+
+	passphrase := strings.TrimRight(string(content), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("%w: it is empty or was already read by another datasource, a pipe can only be read by one datasource so pass a file (ex: 3<passphrase.txt) instead", ErrNoPassphrase)
+	}
+
+	return passphrase, nil
+}
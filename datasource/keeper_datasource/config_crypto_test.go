@@ -0,0 +1,123 @@
+package keeper_datasource
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncryptConfigRoundTrip tests that an encrypted config decrypts with the right passphrase only.
+func TestEncryptConfigRoundTrip(t *testing.T) {
+	encrypted, err := EncryptConfig([]byte(testConfigA), "correct horse")
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedConfig(encrypted))
+	assert.NotContains(t, string(encrypted), "app-key-a")
+
+	plaintext, err := DecryptConfig(encrypted, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, testConfigA, string(plaintext))
+
+	_, err = DecryptConfig(encrypted, "battery staple")
+	require.ErrorIs(t, err, ErrInvalidPassphrase)
+}
+
+// TestDecryptConfigLimitsScryptParameters tests that scrypt parameters above the supported ceiling are
+// rejected before deriving the key.
+func TestDecryptConfigLimitsScryptParameters(t *testing.T) {
+	encrypted, err := EncryptConfig([]byte(testConfigA), "correct horse")
+	require.NoError(t, err)
+
+	for _, param := range [][2]string{{"N", "1073741824"}, {"R", "1048576"}, {"P", "1000"}, {"N", "0"}} {
+		block, _ := pem.Decode(encrypted)
+		require.NotNil(t, block)
+		block.Headers[param[0]] = param[1]
+
+		start := time.Now()
+		_, err := DecryptConfig(pem.EncodeToMemory(block), "correct horse")
+		require.ErrorIs(t, err, ErrMalformedEncrypted, param[0])
+		assert.Less(t, time.Since(start), time.Second, param[0])
+	}
+}
+
+// TestEncryptedFileConfigStorage tests that encrypted config files are decrypted on load and
+// re-encrypted when Keeper updates the config.
+func TestEncryptedFileConfigStorage(t *testing.T) {
+	passphrase := "correct horse"
+	t.Setenv(KEEPER_CONFIG_PASSPHRASE_ENV_KEY, passphrase)
+
+	encrypted, err := EncryptConfig([]byte(testConfigA), passphrase)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.enc")
+	require.NoError(t, os.WriteFile(path, encrypted, 0600))
+
+	options, _, err := getClientOptions(ClientConfig{ConfigFile: path})
+	require.NoError(t, err)
+	assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+
+	options.Config.Set(ksm.KEY_SERVER_PUBLIC_KEY_ID, "17")
+
+	// The updated config must still be encrypted on disk.
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, IsEncryptedConfig(content))
+
+	plaintext, err := DecryptConfig(content, passphrase)
+	require.NoError(t, err)
+	config, _, err := decodeConfig(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "17", config[string(ksm.KEY_SERVER_PUBLIC_KEY_ID)])
+}
+
+// TestEncryptedConfigRequiresPassphrase tests that loading an encrypted config without a passphrase fails.
+func TestEncryptedConfigRequiresPassphrase(t *testing.T) {
+	t.Setenv(KEEPER_CONFIG_PASSPHRASE_ENV_KEY, "")
+	t.Setenv(KEEPER_CONFIG_PASSPHRASE_FD_ENV_KEY, "")
+
+	encrypted, err := EncryptConfig([]byte(testConfigA), "correct horse")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.enc")
+	require.NoError(t, os.WriteFile(path, encrypted, 0600))
+
+	_, err = NewFileConfigStorage(path, false)
+	require.ErrorIs(t, err, ErrNoPassphrase)
+}
+
+// TestReadPassphraseFileTwice tests that a passphrase file shared by several datasources can be read
+// by each of them, while a pipe can only be read once.
+func TestReadPassphraseFileTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase.txt")
+	require.NoError(t, os.WriteFile(path, []byte("correct horse\n"), 0600))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	for i := 0; i < 2; i++ {
+		passphrase, err := readPassphraseFile(f)
+		require.NoError(t, err)
+		assert.Equal(t, "correct horse", passphrase)
+	}
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = w.WriteString("correct horse\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	passphrase, err := readPassphraseFile(r)
+	require.NoError(t, err)
+	assert.Equal(t, "correct horse", passphrase)
+
+	_, err = readPassphraseFile(r)
+	require.ErrorIs(t, err, ErrNoPassphrase)
+	assert.Contains(t, err.Error(), "another datasource")
+}
@@ -0,0 +1,347 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// KEEPER_CONFIG_READ_ONLY_ENV_KEY disables writing KSM config updates back to the config file.
+const KEEPER_CONFIG_READ_ONLY_ENV_KEY = "KEEPER_CONFIG_READ_ONLY"
+
+var ErrInvalidConfigContent = errors.New("config content is neither valid JSON nor base64 encoded JSON")
+
+// configFormat is the encoding a KSM config file is stored in on disk.
+type configFormat int
+
+const (
+	configFormatJSON configFormat = iota
+	configFormatBase64
+	configFormatEncrypted
+)
+
+// FileConfigStorage is a ksm.IKeyValueStorage backed by a KSM config file. Unlike the
+// storage shipped with the Keeper SDK, updates Keeper makes to the config (ex: a rotated
+// server public key id or hostname) are written back to the file atomically while holding
+// a lock file, keeping the encoding and permissions of the original file. The file is
+// re-read under the lock before each update so concurrent builds don't lose each other's
+// updates, and symlinked configs are updated in place of their target. Passphrase
+// encrypted configs are re-encrypted when written back.
+type FileConfigStorage struct {
+	mu         sync.Mutex
+	path       string
+	readOnly   bool
+	format     configFormat
+	passphrase string
+	config     map[string]interface{}
+}
+
+var _ ksm.IKeyValueStorage = (*FileConfigStorage)(nil)
+
+// NewFileConfigStorage loads the KSM config at path. The content can either be raw JSON,
+// base64 encoded JSON or a passphrase encrypted config. When readOnly is set updates are only
+// kept in memory, which is useful for configs mounted on read-only file systems.
+func NewFileConfigStorage(path string, readOnly bool) (*FileConfigStorage, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &FileConfigStorage{
+		path:     path,
+		readOnly: readOnly,
+	}
+
+	storage.config, storage.format, err = storage.decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return storage, nil
+}
+
+// decode decodes the content of the config file. Encrypted configs are decrypted first, the
+// plaintext can be in any of the other formats.
+func (f *FileConfigStorage) decode(content []byte) (map[string]interface{}, configFormat, error) {
+	if !IsEncryptedConfig(content) {
+		return decodeConfig(content)
+	}
+
+	if f.passphrase == "" {
+		passphrase, err := GetConfigPassphrase()
+		if err != nil {
+			return nil, 0, err
+		}
+		f.passphrase = passphrase
+	}
+
+	content, err := DecryptConfig(content, f.passphrase)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	config, _, err := decodeConfig(content)
+	return config, configFormatEncrypted, err
+}
+
+// ReadStorage returns a copy of the config.
+func (f *FileConfigStorage) ReadStorage() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.copyConfig()
+}
+
+// SaveStorage replaces the config and writes it back to disk.
+func (f *FileConfigStorage) SaveStorage(updatedConfig map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.update(func(config map[string]interface{}) {
+		clear(config)
+		for k, v := range updatedConfig {
+			config[k] = v
+		}
+	})
+}
+
+// Get returns the value for key or an empty string if it isn't set.
+func (f *FileConfigStorage) Get(key ksm.ConfigKey) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if value, ok := f.config[string(key)].(string); ok {
+		return value
+	}
+
+	return ""
+}
+
+// Set updates key and writes the config back to disk if the value changed.
+func (f *FileConfigStorage) Set(key ksm.ConfigKey, value interface{}) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if current, ok := f.config[string(key)]; !ok || !reflect.DeepEqual(current, value) {
+		f.update(func(config map[string]interface{}) {
+			config[string(key)] = value
+		})
+	}
+
+	return f.copyConfig()
+}
+
+// Delete removes key and writes the config back to disk if it was set.
+func (f *FileConfigStorage) Delete(key ksm.ConfigKey) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.config[string(key)]; ok {
+		f.update(func(config map[string]interface{}) {
+			delete(config, string(key))
+		})
+	}
+
+	return f.copyConfig()
+}
+
+// DeleteAll removes every key and writes the empty config back to disk.
+func (f *FileConfigStorage) DeleteAll() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.update(func(config map[string]interface{}) {
+		clear(config)
+	})
+
+	return f.copyConfig()
+}
+
+// Contains returns true if key is set.
+func (f *FileConfigStorage) Contains(key ksm.ConfigKey) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.config[string(key)]
+	return ok
+}
+
+// IsEmpty returns true if no keys are set.
+func (f *FileConfigStorage) IsEmpty() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.config) == 0
+}
+
+// copyConfig returns a copy of the config so callers can't modify it without locking.
+func (f *FileConfigStorage) copyConfig() map[string]interface{} {
+	config := make(map[string]interface{}, len(f.config))
+	for k, v := range f.config {
+		config[k] = v
+	}
+
+	return config
+}
+
+// update applies change to the config and writes it back to disk. The file is re-read while
+// holding its lock file and change is applied to its current content, so updates written by
+// other processes since the config was loaded are kept. The Keeper storage interface has no way
+// to return errors so failures are logged and the update is kept in memory.
+func (f *FileConfigStorage) update(change func(config map[string]interface{})) {
+	if f.readOnly {
+		change(f.config)
+		return
+	}
+
+	path := resolveSymlinks(f.path)
+	unlock, err := acquireFileLock(path + ".lock")
+	if err != nil {
+		change(f.config)
+		log.Printf("[WARN] unable to write KSM config updates back to %s, keeping them in memory: %s", f.path, err)
+		return
+	}
+	defer unlock()
+
+	if content, err := os.ReadFile(path); err != nil {
+		log.Printf("[WARN] unable to re-read KSM config %s before updating it: %s", f.path, err)
+	} else if config, _, err := f.decode(content); err != nil {
+		log.Printf("[WARN] unable to re-read KSM config %s before updating it: %s", f.path, err)
+	} else {
+		f.config = config
+	}
+	change(f.config)
+
+	content, err := encodeConfig(f.config, f.format, f.passphrase)
+	if err != nil {
+		log.Printf("[WARN] unable to encode KSM config %s: %s", f.path, err)
+		return
+	}
+
+	if err := replaceFile(path, content); err != nil {
+		log.Printf("[WARN] unable to write KSM config updates back to %s, keeping them in memory: %s", f.path, err)
+	}
+}
+
+// configReadOnly returns true if config files should not be written back to,
+// either from the HCL config or the KEEPER_CONFIG_READ_ONLY environment variable.
+func configReadOnly(c ClientConfig) bool {
+	if c.ConfigReadOnly {
+		return true
+	}
+
+	readOnly, _ := strconv.ParseBool(os.Getenv(KEEPER_CONFIG_READ_ONLY_ENV_KEY))
+	return readOnly
+}
+
+// decodeConfig parses raw JSON or base64 encoded JSON config content.
+func decodeConfig(content []byte) (map[string]interface{}, configFormat, error) {
+	content = bytes.TrimSpace(content)
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(content, &config); err == nil {
+		return config, configFormatJSON, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(content))
+	if err != nil {
+		return nil, 0, ErrInvalidConfigContent
+	}
+
+	if err := json.Unmarshal(decoded, &config); err != nil {
+		return nil, 0, ErrInvalidConfigContent
+	}
+
+	return config, configFormatBase64, nil
+}
+
+// encodeConfig serializes the config in the given format. The passphrase is only used for encrypted configs.
+func encodeConfig(config map[string]interface{}, format configFormat, passphrase string) ([]byte, error) {
+	switch format {
+	case configFormatBase64:
+		content, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(base64.StdEncoding.EncodeToString(content)), nil
+	case configFormatEncrypted:
+		content, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return EncryptConfig(content, passphrase)
+	default:
+		return json.MarshalIndent(config, "", "  ")
+	}
+}
+
+// writeFileAtomic replaces path with content while holding a lock file.
+func writeFileAtomic(path string, content []byte) error {
+	path = resolveSymlinks(path)
+	unlock, err := acquireFileLock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return replaceFile(path, content)
+}
+
+// replaceFile replaces path with content, the caller must hold the lock file of path. The content
+// is written to a temporary file in the same directory which is renamed over path, so readers
+// never see a partially written file. The permissions of the existing file are kept, and when
+// path is a symlink its target is replaced so the link is left in place.
+func replaceFile(path string, content []byte) error {
+	path = resolveSymlinks(path)
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// resolveSymlinks returns the file path links to, or path itself when it isn't a symlink or
+// can't be resolved (ex: it doesn't exist yet).
+func resolveSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
@@ -0,0 +1,164 @@
+package keeper_datasource
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileConfigStorageWritesBack tests that updates are written back to disk in the original
+// encoding and with the original permissions.
+func TestFileConfigStorageWritesBack(t *testing.T) {
+	type tc struct {
+		TestName string
+		Content  string
+		Format   configFormat
+	}
+
+	tcs := []tc{
+		{
+			TestName: "json",
+			Content:  testConfigA,
+			Format:   configFormatJSON,
+		},
+		{
+			TestName: "base64",
+			Content:  base64.StdEncoding.EncodeToString([]byte(testConfigA)),
+			Format:   configFormatBase64,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.TestName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.Content), 0640))
+			require.NoError(t, os.Chmod(path, 0640))
+
+			storage, err := NewFileConfigStorage(path, false)
+			require.NoError(t, err)
+			storage.Set(ksm.KEY_SERVER_PUBLIC_KEY_ID, "17")
+			storage.Delete(ksm.KEY_CLIENT_KEY)
+
+			// Read the config back from disk to make sure the update was persisted.
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			config, format, err := decodeConfig(content)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Format, format)
+			assert.Equal(t, "17", config[string(ksm.KEY_SERVER_PUBLIC_KEY_ID)])
+			assert.Equal(t, "app-key-a", config[string(ksm.KEY_APP_KEY)])
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+			// The lock file must be released once the write is done.
+			assert.NoFileExists(t, path+".lock")
+		})
+	}
+}
+
+// TestFileConfigStorageKeepsSymlink tests that updates to a symlinked config are written to the
+// target of the link and the link is left in place.
+func TestFileConfigStorageKeepsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "secrets", "config.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+	require.NoError(t, os.WriteFile(target, []byte(testConfigA), 0600))
+	link := filepath.Join(dir, "config.json")
+	require.NoError(t, os.Symlink(target, link))
+
+	storage, err := NewFileConfigStorage(link, false)
+	require.NoError(t, err)
+	storage.Set(ksm.KEY_SERVER_PUBLIC_KEY_ID, "17")
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the config must still be a symlink")
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	config, _, err := decodeConfig(content)
+	require.NoError(t, err)
+	assert.Equal(t, "17", config[string(ksm.KEY_SERVER_PUBLIC_KEY_ID)])
+	assert.NoFileExists(t, target+".lock")
+}
+
+// TestFileConfigStorageKeepsConcurrentUpdates tests that updates written by another process since
+// the config was loaded are merged rather than overwritten.
+func TestFileConfigStorageKeepsConcurrentUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(testConfigA), 0600))
+
+	first, err := NewFileConfigStorage(path, false)
+	require.NoError(t, err)
+	second, err := NewFileConfigStorage(path, false)
+	require.NoError(t, err)
+
+	first.Set(ksm.KEY_SERVER_PUBLIC_KEY_ID, "17")
+	second.Set(ksm.KEY_HOSTNAME, "keepersecurity.eu")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	config, _, err := decodeConfig(content)
+	require.NoError(t, err)
+	assert.Equal(t, "17", config[string(ksm.KEY_SERVER_PUBLIC_KEY_ID)])
+	assert.Equal(t, "keepersecurity.eu", config[string(ksm.KEY_HOSTNAME)])
+	assert.Equal(t, "17", second.Get(ksm.KEY_SERVER_PUBLIC_KEY_ID), "the merged config is kept in memory")
+}
+
+// TestFileConfigStorageReadOnly tests that read-only storage keeps updates in memory without touching the file.
+func TestFileConfigStorageReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(testConfigA), 0600))
+
+	storage, err := NewFileConfigStorage(path, true)
+	require.NoError(t, err)
+
+	// Keeper treats an empty map from Set as a failure so read-only storage must still return the config.
+	assert.NotEmpty(t, storage.Set(ksm.KEY_HOSTNAME, "keepersecurity.eu"))
+	assert.Equal(t, "keepersecurity.eu", storage.Get(ksm.KEY_HOSTNAME))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testConfigA, string(content))
+}
+
+// TestFileConfigStorageInvalidContent tests that content that isn't JSON or base64 JSON is rejected.
+func TestFileConfigStorageInvalidContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte("not a config"), 0600))
+
+	_, err := NewFileConfigStorage(path, false)
+	require.ErrorIs(t, err, ErrInvalidConfigContent)
+}
+
+// TestFileLockWaitsForRelease tests that a held lock blocks other writers until it is released.
+func TestFileLockWaitsForRelease(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "config.json.lock")
+	unlock, err := acquireFileLock(lockPath)
+	require.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockSecond, err := acquireFileLock(lockPath)
+		assert.NoError(t, err)
+		unlockSecond()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while still held")
+	case <-time.After(2 * lockRetryInterval):
+	}
+
+	unlock()
+	<-acquired
+}
@@ -0,0 +1,74 @@
+package keeper_datasource
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CustomFields maps the label of each custom field of a record to its values.
+type CustomFields map[string][]string
+
+// HCL2Spec returns the hcl spec of custom_fields. packer-sdc only generates specs for
+// maps of strings, so the spec is defined here to keep every value of a field.
+func (*CustomFields) HCL2Spec() hcldec.Spec {
+	return &hcldec.AttrSpec{Name: "custom_fields", Type: cty.Map(cty.List(cty.String)), Required: false}
+}
+
+// getCustomFields returns the custom fields of a Keeper record keyed by label, or by type when the
+// field has no label, along with the values of the ones holding sensitive values. When more than
+// one field has the same key the first field is used.
+func getCustomFields(r *ksm.Record) (CustomFields, []string) {
+	customFields := CustomFields{}
+	secrets := []string{}
+	for _, f := range getAllRecordFields(r) {
+		if !f.Custom {
+			continue
+		}
+
+		key := f.Label
+		if key == "" {
+			key = f.Type
+		}
+
+		if _, ok := customFields[key]; ok {
+			continue
+		}
+		customFields[key] = f.Values
+
+		if IsSecretFieldType(f.Type) {
+			secrets = append(secrets, secretValues(f.Values)...)
+		}
+	}
+
+	return customFields, secrets
+}
+
+// secretValues returns the values of a sensitive field along with the string properties of
+// values that are objects (ex: the private key of a key pair).
+func secretValues(values []string) []string {
+	secrets := []string{}
+	for _, v := range values {
+		secrets = append(secrets, v)
+
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &object); err != nil {
+			continue
+		}
+		for _, property := range object {
+			if s, ok := property.(string); ok && s != "" {
+				secrets = append(secrets, s)
+			}
+		}
+	}
+
+	return secrets
+}
+
+// CustomSecretValues returns the values of the custom fields holding sensitive values,
+// so datasources can register them with the Packer secret filter.
+func (f *KeeperRecordField) CustomSecretValues() []string {
+	return f.customSecrets
+}
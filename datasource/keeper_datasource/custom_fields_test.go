@@ -0,0 +1,53 @@
+package keeper_datasource
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TestGetCustomFields tests that custom fields are returned by label on typed records and that sensitive values are tracked.
+func TestGetCustomFields(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "Build server",
+		"type": "login",
+		"fields": [
+			{"type": "login", "value": ["admin"]},
+			{"type": "password", "value": ["hunter2"]}
+		],
+		"custom": [
+			{"type": "text", "label": "environment", "value": ["staging"]},
+			{"type": "text", "label": "regions", "value": ["us-east-1", "eu-west-1"]},
+			{"type": "secret", "label": "api token", "value": ["token"]},
+			{"type": "keyPair", "value": [{"publicKey": "ssh-ed25519 AAAA", "privateKey": "PRIVATE KEY"}]},
+			{"type": "text", "label": "environment", "value": ["ignored"]},
+			{"type": "multiline", "label": "empty", "value": []}
+		]
+	}`)
+
+	login, err := client.GetLogin(RecordQuery{Uid: "record-uid"})
+	require.NoError(t, err)
+	assert.Equal(t, CustomFields{
+		"environment": {"staging"},
+		"regions":     {"us-east-1", "eu-west-1"},
+		"api token":   {"token"},
+		"keyPair":     {`{"privateKey":"PRIVATE KEY","publicKey":"ssh-ed25519 AAAA"}`},
+		"empty":       {},
+	}, login.CustomFields)
+
+	assert.ElementsMatch(t, []string{
+		"token",
+		`{"privateKey":"PRIVATE KEY","publicKey":"ssh-ed25519 AAAA"}`,
+		"PRIVATE KEY",
+		"ssh-ed25519 AAAA",
+	}, login.CustomSecretValues())
+
+	// The output spec keeps every value of a custom field.
+	value := hcl2helper.HCL2ValueFromConfig(login, (&FlatKeeperLogin{}).HCL2Spec())
+	regions := value.GetAttr("custom_fields").Index(cty.StringVal("regions"))
+	assert.True(t, regions.Equals(cty.ListVal([]cty.Value{cty.StringVal("us-east-1"), cty.StringVal("eu-west-1")})).True())
+}
@@ -5,15 +5,69 @@ import (
 )
 
 var (
-	ErrUidRequired = errors.New("uid is a required field")
+	ErrUidRequired             = errors.New("one of uid or title is required")
+	ErrUidAndTitle             = errors.New("only one of uid or title can be set")
+	ErrTitleFilterWithoutTitle = errors.New("folder_uid and record_type can only be set with title")
+	ErrMultipleConfigSources   = errors.New("only one of config_file, config_base64, config_json, token or config_command can be set")
 )
 
 // ValidateDataSourceConfig validates the configuration for all Keeper datasources.
-// uid is a required field for all datasources and must be set.
+// Exactly one of uid or title must be set to identify the record.
 func ValidateDataSourceConfig(config Config) error {
-	if config.Uid == nil || *config.Uid == "" {
+	hasUid := config.Uid != nil && *config.Uid != ""
+	if !hasUid && config.Title == "" {
 		return ErrUidRequired
 	}
 
+	if hasUid && config.Title != "" {
+		return ErrUidAndTitle
+	}
+
+	if hasUid && (config.FolderUid != "" || config.RecordType != "") {
+		return ErrTitleFilterWithoutTitle
+	}
+
+	if config.FieldIndex < 0 {
+		return ErrInvalidFieldIndex
+	}
+
+	if err := validateFileOptions(config); err != nil {
+		return err
+	}
+
+	return ValidateClientConfig(config.ClientConfig)
+}
+
+// ValidateClientConfig validates the client settings shared by all Keeper datasources.
+// At most one KSM config source can be set in HCL and the network settings must be valid.
+func ValidateClientConfig(config ClientConfig) error {
+	sources := 0
+	for _, v := range []string{config.ConfigFile, config.ConfigBase64, config.ConfigJSON, config.Token} {
+		if v != "" {
+			sources++
+		}
+	}
+
+	if len(config.ConfigCommand) > 0 {
+		sources++
+	}
+
+	if sources > 1 {
+		return ErrMultipleConfigSources
+	}
+
+	// Catch an invalid hostname or proxy before any request is made.
+	if _, err := getNetworkSettings(config); err != nil {
+		return err
+	}
+
+	if _, err := getCacheSettings(config); err != nil {
+		return err
+	}
+
+	if _, err := getOfflineSettings(config); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,177 @@
+package keeper_datasource
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Errors classifying why a datasource failed to read a record from Keeper. They are returned
+// wrapped in a KeeperError, so errors.Is matches them along with the underlying error.
+var (
+	ErrAccessDenied   = errors.New("access denied by Keeper")
+	ErrThrottled      = errors.New("request throttled by Keeper")
+	ErrInvalidConfig  = errors.New("invalid or expired KSM config")
+	ErrNetwork        = errors.New("unable to reach Keeper")
+	ErrMalformedField = errors.New("malformed field")
+	ErrIntegrity      = errors.New("attachment failed its integrity check")
+)
+
+// KeeperError is the error returned by datasources when reading a record fails. It names the
+// datasource and the record it was reading, and Hint explains how to fix the problem.
+type KeeperError struct {
+	// Datasource is the datasource that failed (ex: keeper-login).
+	Datasource string
+	// Uid or Title is the record the datasource was reading, neither is set when the datasource
+	// reads more than one record.
+	Uid   string
+	Title string
+	// Kind is the error class of Err (ex: ErrRecordNotFound), nil when Err couldn't be classified.
+	Kind error
+	// Hint is an actionable suggestion for fixing the problem, it can be empty.
+	Hint string
+	Err  error
+}
+
+func (e *KeeperError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Datasource)
+	switch {
+	case e.Uid != "":
+		fmt.Fprintf(&b, " (uid %q)", e.Uid)
+	case e.Title != "":
+		fmt.Fprintf(&b, " (title %q)", e.Title)
+	}
+	b.WriteString(": ")
+
+	if e.Kind != nil && !errors.Is(e.Err, e.Kind) {
+		b.WriteString(e.Kind.Error())
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+
+	if e.Hint != "" {
+		b.WriteString("; ")
+		b.WriteString(e.Hint)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the error class along with the underlying error.
+func (e *KeeperError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// WrapError wraps err in a KeeperError for the datasource reading the record of the query.
+// nil and errors that are already wrapped are returned as is.
+func WrapError(datasource string, query RecordQuery, err error) error {
+	return wrapError(datasource, query, "", err)
+}
+
+// WrapError wraps err like the package level WrapError, naming the KSM application of the
+// client in the hints when Keeper already told us its title.
+func (c *PackerKeeperClient) WrapError(datasource string, query RecordQuery, err error) error {
+	application := ""
+	if namer, ok := c.KeeperClient.(applicationNamer); ok {
+		application = namer.ApplicationName()
+	}
+
+	return wrapError(datasource, query, application, err)
+}
+
+// applicationNamer is implemented by clients knowing the title of their KSM application.
+type applicationNamer interface {
+	ApplicationName() string
+}
+
+func wrapError(datasource string, query RecordQuery, application string, err error) error {
+	var keeperErr *KeeperError
+	if err == nil || errors.As(err, &keeperErr) {
+		return err
+	}
+
+	kind, hint := classifyError(err, application)
+	return &KeeperError{
+		Datasource: datasource,
+		Uid:        query.Uid,
+		Title:      query.Title,
+		Kind:       kind,
+		Hint:       hint,
+		Err:        err,
+	}
+}
+
+// classifyError returns the error class of err and a hint for fixing it. Keeper doesn't return typed
+// errors for network failures, so those are recognized by the message Keeper wraps them in.
+func classifyError(err error, application string) (error, string) {
+	shareWith := "the KSM application of the config"
+	if application != "" {
+		shareWith = fmt.Sprintf("the KSM application %q", application)
+	}
+
+	var httpErr *ksm.KeeperHTTPError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrRecordNotFound):
+		return ErrRecordNotFound, fmt.Sprintf("check the uid or title and share the record, or a shared folder holding it, with %s", shareWith)
+	case errors.Is(err, ErrWrongRecordType):
+		return ErrWrongRecordType, "add the record type to accept_types, set strict_type = false or use keeper-record to read any record type"
+	case errors.Is(err, ErrFieldLabelNotFound), errors.Is(err, ErrFieldIndexOutOfRange):
+		return nil, "check field_label and field_index against the fields of the record in Keeper"
+	case errors.Is(err, ErrMalformedField):
+		return ErrMalformedField, "fix the value of the field in Keeper"
+	case errors.Is(err, ErrChecksumMismatch), errors.Is(err, ErrSizeMismatch):
+		return ErrIntegrity, "check the attachment in Keeper is the approved one, then update expected_sha256 with its digest"
+	case errors.Is(err, ErrSSHKeyPassphrase):
+		return ErrSSHKeyPassphrase, "set the password field of the record to the passphrase of the private key"
+	case errors.Is(err, ErrCertificateNotFound):
+		return ErrCertificateNotFound, "attach the certificate to the record in Keeper or set certificate_file to the name of its attachment"
+	case errors.Is(err, ErrInvalidCertificate):
+		return ErrInvalidCertificate, "check the attachment is a PEM, DER or PKCS#12 certificate and the password field of the record holds the PKCS#12 password"
+	case errors.Is(err, ErrCertificateExpired):
+		return ErrCertificateExpired, "renew the certificate and replace the attachment in Keeper"
+	case errors.Is(err, ErrCertificateExpiresSoon):
+		return ErrCertificateExpiresSoon, "renew the certificate and replace the attachment in Keeper before it enters the min_days_valid window, or lower min_days_valid"
+	case errors.Is(err, ErrFileDownload):
+		return ErrNetwork, "check the network connection to Keeper, or set max_file_size or file_glob to leave out large attachments"
+	case errors.Is(err, ErrTokenConfigMismatch):
+		return ErrInvalidConfig, "remove token_config_file to redeem the new token, or set token_config_file to another path"
+	case errors.Is(err, ErrClientInit), errors.Is(err, ErrInvalidConfigContent), errors.Is(err, ErrInvalidPassphrase), errors.Is(err, ErrMalformedEncrypted):
+		return ErrInvalidConfig, "check the KSM config is complete, or generate a new one from a one-time token"
+	case errors.As(err, &httpErr):
+		return classifyHTTPError(httpErr, shareWith)
+	case errors.As(err, &netErr), strings.Contains(err.Error(), "error during POST request"):
+		return ErrNetwork, "check the network connection to Keeper, hostname, proxy_url and ca_bundle_file, or set offline_fallback to use the last known records when Keeper is unreachable"
+	}
+
+	return nil, ""
+}
+
+// classifyHTTPError classifies an error response from Keeper.
+func classifyHTTPError(err *ksm.KeeperHTTPError, shareWith string) (error, string) {
+	resultCode := strings.ToLower(err.ResultCode)
+	message := strings.ToLower(err.Message)
+
+	switch {
+	case err.StatusCode == http.StatusTooManyRequests || resultCode == "throttled":
+		return ErrThrottled, "wait before running the build again, or raise max_retries and retry_max_delay"
+	case err.StatusCode == http.StatusUnauthorized || resultCode == "invalid_client" || resultCode == "invalid_client_version" || strings.Contains(message, "signature"):
+		return ErrInvalidConfig, fmt.Sprintf("the client device of the KSM config may have expired or been removed, add a new client device to %s and update the config", shareWith)
+	case err.StatusCode == http.StatusForbidden || resultCode == "access_denied":
+		return ErrAccessDenied, fmt.Sprintf("check %s still exists, has access to the record and that the client device of the KSM config hasn't expired", shareWith)
+	case err.StatusCode >= http.StatusInternalServerError:
+		return ErrNetwork, "Keeper is unavailable, try again later or set offline_fallback to use the last known records"
+	}
+
+	return nil, ""
+}
@@ -0,0 +1,110 @@
+package keeper_datasource
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorClassification tests that Keeper failures are classified, keep their cause and name the datasource and record.
+func TestErrorClassification(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		kind error
+		hint string
+	}{
+		"not found": {
+			err:  fmt.Errorf("%w for uid record-uid", ErrRecordNotFound),
+			kind: ErrRecordNotFound,
+			hint: "share the record",
+		},
+		"wrong type": {
+			err:  fmt.Errorf("%w Uid: record-uid ExpectedType: login, ActualType: file", ErrWrongRecordType),
+			kind: ErrWrongRecordType,
+			hint: "keeper-record",
+		},
+		"throttled": {
+			err:  fmt.Errorf("POST Error: %w", &ksm.KeeperHTTPError{StatusCode: 403, ResultCode: "throttled", Message: "Try again in 1 minute"}),
+			kind: ErrThrottled,
+			hint: "max_retries",
+		},
+		"access denied": {
+			err:  fmt.Errorf("POST Error: %w", &ksm.KeeperHTTPError{StatusCode: 403, ResultCode: "access_denied", Message: "Unable to validate application access"}),
+			kind: ErrAccessDenied,
+			hint: "has access to the record",
+		},
+		"revoked client": {
+			err:  fmt.Errorf("POST Error: %w", &ksm.KeeperHTTPError{StatusCode: 403, ResultCode: "access_denied", Message: "Signature is invalid"}),
+			kind: ErrInvalidConfig,
+			hint: "add a new client device",
+		},
+		"client init": {
+			err:  ErrClientInit,
+			kind: ErrInvalidConfig,
+			hint: "one-time token",
+		},
+		"network": {
+			err:  errors.New("error during POST request: Post \"https://keepersecurity.com/api/rest/sm/v1/get_secret\": dial tcp: connection refused"),
+			kind: ErrNetwork,
+			hint: "proxy_url",
+		},
+		"server error": {
+			err:  fmt.Errorf("POST Error: %w", &ksm.KeeperHTTPError{StatusCode: 502, Body: []byte("bad gateway")}),
+			kind: ErrNetwork,
+			hint: "offline_fallback",
+		},
+		"certificate expires soon": {
+			err:  fmt.Errorf("%w: it expires on 2026-11-01, less than 30 days from now", ErrCertificateExpiresSoon),
+			kind: ErrCertificateExpiresSoon,
+			hint: "min_days_valid window",
+		},
+		"malformed field": {
+			err:  fmt.Errorf("record record-uid: %w: host value isn't an object", ErrMalformedField),
+			kind: ErrMalformedField,
+			hint: "fix the value",
+		},
+	}
+
+	for name, test := range tests {
+		err := WrapError("keeper-login", RecordQuery{Uid: "record-uid"}, test.err)
+
+		var keeperErr *KeeperError
+		require.ErrorAs(t, err, &keeperErr, name)
+		assert.ErrorIs(t, err, test.kind, name)
+		assert.ErrorIs(t, err, test.err, name)
+		assert.Contains(t, keeperErr.Hint, test.hint, name)
+		assert.Contains(t, err.Error(), `keeper-login (uid "record-uid")`, name)
+		assert.Contains(t, err.Error(), test.kind.Error(), name)
+
+		// Wrapping twice keeps the first datasource.
+		assert.Same(t, err, WrapError("keeper-record", RecordQuery{}, err), name)
+	}
+
+	// A certificate that is still valid isn't reported as expired.
+	err := WrapError("keeper-certificate", RecordQuery{Uid: "cert-uid"}, fmt.Errorf("%w: it expires on 2026-11-01, less than 30 days from now", ErrCertificateExpiresSoon))
+	assert.ErrorIs(t, err, ErrCertificateExpiresSoon)
+	assert.False(t, errors.Is(err, ErrCertificateExpired))
+
+	err = WrapError("keeper-login", RecordQuery{Title: "database"}, errors.New("unexpected"))
+	assert.EqualError(t, err, `keeper-login (title "database"): unexpected`)
+	assert.NoError(t, WrapError("keeper-login", RecordQuery{}, nil))
+}
+
+// TestErrorHintNamesApplication tests that hints name the KSM application once Keeper returned its title.
+func TestErrorHintNamesApplication(t *testing.T) {
+	ksmClient, transport, _ := newOfflineTestClient(t, t.TempDir())
+	transport.appTitle = "Packer builds"
+	client := NewClient(ksmClient)
+
+	query := RecordQuery{Uid: "missing"}
+	_, err := client.GetRecord(query)
+	require.Error(t, err)
+
+	err = client.WrapError("keeper-login", query, err)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	assert.Contains(t, err.Error(), `share the record, or a shared folder holding it, with the KSM application "Packer builds"`)
+}
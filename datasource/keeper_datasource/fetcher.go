@@ -0,0 +1,170 @@
+package keeper_datasource
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// defaultBatchWindow is how long the fetcher waits for other datasources to request
+// records before sending them to Keeper in a single request.
+const defaultBatchWindow = 10 * time.Millisecond
+
+// recordFetcher batches record requests made within a short window into a single
+// GetSecrets call. Requests for a uid that is already being fetched wait for that
+// request instead of fetching it again, and each caller gets its own copy of the record.
+type recordFetcher struct {
+	client KeeperClient
+	window time.Duration
+
+	mu sync.Mutex
+	// pending are the requests waiting for the next batch.
+	pending map[string]*fetchCall
+	// inflight are the requests of batches sent to Keeper that haven't returned yet.
+	inflight map[string]*fetchCall
+}
+
+// fetchCall is the request for a single uid, shared by every caller requesting it.
+type fetchCall struct {
+	done   chan struct{}
+	record *ksm.Record
+	err    error
+}
+
+// newRecordFetcher creates a fetcher sending batches to the client.
+func newRecordFetcher(client KeeperClient) *recordFetcher {
+	return &recordFetcher{
+		client:   client,
+		window:   defaultBatchWindow,
+		pending:  map[string]*fetchCall{},
+		inflight: map[string]*fetchCall{},
+	}
+}
+
+// Get retrieves the records for the uids, in the same order. Duplicate uids are only fetched once.
+func (f *recordFetcher) Get(uids ...string) ([]*ksm.Record, error) {
+	return f.get(uids, false)
+}
+
+// GetShared retrieves the records for the uids like Get, leaving out the ones that aren't
+// shared with the application instead of failing.
+func (f *recordFetcher) GetShared(uids ...string) ([]*ksm.Record, error) {
+	return f.get(uids, true)
+}
+
+func (f *recordFetcher) get(uids []string, skipMissing bool) ([]*ksm.Record, error) {
+	calls := make([]*fetchCall, len(uids))
+
+	f.mu.Lock()
+	for i, uid := range uids {
+		call, ok := f.inflight[uid]
+		if !ok {
+			call, ok = f.pending[uid]
+		}
+
+		if !ok {
+			// The first request of a batch schedules it.
+			if len(f.pending) == 0 {
+				time.AfterFunc(f.window, f.flush)
+			}
+
+			call = &fetchCall{done: make(chan struct{})}
+			f.pending[uid] = call
+		}
+
+		calls[i] = call
+	}
+	f.mu.Unlock()
+
+	records := make([]*ksm.Record, 0, len(uids))
+	for _, call := range calls {
+		<-call.done
+		if skipMissing && errors.Is(call.err, ErrRecordNotFound) {
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+
+		records = append(records, copyRecord(call.record))
+	}
+
+	return records, nil
+}
+
+// flush sends the pending requests to Keeper and hands the records to the callers waiting for them.
+func (f *recordFetcher) flush() {
+	f.mu.Lock()
+	batch := f.pending
+	f.pending = map[string]*fetchCall{}
+	for uid, call := range batch {
+		f.inflight[uid] = call
+	}
+	f.mu.Unlock()
+
+	uids := make([]string, 0, len(batch))
+	for uid := range batch {
+		uids = append(uids, uid)
+	}
+
+	records, err := f.client.GetSecrets(uids)
+
+	found := make(map[string]*ksm.Record, len(records))
+	for _, r := range records {
+		found[r.Uid] = r
+	}
+
+	f.mu.Lock()
+	for uid, call := range batch {
+		delete(f.inflight, uid)
+
+		switch {
+		case err != nil:
+			call.err = err
+		case found[uid] == nil:
+			call.err = fmt.Errorf("%w for uid %s", ErrRecordNotFound, uid)
+		default:
+			call.record = found[uid]
+		}
+
+		close(call.done)
+	}
+	f.mu.Unlock()
+}
+
+// copyRecord returns a copy of the record that can be changed without affecting other callers.
+func copyRecord(r *ksm.Record) *ksm.Record {
+	c := *r
+	c.RecordDict, _ = copyValue(r.RecordDict).(map[string]interface{})
+
+	c.Files = make([]*ksm.KeeperFile, 0, len(r.Files))
+	for _, file := range r.Files {
+		fileCopy := *file
+		c.Files = append(c.Files, &fileCopy)
+	}
+
+	return &c
+}
+
+// copyValue deep copies a value decoded from JSON.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = copyValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = copyValue(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,77 @@
+package keeper_datasource
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchKeeperClient records the uids of each GetSecrets call and returns a record for every uid except missing ones.
+type batchKeeperClient struct {
+	KeeperClient
+
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (c *batchKeeperClient) GetSecrets(uids []string) ([]*ksm.Record, error) {
+	c.mu.Lock()
+	batch := append([]string{}, uids...)
+	sort.Strings(batch)
+	c.batches = append(c.batches, batch)
+	c.mu.Unlock()
+
+	records := []*ksm.Record{}
+	for _, uid := range uids {
+		if uid == "missing" {
+			continue
+		}
+		data := fmt.Sprintf(`{"uid": "%s", "title": "%s", "type": "login"}`, uid, uid)
+		record := recordFromJSON(data)
+		record.RawJson = data
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// TestConcurrentFetchesAreBatched tests that concurrent requests are sent in one call, with each uid requested once.
+func TestConcurrentFetchesAreBatched(t *testing.T) {
+	keeperClient := &batchKeeperClient{}
+	client := NewClient(keeperClient)
+	client.fetcher.window = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	records := make([]*ksm.Record, 20)
+	for i := range records {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			record, err := client.GetRecord(RecordQuery{Uid: fmt.Sprintf("uid-%d", i%4)})
+			assert.NoError(t, err)
+			records[i] = record
+		}(i)
+	}
+	wg.Wait()
+
+	require.Len(t, keeperClient.batches, 1)
+	assert.Equal(t, []string{"uid-0", "uid-1", "uid-2", "uid-3"}, keeperClient.batches[0])
+
+	// Callers requesting the same uid get their own copy of the record.
+	require.NotNil(t, records[0])
+	require.NotNil(t, records[4])
+	assert.Equal(t, "uid-0", records[4].Uid)
+	assert.NotSame(t, records[0], records[4])
+	records[0].RecordDict["title"] = "changed"
+	assert.Equal(t, "uid-0", records[4].Title())
+
+	// A uid missing from the response fails only the callers requesting it.
+	_, err := client.GetRecord(RecordQuery{Uid: "missing"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	require.Len(t, keeperClient.batches, 2)
+}
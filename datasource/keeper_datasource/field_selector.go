@@ -0,0 +1,230 @@
+package keeper_datasource
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Errors for selecting an entry of a multi-value field.
+var (
+	ErrInvalidFieldIndex    = errors.New("field_index can't be negative")
+	ErrFieldLabelNotFound   = errors.New("no field matches field_label")
+	ErrFieldIndexOutOfRange = errors.New("field_index is out of range")
+)
+
+// FieldSelector chooses which entry of a multi-value field (ex: the urls of a login) feeds the
+// scalar output of a datasource. The zero value selects the first entry.
+type FieldSelector struct {
+	// Index is the index of the entry among the entries of the fields matching Label.
+	Index int
+	// Label limits the entries to the ones of fields with this label.
+	Label string
+}
+
+// fieldEntry is a single value of a record field along with the label of its field.
+type fieldEntry struct {
+	label string
+	value interface{}
+}
+
+// getFieldEntries returns every value of the standard and custom fields of fieldType, standard fields first.
+func getFieldEntries(r *ksm.Record, fieldType string) []fieldEntry {
+	entries := []fieldEntry{}
+	for _, f := range r.GetFieldsByMask(fieldType, ksm.FieldTokenType, ksm.FieldSectionFields|ksm.FieldSectionCustom) {
+		label, _ := f["label"].(string)
+		values, _ := f["value"].([]interface{})
+		for _, v := range values {
+			entries = append(entries, fieldEntry{label: label, value: v})
+		}
+	}
+
+	return entries
+}
+
+// selectEntry returns the value of the entry the selector chooses. nil is returned when the
+// record has no entries and the selector is the zero value, so records missing an optional
+// field keep working.
+func (s FieldSelector) selectEntry(entries []fieldEntry, fieldType string) (interface{}, error) {
+	i, err := s.selectIndex(entries, fieldType)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+
+	return entries[i].value, nil
+}
+
+// selectIndex returns the index in entries of the entry the selector chooses, -1 when the record
+// has no entries and the selector is unset.
+func (s FieldSelector) selectIndex(entries []fieldEntry, fieldType string) (int, error) {
+	matches := make([]int, 0, len(entries))
+	for i, e := range entries {
+		if s.Label == "" || e.label == s.Label {
+			matches = append(matches, i)
+		}
+	}
+
+	if s.Label != "" && len(matches) == 0 {
+		return -1, fmt.Errorf("%w: no %s field has the label %q", ErrFieldLabelNotFound, fieldType, s.Label)
+	}
+
+	if s.Index < len(matches) {
+		return matches[s.Index], nil
+	}
+
+	if s == (FieldSelector{}) {
+		return -1, nil
+	}
+
+	return -1, fmt.Errorf("%w: field_index %d, the record has %d %s values", ErrFieldIndexOutOfRange, s.Index, len(matches), fieldType)
+}
+
+// getFieldStrings returns every value of the fields of fieldType as a string, along with the one the selector chooses.
+func getFieldStrings(r *ksm.Record, fieldType string, s FieldSelector) ([]string, string, error) {
+	entries := getFieldEntries(r, fieldType)
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		value, err := fieldValueString(e.value)
+		if err != nil {
+			continue
+		}
+		values = append(values, value)
+	}
+
+	selected, err := s.selectEntry(entries, fieldType)
+	if err != nil || selected == nil {
+		return values, "", err
+	}
+
+	value, _ := fieldValueString(selected)
+	return values, value, nil
+}
+
+// getHosts returns every host of the record along with the one the selector chooses.
+func getHosts(r *ksm.Record, s FieldSelector) ([]HostConnection, HostConnection, error) {
+	entries := getFieldEntries(r, "host")
+	hosts := make([]HostConnection, 0, len(entries))
+	for _, e := range entries {
+		hosts = append(hosts, parseHost(e.value))
+	}
+
+	selected, err := s.selectEntry(entries, "host")
+	if err != nil || selected == nil {
+		return hosts, HostConnection{}, err
+	}
+
+	if _, ok := selected.(map[string]interface{}); !ok {
+		return hosts, HostConnection{}, fmt.Errorf("%w: host value isn't an object", ErrMalformedField)
+	}
+
+	return hosts, parseHost(selected), nil
+}
+
+// getKeyPairs returns every key pair of the record along with the index of the one the selector
+// chooses, -1 when the record has none.
+func getKeyPairs(r *ksm.Record, s FieldSelector) ([]KeyPair, int, error) {
+	entries := getFieldEntries(r, "keyPair")
+	keyPairs := make([]KeyPair, 0, len(entries))
+	for _, e := range entries {
+		keyPairs = append(keyPairs, parseKeyPair(e.value))
+	}
+
+	selected, err := s.selectIndex(entries, "keyPair")
+	if err != nil || selected < 0 {
+		return keyPairs, -1, err
+	}
+
+	if _, ok := entries[selected].value.(map[string]interface{}); !ok {
+		return keyPairs, -1, fmt.Errorf("%w: keyPair value isn't an object", ErrMalformedField)
+	}
+
+	return keyPairs, selected, nil
+}
+
+// parseHost extracts the host connection data from a host field value
+func parseHost(value interface{}) HostConnection {
+	hc := HostConnection{}
+
+	// Make sure the value is a map
+	valuesMap, ok := value.(map[string]interface{})
+	if !ok {
+		return hc
+	}
+
+	// Extract the host connection data from the map
+	if val, ok := valuesMap["hostName"].(string); ok {
+		hc.HostName = val
+	}
+
+	// Extract the port from the map
+	if val, ok := valuesMap["port"].(string); ok {
+		// Attempt to convert the port from a string to an int
+		// if it fails return -1
+		port, err := strconv.Atoi(val)
+		if err != nil {
+			hc.Port = -1
+			return hc
+		}
+
+		hc.Port = port
+	}
+
+	return hc
+}
+
+// parseKeyPair extracts the key pair data from a keyPair field value
+func parseKeyPair(value interface{}) KeyPair {
+	keypair := KeyPair{}
+
+	// Make sure the value is a map
+	valuesMap, ok := value.(map[string]interface{})
+	if !ok {
+		return keypair
+	}
+
+	// Extract the key pair data from the map
+	if pub, ok := valuesMap["publicKey"].(string); ok {
+		keypair.PublicKey = pub
+	}
+
+	if priv, ok := valuesMap["privateKey"].(string); ok {
+		keypair.PrivateKey = priv
+	}
+
+	return keypair
+}
+
+// getFieldValue returns the values of the first field of the type joined like Keeper does. Custom
+// fields are used when the record has no standard field of the type with a value, as record types
+// derived from the standard ones often keep their fields there.
+func getFieldValue(r *ksm.Record, fieldType string) string {
+	if value := r.GetFieldValueByType(fieldType); value != "" {
+		return value
+	}
+
+	for _, f := range r.GetFieldsByMask(fieldType, ksm.FieldTokenType, ksm.FieldSectionCustom) {
+		if value := formatFieldValues(f); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// formatFieldValues joins the values of a field like Keeper does.
+func formatFieldValues(f map[string]interface{}) string {
+	values, _ := f["value"].([]interface{})
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		// Keeper decodes numbers as floats, format whole numbers as integers like it does.
+		if f, ok := v.(float64); ok && f == float64(int(f)) {
+			v = int(f)
+		}
+		formatted = append(formatted, fmt.Sprintf("%v", v))
+	}
+
+	return strings.Join(formatted, ", ")
+}
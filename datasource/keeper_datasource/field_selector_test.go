@@ -0,0 +1,94 @@
+package keeper_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFieldSelection tests that every url is returned and that field_index and field_label choose the scalar url.
+func TestFieldSelection(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "Build server",
+		"type": "login",
+		"fields": [
+			{"type": "login", "value": ["admin"]},
+			{"type": "url", "value": ["https://primary.example.com"]}
+		],
+		"custom": [
+			{"type": "url", "label": "mirror", "value": ["https://mirror-1.example.com", "https://mirror-2.example.com"]}
+		]
+	}`)
+
+	urls := []string{"https://primary.example.com", "https://mirror-1.example.com", "https://mirror-2.example.com"}
+	tests := map[string]struct {
+		field    FieldSelector
+		expected string
+	}{
+		"default":         {FieldSelector{}, "https://primary.example.com"},
+		"index":           {FieldSelector{Index: 2}, "https://mirror-2.example.com"},
+		"label":           {FieldSelector{Label: "mirror"}, "https://mirror-1.example.com"},
+		"label and index": {FieldSelector{Label: "mirror", Index: 1}, "https://mirror-2.example.com"},
+	}
+	for name, test := range tests {
+		login, err := client.GetLogin(RecordQuery{Uid: "record-uid", Field: test.field})
+		require.NoError(t, err, name)
+		assert.Equal(t, test.expected, login.Url, name)
+		assert.Equal(t, urls, login.Urls, name)
+	}
+
+	_, err := client.GetLogin(RecordQuery{Uid: "record-uid", Field: FieldSelector{Index: 3}})
+	require.ErrorIs(t, err, ErrFieldIndexOutOfRange)
+
+	_, err = client.GetLogin(RecordQuery{Uid: "record-uid", Field: FieldSelector{Label: "missing"}})
+	require.ErrorIs(t, err, ErrFieldLabelNotFound)
+
+	err = ValidateDataSourceConfig(Config{Title: "Build server", FieldIndex: -1})
+	require.ErrorIs(t, err, ErrInvalidFieldIndex)
+}
+
+// TestMultipleHostsAndKeyPairs tests that every host and key pair is returned and the selected ones feed the scalar outputs.
+func TestMultipleHostsAndKeyPairs(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "Deploy key",
+		"type": "sshKeys",
+		"fields": [
+			{"type": "keyPair", "value": [{"publicKey": "ssh-ed25519 AAAA", "privateKey": "PRIVATE KEY 1"}]},
+			{"type": "host", "value": [{"hostName": "bastion.example.com", "port": "22"}]}
+		],
+		"custom": [
+			{"type": "keyPair", "label": "rotated", "value": [{"publicKey": "ssh-ed25519 BBBB", "privateKey": "PRIVATE KEY 2"}]},
+			{"type": "host", "label": "replica", "value": [{"hostName": "replica.example.com", "port": "2222"}]}
+		]
+	}`)
+
+	sshKey, err := client.GetSSHKey(RecordQuery{Uid: "record-uid", Field: FieldSelector{Label: "rotated"}})
+	require.NoError(t, err)
+	assert.Equal(t, KeyPair{PublicKey: "ssh-ed25519 BBBB", PrivateKey: "PRIVATE KEY 2"}, sshKey.KeyPair)
+	assert.Len(t, sshKey.KeyPairs, 2)
+
+	// The host isn't affected by the selector.
+	assert.Equal(t, HostConnection{HostName: "bastion.example.com", Port: 22}, sshKey.HostConnection)
+	assert.Equal(t, []HostConnection{
+		{HostName: "bastion.example.com", Port: 22},
+		{HostName: "replica.example.com", Port: 2222},
+	}, sshKey.Hosts)
+}
+
+// TestMalformedHost tests that a selected host that isn't an object is reported as a malformed field.
+func TestMalformedHost(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "Web server",
+		"type": "serverCredentials",
+		"fields": [
+			{"type": "host", "value": ["web.example.com:22"]}
+		]
+	}`)
+
+	_, err := client.GetServerCredentials(RecordQuery{Uid: "record-uid"})
+	require.ErrorIs(t, err, ErrMalformedField)
+}
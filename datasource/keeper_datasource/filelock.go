@@ -0,0 +1,51 @@
+package keeper_datasource
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// lockRetryInterval is how long to wait between attempts to acquire a lock file.
+	lockRetryInterval = 50 * time.Millisecond
+	// lockTimeout is how long to wait for a lock file before giving up.
+	lockTimeout = 10 * time.Second
+	// lockStaleAfter is how old a lock file must be before it is considered abandoned
+	// by a crashed process and removed.
+	lockStaleAfter = 30 * time.Second
+)
+
+var ErrLockTimeout = errors.New("timed out waiting for lock file")
+
+// acquireFileLock creates path exclusively, waiting for other processes holding the lock to release it.
+// The returned function releases the lock. Lock files are used instead of flock so locking behaves
+// the same on every platform Packer runs on.
+func acquireFileLock(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("unable to create lock file %s: %w", path, err)
+		}
+
+		// Remove locks left behind by processes that exited without releasing them.
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w %s", ErrLockTimeout, path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
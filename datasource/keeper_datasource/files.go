@@ -0,0 +1,479 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// defaultFileMode is the permissions of attachments written to disk, they often hold secrets.
+const defaultFileMode = os.FileMode(0600)
+
+// Errors for writing attachments to disk.
+var (
+	ErrInvalidFileMode        = errors.New("file_mode and the mode of file blocks must be octal permissions (ex: 0600)")
+	ErrInvalidFileGlob        = errors.New("file_glob and the name of file blocks must be valid globs")
+	ErrInvalidDestinationName = errors.New("destination_name of file blocks must be a file name without directories")
+	ErrFileOptionsWithoutDir  = errors.New("file_mode, file blocks and keep can only be set with destination_dir")
+	ErrFileConflict           = errors.New("more than one attachment is written to the same file")
+	ErrInvalidMaxFileSize     = errors.New("max_file_size can't be negative")
+	ErrFileDownload           = errors.New("unable to download attachment")
+	ErrInvalidChecksum        = errors.New("expected_sha256 values must be hex encoded SHA-256 digests")
+	ErrChecksumMismatch       = errors.New("attachment doesn't match its expected_sha256")
+	ErrSizeMismatch           = errors.New("attachment doesn't have the size listed by Keeper")
+)
+
+// FileOptions controls which attachments of a record are downloaded and how they are written to disk.
+type FileOptions struct {
+	// Download downloads every attachment not left out by the filters.
+	Download bool
+	// Names limits the attachments downloaded to the ones with these names.
+	Names []string
+	// MaxSize skips attachments larger than this many bytes, no limit when 0.
+	MaxSize int64
+	// ExpectedSHA256 are the SHA-256 digests attachments must match, keyed by name. They are always downloaded.
+	ExpectedSHA256 map[string]string
+	// VerifySize checks the content downloaded has the size listed by Keeper.
+	VerifySize bool
+	// DestinationDir is the directory attachments are written to, they aren't written when empty.
+	DestinationDir string
+	// Mode is the permissions of the files written.
+	Mode os.FileMode
+	// Glob limits the attachments downloaded to the ones whose name matches.
+	Glob string
+	// Files set the name and permissions of the attachments they match.
+	Files []FileDestination
+	// Keep leaves the files on disk when the plugin exits.
+	Keep bool
+}
+
+// parseFileMode parses octal permissions, an empty mode is the default.
+func parseFileMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return defaultFileMode, nil
+	}
+
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || perm > 0777 {
+		return 0, fmt.Errorf("%w, got %q", ErrInvalidFileMode, mode)
+	}
+
+	return os.FileMode(perm), nil
+}
+
+// validateFileOptions checks the attachment settings of the datasource config.
+func validateFileOptions(c Config) error {
+	if c.MaxFileSize < 0 {
+		return ErrInvalidMaxFileSize
+	}
+
+	for name, sum := range c.ExpectedSHA256 {
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("%w, got %q for %s", ErrInvalidChecksum, sum, name)
+		}
+	}
+
+	if c.DestinationDir == "" && (c.FileMode != "" || len(c.Files) > 0 || c.Keep) {
+		return ErrFileOptionsWithoutDir
+	}
+
+	if _, err := parseFileMode(c.FileMode); err != nil {
+		return err
+	}
+
+	if _, err := path.Match(c.FileGlob, ""); err != nil {
+		return fmt.Errorf("%w, got %q", ErrInvalidFileGlob, c.FileGlob)
+	}
+
+	for _, f := range c.Files {
+		if _, err := path.Match(f.Name, ""); err != nil || f.Name == "" {
+			return fmt.Errorf("%w, got %q", ErrInvalidFileGlob, f.Name)
+		}
+
+		if _, err := parseFileMode(f.Mode); err != nil {
+			return err
+		}
+
+		if strings.ContainsAny(f.DestinationName, `/\`) || f.DestinationName == "." || f.DestinationName == ".." {
+			return fmt.Errorf("%w, got %q", ErrInvalidDestinationName, f.DestinationName)
+		}
+	}
+
+	return nil
+}
+
+// fileOptions returns the attachment settings of the datasource config, which must be valid.
+func (c *Config) fileOptions() FileOptions {
+	mode, _ := parseFileMode(c.FileMode)
+	return FileOptions{
+		Download:       c.DownloadFiles != nil && *c.DownloadFiles,
+		Names:          c.FileNames,
+		MaxSize:        c.MaxFileSize,
+		ExpectedSHA256: c.ExpectedSHA256,
+		VerifySize:     c.VerifySize,
+		DestinationDir: c.DestinationDir,
+		Mode:           mode,
+		Glob:           c.FileGlob,
+		Files:          c.Files,
+		Keep:           c.Keep,
+	}
+}
+
+// downloads reports whether the options ask for the content of attachments, only their metadata
+// is listed otherwise.
+func (o FileOptions) downloads() bool {
+	return o.Download || o.DestinationDir != "" || len(o.Names) > 0 || o.Glob != "" || len(o.Files) > 0 || o.MaxSize > 0 ||
+		len(o.ExpectedSHA256) > 0
+}
+
+// selected reports whether an attachment is downloaded. When file_names, file_glob or file blocks
+// are set only the attachments matching one of them are, along with the ones with an expected digest.
+func (o FileOptions) selected(name string) bool {
+	if _, ok := o.ExpectedSHA256[name]; ok {
+		return true
+	}
+
+	// Without filters every attachment is downloaded, unless only expected_sha256 asked for downloads.
+	if len(o.Names) == 0 && o.Glob == "" && len(o.Files) == 0 {
+		return o.Download || o.DestinationDir != "" || o.MaxSize > 0
+	}
+
+	for _, n := range o.Names {
+		if n == name {
+			return true
+		}
+	}
+
+	if ok, _ := path.Match(o.Glob, name); ok && o.Glob != "" {
+		return true
+	}
+
+	for _, f := range o.Files {
+		if ok, _ := path.Match(f.Name, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// destination returns the file name and permissions an attachment is written with, from the first
+// file block matching it.
+func (o FileOptions) destination(name string) (string, os.FileMode) {
+	for _, f := range o.Files {
+		if ok, _ := path.Match(f.Name, name); !ok {
+			continue
+		}
+
+		mode := o.Mode
+		if f.Mode != "" {
+			mode, _ = parseFileMode(f.Mode)
+		}
+		if f.DestinationName != "" {
+			name = f.DestinationName
+		}
+		return name, mode
+	}
+
+	return name, o.Mode
+}
+
+// downloadFile downloads the content of an attachment through the transport used to reach Keeper,
+// so it goes through the same proxy, CA bundle and retries. The default HTTP transport is used when
+// transport is nil. Content Keeper already returned is used as is.
+//
+// Keeper encrypts attachments with AES-GCM which can only be decrypted as a whole, so the encrypted
+// content is read into memory and decrypted in place. When maxSize is above 0 downloads of content
+// larger than maxSize bytes are stopped, whatever size Keeper listed, so at most maxSize bytes and the
+// encryption overhead are held in memory.
+func downloadFile(transport http.RoundTripper, f *ksm.KeeperFile, maxSize int64) ([]byte, error) {
+	if len(f.FileData) > 0 {
+		return f.FileData, nil
+	}
+
+	fileURL := f.GetUrl()
+	if fileURL == "" {
+		return nil, fmt.Errorf("%w %s (%s): Keeper returned no download URL", ErrFileDownload, f.Uid, f.Name)
+	}
+
+	fileKey := f.DecryptFileKey()
+	if len(fileKey) == 0 {
+		return nil, fmt.Errorf("%w %s (%s): unable to decrypt the file key", ErrFileDownload, f.Uid, f.Name)
+	}
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(fileURL)
+	if err != nil {
+		// The URL is signed, leave it out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%w %s (%s): %w", ErrFileDownload, f.Uid, f.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w %s (%s): %s", ErrFileDownload, f.Uid, f.Name, resp.Status)
+	}
+
+	body := io.Reader(resp.Body)
+	limit := maxSize + encryptionOverhead
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+
+	encrypted, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s (%s): %w", ErrFileDownload, f.Uid, f.Name, err)
+	}
+
+	if maxSize > 0 && int64(len(encrypted)) > limit {
+		return nil, fmt.Errorf("%w %s (%s): the content is larger than max_file_size", ErrFileDownload, f.Uid, f.Name)
+	}
+
+	data, err := decryptInPlace(encrypted, fileKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s (%s): unable to decrypt the content: %w", ErrFileDownload, f.Uid, f.Name, err)
+	}
+
+	return data, nil
+}
+
+// encryptionOverhead is how many bytes AES-GCM encryption adds to attachments, their nonce and tag.
+const encryptionOverhead = ksm.AesGcmNonceSize + 16
+
+// decryptInPlace decrypts content encrypted with AES-GCM like ksm.Decrypt, reusing the memory of the
+// encrypted content for the decrypted one.
+func decryptInPlace(data, key []byte) ([]byte, error) {
+	if len(data) <= ksm.AesGcmNonceSize {
+		return nil, errors.New("the content is too short")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext := data[:ksm.AesGcmNonceSize], data[ksm.AesGcmNonceSize:]
+	return gcm.Open(ciphertext[:0], nonce, ciphertext, nil)
+}
+
+// downloadFiles downloads the attachments of the record selected by the options. Their content is
+// set on their file ref, or written to the destination directory with the path of each file set on
+// its file ref instead.
+func (c *PackerKeeperClient) downloadFiles(r *ksm.Record, fields *KeeperRecordField, options FileOptions) error {
+	if !options.downloads() {
+		return nil
+	}
+
+	if options.DestinationDir != "" {
+		if err := os.MkdirAll(options.DestinationDir, 0700); err != nil {
+			return fmt.Errorf("unable to create destination_dir: %w", err)
+		}
+	}
+
+	refs := map[string]*FileRef{}
+	for i := range fields.FileRefs {
+		refs[fields.FileRefs[i].Uid] = &fields.FileRefs[i]
+	}
+
+	written := map[string]string{}
+	verified := map[string]bool{}
+	for _, f := range r.Files {
+		// Attachment names come from the vault, never let them point outside the directory.
+		name := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(f.Name, `\`, "/")))
+		if name == "/" || name == "." {
+			name = f.Uid
+		}
+
+		if !options.selected(name) {
+			continue
+		}
+
+		_, pinned := options.ExpectedSHA256[name]
+		if options.MaxSize > 0 && int64(f.Size) > options.MaxSize && !pinned {
+			log.Printf("[INFO] skipping attachment %s of %d bytes, larger than max_file_size", f.Uid, f.Size)
+			continue
+		}
+
+		maxSize := options.MaxSize
+		if pinned {
+			maxSize = 0
+		}
+
+		data, err := downloadFile(c.transport, f, maxSize)
+		if err != nil {
+			return err
+		}
+
+		verify := func(d fileDigest) error {
+			if options.VerifySize && d.Size != int64(f.Size) {
+				return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, f.Name, d.Size, f.Size)
+			}
+
+			if pinned {
+				if expected := options.ExpectedSHA256[name]; !strings.EqualFold(expected, hex.EncodeToString(d.SHA256)) {
+					return fmt.Errorf("%w: %s has SHA-256 %x, expected %s", ErrChecksumMismatch, f.Name, d.SHA256, expected)
+				}
+				verified[name] = true
+			}
+
+			return nil
+		}
+
+		ref, ok := refs[f.Uid]
+		if !ok || options.DestinationDir == "" {
+			var encoded strings.Builder
+			encoder := base64.NewEncoder(base64.StdEncoding, &encoded)
+			digest, err := copyWithDigest(encoder, bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			encoder.Close()
+
+			if err := verify(digest); err != nil {
+				return err
+			}
+
+			if ok {
+				ref.SHA256 = hex.EncodeToString(digest.SHA256)
+				ref.SHA512 = hex.EncodeToString(digest.SHA512)
+				ref.Base64Data = encoded.String()
+			}
+			continue
+		}
+
+		name, mode := options.destination(name)
+
+		if other, ok := written[name]; ok {
+			return fmt.Errorf("%w: attachments %s and %s are both written to %s, set destination_name in a file block", ErrFileConflict, other, f.Uid, name)
+		}
+		written[name] = f.Uid
+
+		// The digest is checked before the file is renamed into place, so a file failing it is never written.
+		var digest fileDigest
+		var verifyErr error
+		p := filepath.Join(options.DestinationDir, name)
+		err = writeAttachment(p, bytes.NewReader(data), mode, options.Keep, func(d fileDigest) error {
+			digest = d
+			verifyErr = verify(d)
+			return verifyErr
+		})
+		if verifyErr != nil {
+			return verifyErr
+		}
+		if err != nil {
+			return fmt.Errorf("unable to write attachment %s to %s: %w", f.Uid, p, err)
+		}
+
+		ref.SHA256 = hex.EncodeToString(digest.SHA256)
+		ref.SHA512 = hex.EncodeToString(digest.SHA512)
+
+		if fields.FilePaths == nil {
+			fields.FilePaths = map[string]string{}
+		}
+		fields.FilePaths[f.Name] = p
+		ref.Path = p
+	}
+
+	// An approved attachment removed from the record fails the build like one that was changed.
+	for name := range options.ExpectedSHA256 {
+		if !verified[name] {
+			return fmt.Errorf("%w: the record has no attachment named %s", ErrChecksumMismatch, name)
+		}
+	}
+
+	return nil
+}
+
+// fileDigest is the size and digests of the content of an attachment.
+type fileDigest struct {
+	Size   int64
+	SHA256 []byte
+	SHA512 []byte
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// copyWithDigest copies content to w, hashing and counting it on the way.
+func copyWithDigest(w io.Writer, content io.Reader) (fileDigest, error) {
+	sha256Hash := sha256.New()
+	sha512Hash := sha512.New()
+	var size byteCounter
+
+	if _, err := io.Copy(io.MultiWriter(w, sha256Hash, sha512Hash, &size), content); err != nil {
+		return fileDigest{}, err
+	}
+
+	return fileDigest{Size: int64(size), SHA256: sha256Hash.Sum(nil), SHA512: sha512Hash.Sum(nil)}, nil
+}
+
+// writeAttachment streams content to a temporary file next to path which is renamed over it once
+// complete, so provisioners never read a partially written file. check is called with the digest of
+// the content before the rename, the file isn't written when it fails. Files not kept are removed
+// once the plugin exits.
+func writeAttachment(p string, content io.Reader, mode os.FileMode, keep bool, check func(fileDigest) error) error {
+	if !keep {
+		// Register the file before writing it so it can't be left behind.
+		if err := registerCleanup(p); err != nil {
+			return fmt.Errorf("unable to start removing attachments once the build is done, set keep = true to leave them on disk: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	digest, err := copyWithDigest(tmp, content)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := check(digest); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
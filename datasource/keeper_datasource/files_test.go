@@ -0,0 +1,364 @@
+package keeper_datasource
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAttachmentsClient returns a client reading a file record with the attachments, keyed by name.
+func newAttachmentsClient(attachments map[string]string) *PackerKeeperClient {
+	record := recordFromJSON(`{"uid": "file-uid", "title": "installers", "type": "file", "fields": []}`)
+	for name, content := range attachments {
+		record.Files = append(record.Files, &ksm.KeeperFile{Uid: name + "-uid", Name: name, Title: name, Size: len(content), FileData: []byte(content)})
+	}
+
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(record, nil)
+	return NewClient(mockClient)
+}
+
+// TestWriteFiles tests that attachments are written to destination_dir with their mode and name, leaving their content out of the outputs.
+func TestWriteFiles(t *testing.T) {
+	t.Cleanup(CleanupFiles)
+	dir := filepath.Join(t.TempDir(), "files")
+	client := newAttachmentsClient(map[string]string{
+		"setup.msi":  "installer",
+		"readme.txt": "readme",
+		"id_rsa.pem": "private key",
+	})
+
+	config := Config{
+		DestinationDir: dir,
+		FileGlob:       "*.msi",
+		Files:          []FileDestination{{Name: "*.pem", DestinationName: "key.pem", Mode: "0400"}},
+	}
+	require.NoError(t, validateFileOptions(config))
+
+	file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: config.fileOptions()})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"setup.msi":  filepath.Join(dir, "setup.msi"),
+		"id_rsa.pem": filepath.Join(dir, "key.pem"),
+	}, file.FilePaths)
+
+	for _, ref := range file.FileRefs {
+		switch ref.Name {
+		case "readme.txt":
+			assert.Empty(t, ref.Path)
+			assert.Empty(t, ref.Base64Data)
+			assert.NoFileExists(t, filepath.Join(dir, "readme.txt"))
+		default:
+			assert.Equal(t, file.FilePaths[ref.Name], ref.Path)
+			assert.Empty(t, ref.Base64Data)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(content))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "key.pem"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(dir, "setup.msi"))
+		require.NoError(t, err)
+		assert.Equal(t, defaultFileMode, info.Mode().Perm())
+	}
+
+	// Files are removed when the plugin exits.
+	CleanupFiles()
+	assert.NoFileExists(t, filepath.Join(dir, "setup.msi"))
+	assert.NoFileExists(t, filepath.Join(dir, "key.pem"))
+}
+
+// TestWriteFilesKeep tests that kept files survive cleanup and that attachment names can't escape destination_dir.
+func TestWriteFilesKeep(t *testing.T) {
+	dir := t.TempDir()
+	client := newAttachmentsClient(map[string]string{"../../escape.sh": "#!/bin/sh"})
+
+	file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: dir, Mode: defaultFileMode, Keep: true}})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "escape.sh"), file.FilePaths["../../escape.sh"])
+
+	CleanupFiles()
+	assert.FileExists(t, filepath.Join(dir, "escape.sh"))
+}
+
+// TestWriteFilesConflict tests that attachments written to the same file are reported.
+func TestWriteFilesConflict(t *testing.T) {
+	t.Cleanup(CleanupFiles)
+	client := newAttachmentsClient(map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	options := FileOptions{DestinationDir: t.TempDir(), Mode: defaultFileMode, Files: []FileDestination{{Name: "*.txt", DestinationName: "same.txt"}}}
+	_, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: options})
+	require.ErrorIs(t, err, ErrFileConflict)
+}
+
+// TestDownloadFiles tests that attachment content is only downloaded when asked for, and only for the attachments selected.
+func TestDownloadFiles(t *testing.T) {
+	client := newAttachmentsClient(map[string]string{
+		"setup.msi":  "a large installer",
+		"readme.txt": "readme",
+		"notes.txt":  "notes",
+	})
+
+	content := func(options FileOptions) map[string]string {
+		file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: options})
+		require.NoError(t, err)
+
+		contents := map[string]string{}
+		for _, ref := range file.FileRefs {
+			// Metadata is always listed.
+			assert.NotEmpty(t, ref.Size, ref.Name)
+			if ref.Base64Data != "" {
+				data, err := base64.StdEncoding.DecodeString(ref.Base64Data)
+				require.NoError(t, err)
+				contents[ref.Name] = string(data)
+			}
+		}
+		assert.Len(t, file.FileRefs, 3)
+		return contents
+	}
+
+	assert.Empty(t, content(FileOptions{}))
+	assert.Equal(t, map[string]string{"setup.msi": "a large installer", "readme.txt": "readme", "notes.txt": "notes"}, content(FileOptions{Download: true}))
+	assert.Equal(t, map[string]string{"readme.txt": "readme"}, content(FileOptions{Names: []string{"readme.txt"}}))
+	assert.Equal(t, map[string]string{"readme.txt": "readme", "setup.msi": "a large installer"}, content(FileOptions{Names: []string{"setup.msi"}, Glob: "read*"}))
+	assert.Equal(t, map[string]string{"readme.txt": "readme", "notes.txt": "notes"}, content(FileOptions{MaxSize: 6}))
+	assert.Equal(t, map[string]string{"notes.txt": "notes"}, content(FileOptions{Glob: "*.txt", MaxSize: 5}))
+
+	// Attachments Keeper fails to return are reported instead of being left empty.
+	record := recordFromJSON(`{"uid": "file-uid", "type": "file", "fields": []}`)
+	record.Files = []*ksm.KeeperFile{{Uid: "iso-uid", Name: "image.iso", Size: 500}}
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(record, nil)
+
+	_, err := NewClient(mockClient).GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Download: true}})
+	require.ErrorIs(t, err, ErrFileDownload)
+}
+
+// TestDownloadFilesTransport tests that attachments are downloaded through the transport of the client config,
+// and that download failures are reported with their cause.
+func TestDownloadFilesTransport(t *testing.T) {
+	recordKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	fileKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	encryptedKey, err := ksm.EncryptAesGcm(fileKey, recordKey)
+	require.NoError(t, err)
+	content, err := ksm.EncryptAesGcm([]byte("installer"), fileKey)
+	require.NoError(t, err)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/setup.msi":
+			w.Write(content)
+		case "/corrupted.msi":
+			w.Write([]byte("not encrypted with the file key"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certPEM, 0600))
+
+	record := recordFromJSON(`{"uid": "file-uid", "type": "file", "fields": []}`)
+	for _, name := range []string{"setup.msi", "corrupted.msi", "missing.msi"} {
+		record.Files = append(record.Files, &ksm.KeeperFile{
+			Uid:  name + "-uid",
+			Name: name,
+			Size: len("installer"),
+			F: map[string]interface{}{
+				"url":     server.URL + "/" + name,
+				"fileKey": ksm.BytesToBase64(encryptedKey),
+			},
+			RecordKeyBytes: recordKey,
+		})
+	}
+
+	registry := NewClientRegistry(func(options *ksm.ClientOptions, transport http.RoundTripper) (KeeperClient, error) {
+		mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+		mockClient.On("GetSecret").Return(record, nil)
+		return mockClient, nil
+	})
+	client, err := registry.Get(ClientConfig{ConfigJSON: generateTestConfig(t), CABundleFile: caBundle, MaxRetries: -1})
+	require.NoError(t, err)
+
+	file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Names: []string{"setup.msi"}}})
+	require.NoError(t, err)
+	for _, ref := range file.FileRefs {
+		if ref.Name == "setup.msi" {
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("installer")), ref.Base64Data)
+		}
+	}
+
+	_, err = client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Names: []string{"missing.msi"}}})
+	require.ErrorIs(t, err, ErrFileDownload)
+	assert.ErrorContains(t, err, "404")
+
+	_, err = client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Names: []string{"corrupted.msi"}}})
+	require.ErrorIs(t, err, ErrFileDownload)
+	assert.ErrorContains(t, err, "decrypt")
+
+	// Without the CA bundle the server isn't trusted.
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(record, nil)
+	_, err = NewClient(mockClient).GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Names: []string{"setup.msi"}}})
+	require.ErrorIs(t, err, ErrFileDownload)
+	assert.ErrorContains(t, err, "certificate")
+	assert.NotContains(t, err.Error(), server.URL)
+}
+
+// TestDownloadFilesToDisk tests that downloads written to destination_dir leave no temporary file behind, and are
+// stopped once larger than max_file_size whatever size Keeper listed.
+func TestDownloadFilesToDisk(t *testing.T) {
+	t.Cleanup(CleanupFiles)
+	recordKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	fileKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	encryptedKey, err := ksm.EncryptAesGcm(fileKey, recordKey)
+	require.NoError(t, err)
+	content, err := ksm.EncryptAesGcm([]byte(strings.Repeat("installer", 100)), fileKey)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	// Keeper lists a size below max_file_size, the content downloaded is larger.
+	record := recordFromJSON(`{"uid": "file-uid", "type": "file", "fields": []}`)
+	record.Files = append(record.Files, &ksm.KeeperFile{
+		Uid:  "setup-uid",
+		Name: "setup.msi",
+		Size: 10,
+		F: map[string]interface{}{
+			"url":     server.URL + "/setup.msi",
+			"fileKey": ksm.BytesToBase64(encryptedKey),
+		},
+		RecordKeyBytes: recordKey,
+	})
+
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(record, nil)
+	client := NewClient(mockClient)
+
+	dir := t.TempDir()
+	file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: dir, Mode: defaultFileMode}})
+	require.NoError(t, err)
+
+	written, err := os.ReadFile(file.FilePaths["setup.msi"])
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("installer", 100), string(written))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: t.TempDir(), Mode: defaultFileMode, MaxSize: 100}})
+	require.ErrorIs(t, err, ErrFileDownload)
+	assert.ErrorContains(t, err, "larger than max_file_size")
+}
+
+// TestVerifyFiles tests that downloaded attachments list their digests and fail the datasource when they don't match
+// their expected_sha256 or size.
+func TestVerifyFiles(t *testing.T) {
+	client := newAttachmentsClient(map[string]string{"setup.msi": "installer", "readme.txt": "readme"})
+	sha256Sum := sha256.Sum256([]byte("installer"))
+	sha512Sum := sha512.Sum512([]byte("installer"))
+	installerSHA256 := hex.EncodeToString(sha256Sum[:])
+
+	// Attachments with an expected digest are downloaded without download_files.
+	file, err := client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{ExpectedSHA256: map[string]string{"setup.msi": strings.ToUpper(installerSHA256)}, VerifySize: true}})
+	require.NoError(t, err)
+	for _, ref := range file.FileRefs {
+		if ref.Name == "setup.msi" {
+			assert.Equal(t, installerSHA256, ref.SHA256)
+			assert.Equal(t, hex.EncodeToString(sha512Sum[:]), ref.SHA512)
+			assert.NotEmpty(t, ref.Base64Data)
+		} else {
+			assert.Empty(t, ref.SHA256)
+			assert.Empty(t, ref.Base64Data)
+		}
+	}
+
+	// The digest is checked before the attachment is written to disk.
+	dir := t.TempDir()
+	_, err = client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: dir, Mode: defaultFileMode, ExpectedSHA256: map[string]string{"setup.msi": strings.Repeat("0", 64)}}})
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.NoFileExists(t, filepath.Join(dir, "setup.msi"))
+
+	_, err = client.GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{ExpectedSHA256: map[string]string{"missing.msi": installerSHA256}}})
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	record := recordFromJSON(`{"uid": "file-uid", "type": "file", "fields": []}`)
+	record.Files = []*ksm.KeeperFile{{Uid: "cert-uid", Name: "ca.pem", Size: 100, FileData: []byte("truncated")}}
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(record, nil)
+
+	_, err = NewClient(mockClient).GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{Download: true, VerifySize: true}})
+	require.ErrorIs(t, err, ErrSizeMismatch)
+
+	// Integrity failures are classified so builds can tell them apart from Keeper failures.
+	kind, _ := classifyError(err, "")
+	assert.Equal(t, ErrIntegrity, kind)
+
+	// The size is checked before the attachment is written to disk.
+	sizeDir := t.TempDir()
+	_, err = NewClient(mockClient).GetFile(RecordQuery{Uid: "file-uid", Files: FileOptions{DestinationDir: sizeDir, Mode: defaultFileMode, VerifySize: true, Keep: true}})
+	require.ErrorIs(t, err, ErrSizeMismatch)
+	entries, err := os.ReadDir(sizeDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestValidateFileOptions tests that invalid attachment settings are rejected when the datasource is configured.
+func TestValidateFileOptions(t *testing.T) {
+	uid := "file-uid"
+	tests := map[string]struct {
+		config Config
+		err    error
+	}{
+		"mode without dir":       {Config{FileMode: "0600"}, ErrFileOptionsWithoutDir},
+		"keep without dir":       {Config{Keep: true}, ErrFileOptionsWithoutDir},
+		"invalid mode":           {Config{DestinationDir: "files", FileMode: "rwx"}, ErrInvalidFileMode},
+		"mode out of range":      {Config{DestinationDir: "files", FileMode: "1777"}, ErrInvalidFileMode},
+		"invalid glob":           {Config{FileGlob: "[a-"}, ErrInvalidFileGlob},
+		"empty file name":        {Config{DestinationDir: "files", Files: []FileDestination{{}}}, ErrInvalidFileGlob},
+		"invalid file mode":      {Config{DestinationDir: "files", Files: []FileDestination{{Name: "a", Mode: "9"}}}, ErrInvalidFileMode},
+		"destination with dirs":  {Config{DestinationDir: "files", Files: []FileDestination{{Name: "a", DestinationName: "../a"}}}, ErrInvalidDestinationName},
+		"destination parent dir": {Config{DestinationDir: "files", Files: []FileDestination{{Name: "a", DestinationName: ".."}}}, ErrInvalidDestinationName},
+		"negative max file size": {Config{MaxFileSize: -1}, ErrInvalidMaxFileSize},
+		"invalid checksum":       {Config{ExpectedSHA256: map[string]string{"a": "abc"}}, ErrInvalidChecksum},
+	}
+
+	for name, test := range tests {
+		test.config.Uid = &uid
+		assert.ErrorIs(t, ValidateDataSourceConfig(test.config), test.err, name)
+	}
+
+	config := Config{Uid: &uid, DestinationDir: "files", FileMode: "0644", FileGlob: "*.msi", Files: []FileDestination{{Name: "a", Mode: "755"}}}
+	require.NoError(t, ValidateDataSourceConfig(config))
+	assert.Equal(t, os.FileMode(0644), config.RecordQuery().Files.Mode)
+}
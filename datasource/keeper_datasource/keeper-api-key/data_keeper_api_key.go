@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-api-key"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,20 +52,23 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the API key from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the API key using the UID from the config
-	apiKey, err := keeperClient.GetAPIKey(*d.Config.Uid)
+	// Fetch the API key using the uid or title from the config
+	apiKey, err := keeperClient.GetAPIKey(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the secret filter to mask the API key and secret
 	packersdk.LogSecretFilter.Set(apiKey.ClientSecret)
+	packersdk.LogSecretFilter.Set(apiKey.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperAPIKey: *apiKey,
 	}
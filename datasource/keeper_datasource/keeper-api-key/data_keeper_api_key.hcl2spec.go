@@ -16,6 +16,8 @@ type FlatDatasourceOutput struct {
 	Title        *string                         `mapstructure:"title" cty:"title" hcl:"title"`
 	Notes        *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
 	FileRefs     []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string               `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields keeper_datasource.CustomFields  `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
 	AppId        *string                         `mapstructure:"app_id" cty:"app_id" hcl:"app_id"`
 	ClientSecret *string                         `mapstructure:"client_secret" cty:"client_secret" hcl:"client_secret"`
 }
@@ -37,6 +39,8 @@ func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&keeper_datasource.CustomFields{}).HCL2Spec(),
 		"app_id":        &hcldec.AttrSpec{Name: "app_id", Type: cty.String, Required: false},
 		"client_secret": &hcldec.AttrSpec{Name: "client_secret", Type: cty.String, Required: false},
 	}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DatasourceOutput
+package keeper_certificate
+
+import (
+	"errors"
+	"path"
+
+	keeper "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-certificate"
+
+var (
+	ErrInvalidMinDaysValid    = errors.New("min_days_valid can't be negative")
+	ErrInvalidCertificateFile = errors.New("certificate_file must be a file name or a valid glob")
+)
+
+type Datasource struct {
+	Config Config
+}
+
+type Config struct {
+	keeper.Config `mapstructure:",squash"`
+	// CertificateFile is the name, or a glob, of the attachment holding the certificate (ex: `*.pfx`). Defaults to
+	// the first attachment with a `.pem`, `.crt`, `.cer`, `.der`, `.pfx` or `.p12` extension.
+	CertificateFile string `mapstructure:"certificate_file"`
+	// MinDaysValid fails the build when the certificate expires within this many days. Expired certificates
+	// always fail the build.
+	MinDaysValid int `mapstructure:"min_days_valid"`
+}
+
+type DatasourceOutput struct {
+	keeper.KeeperCertificate `mapstructure:",squash"`
+}
+
+// ConfigSpec converts the config struct to a spec for HCL2
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.Config.FlatMapstructure().HCL2Spec()
+}
+
+// Configure decodes the raw configuration into the Datasource struct
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.Config, nil, raws...)
+	if err != nil {
+		return err
+	}
+
+	if d.Config.MinDaysValid < 0 {
+		return ErrInvalidMinDaysValid
+	}
+
+	if _, err := path.Match(d.Config.CertificateFile, ""); err != nil {
+		return ErrInvalidCertificateFile
+	}
+
+	// Validate all required fields are set and valid
+	return keeper.ValidateDataSourceConfig(d.Config.Config)
+}
+
+// OutputSpec converts the output struct to a spec for HCL2
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+// Execute fetches the certificate from Keeper and returns it as a cty.Value
+func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
+	// Get the Keeper client
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
+	}
+
+	// Fetch the certificate attached to the record with the uid or title from the config
+	cert, err := keeperClient.GetCertificate(query, keeper.CertificateOptions{
+		File:         d.Config.CertificateFile,
+		MinDaysValid: d.Config.MinDaysValid,
+	})
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
+	}
+
+	// Set the secret filter for the private key
+	packersdk.LogSecretFilter.Set(cert.PrivateKey)
+	packersdk.LogSecretFilter.Set(cert.CustomSecretValues()...)
+	output := &DatasourceOutput{
+		KeeperCertificate: *cert,
+	}
+
+	return hcl2helper.HCL2ValueFromConfig(output, d.OutputSpec()), nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keeper_certificate
+
+import (
+	_ "embed"
+	"os/exec"
+	"testing"
+
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/template.pkr.hcl
+var testDatasourceHCL2Basic string
+
+// Run with: PACKER_ACC=1 go test -count 1 -v ./datasource/keeper_datasource/keeper-certificate/data_keeper_certificate_acc_test.go  -timeout=120m
+// TestAccKeeperCertificate is an integration test that pulls a real secret from Keeper and checks the output. Don't use real secrets in this test.
+func TestAccKeeperCertificate(t *testing.T) {
+	testCase := &acctest.PluginTestCase{
+		Name: "keeper_certificate_basic_test",
+		Setup: func() error {
+			return nil
+		},
+		Teardown: func() error {
+			return nil
+		},
+		Template: testDatasourceHCL2Basic,
+		Type:     "keeper-certificate",
+		Check: func(buildCommand *exec.Cmd, logfile string) error {
+			logLines := []string{
+				"null.basic-example: Title: test-certificate",
+				"null.basic-example: File Name: test.pfx",
+				"null.basic-example: Subject: CN=test.example.com",
+				"null.basic-example: SANs: test.example.com",
+			}
+
+			if err := keeper_datasource.RunPackerAcceptanceTest(t, buildCommand, logfile, logLines); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	acctest.TestPlugin(t, testCase)
+}
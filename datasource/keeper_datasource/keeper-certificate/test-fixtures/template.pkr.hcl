@@ -0,0 +1,26 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-certificate" "test" {
+  # Login record with a PKCS#12 attachment, the password field decrypts it
+  uid = "Xq2BvN8cTmW4kLpR7dYh0g"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Title: ${data.keeper-certificate.test.title}",
+      "echo File Name: ${data.keeper-certificate.test.file_name}",
+      "echo Subject: ${data.keeper-certificate.test.subject}",
+      "echo SANs: ${join(",", data.keeper-certificate.test.sans)}"
+    ]
+  }
+}
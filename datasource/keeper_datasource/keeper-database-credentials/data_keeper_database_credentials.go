@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-database-credential"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,20 +52,23 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the database credentials from Keeper and returns them as a cty.Value.
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the database credentials using the UID from the config
-	creds, err := keeperClient.GetDatabaseCredentials(*d.Config.Uid)
+	// Fetch the database credentials using the uid or title from the config
+	creds, err := keeperClient.GetDatabaseCredentials(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the credentials in the log secret filter to avoid logging sensitive information
 	packersdk.LogSecretFilter.Set(creds.Login, creds.Password)
+	packersdk.LogSecretFilter.Set(creds.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperDataBaseCredentials: *creds,
 	}
@@ -11,15 +11,18 @@ import (
 // FlatDatasourceOutput is an auto-generated flat version of DatasourceOutput.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatDatasourceOutput struct {
-	Uid            *string                               `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type           *string                               `mapstructure:"type" cty:"type" hcl:"type"`
-	Title          *string                               `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes          *string                               `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs       []keeper_datasource.FlatFileRef       `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	HostConnection *keeper_datasource.FlatHostConnection `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
-	Login          *string                               `mapstructure:"login" cty:"login" hcl:"login"`
-	Password       *string                               `mapstructure:"password" cty:"password" hcl:"password"`
-	DbType         *string                               `mapstructure:"db_type" cty:"db_type" hcl:"db_type"`
+	Uid            *string                                `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type           *string                                `mapstructure:"type" cty:"type" hcl:"type"`
+	Title          *string                                `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes          *string                                `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs       []keeper_datasource.FlatFileRef        `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string                      `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   keeper_datasource.CustomFields         `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection *keeper_datasource.FlatHostConnection  `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	Hosts          []keeper_datasource.FlatHostConnection `mapstructure:"hosts" cty:"hosts" hcl:"hosts"`
+	Login          *string                                `mapstructure:"login" cty:"login" hcl:"login"`
+	Password       *string                                `mapstructure:"password" cty:"password" hcl:"password"`
+	DbType         *string                                `mapstructure:"db_type" cty:"db_type" hcl:"db_type"`
 }
 
 // FlatMapstructure returns a new FlatDatasourceOutput.
@@ -39,7 +42,10 @@ func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&keeper_datasource.CustomFields{}).HCL2Spec(),
 		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatHostConnection)(nil).HCL2Spec())},
+		"hosts":              &hcldec.BlockListSpec{TypeName: "hosts", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatHostConnection)(nil).HCL2Spec())},
 		"login":              &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
 		"password":           &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
 		"db_type":            &hcldec.AttrSpec{Name: "db_type", Type: cty.String, Required: false},
@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-encrypted-note"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,20 +52,23 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the encrypted note from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the encrypted note using the UID from the config
-	note, err := keeperClient.GetEncryptedNote(*d.Config.Uid)
+	// Fetch the encrypted note using the uid or title from the config
+	note, err := keeperClient.GetEncryptedNote(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the log secret filter to mask sensitive information
 	packersdk.LogSecretFilter.Set(note.Note)
+	packersdk.LogSecretFilter.Set(note.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperEncryptedNote: *note,
 	}
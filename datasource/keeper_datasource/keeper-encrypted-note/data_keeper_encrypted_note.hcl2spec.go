@@ -11,13 +11,15 @@ import (
 // FlatDatasourceOutput is an auto-generated flat version of DatasourceOutput.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatDatasourceOutput struct {
-	Uid      *string                         `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string                         `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string                         `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	Note     *string                         `mapstructure:"note" cty:"note" hcl:"note"`
-	Date     *string                         `mapstructure:"date" cty:"date" hcl:"date"`
+	Uid          *string                         `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type         *string                         `mapstructure:"type" cty:"type" hcl:"type"`
+	Title        *string                         `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes        *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs     []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string               `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields keeper_datasource.CustomFields  `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Note         *string                         `mapstructure:"note" cty:"note" hcl:"note"`
+	Date         *string                         `mapstructure:"date" cty:"date" hcl:"date"`
 }
 
 // FlatMapstructure returns a new FlatDatasourceOutput.
@@ -32,13 +34,15 @@ func (*DatasourceOutput) FlatMapstructure() interface{ HCL2Spec() map[string]hcl
 // The decoded values from this spec will then be applied to a FlatDatasourceOutput.
 func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
-		"note":      &hcldec.AttrSpec{Name: "note", Type: cty.String, Required: false},
-		"date":      &hcldec.AttrSpec{Name: "date", Type: cty.String, Required: false},
+		"uid":           &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":          &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&keeper_datasource.CustomFields{}).HCL2Spec(),
+		"note":          &hcldec.AttrSpec{Name: "note", Type: cty.String, Required: false},
+		"date":          &hcldec.AttrSpec{Name: "date", Type: cty.String, Required: false},
 	}
 	return s
 }
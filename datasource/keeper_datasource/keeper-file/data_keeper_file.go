@@ -9,10 +9,14 @@ import (
 	keeper "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-file"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -33,6 +37,12 @@ func (d *Datasource) Configure(raws ...interface{}) error {
 		return err
 	}
 
+	// File records are read for their attachments, download them unless download_files = false
+	if d.Config.DownloadFiles == nil {
+		download := true
+		d.Config.DownloadFiles = &download
+	}
+
 	// Validate all required fields are set and valid
 	if err := keeper_datasource.ValidateDataSourceConfig(d.Config); err != nil {
 		return err
@@ -48,18 +58,22 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the file from Keeper and returns it as a cty.Value.
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the file using the UID from the config
-	file, err := keeperClient.GetFile(*d.Config.Uid)
+	// Fetch the file using the uid or title from the config
+	file, err := keeperClient.GetFile(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
+	// Set the secret filter for custom fields holding sensitive values
+	packersdk.LogSecretFilter.Set(file.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperFile: *file,
 	}
@@ -0,0 +1,21 @@
+package keeper_file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigureDownloadsByDefault tests that keeper-file downloads attachments unless download_files = false.
+func TestConfigureDownloadsByDefault(t *testing.T) {
+	d := &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{"uid": "file-uid"}))
+	require.NotNil(t, d.Config.DownloadFiles)
+	assert.True(t, *d.Config.DownloadFiles)
+
+	d = &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{"uid": "file-uid", "download_files": false}))
+	require.NotNil(t, d.Config.DownloadFiles)
+	assert.False(t, *d.Config.DownloadFiles)
+}
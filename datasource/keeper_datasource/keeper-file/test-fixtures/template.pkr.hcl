@@ -3,7 +3,8 @@
 
 data "keeper-file" "test" {
   # Single file record
-  uid = "OIPg0wxSeC9wiuvTZH0N-w"
+  uid            = "OIPg0wxSeC9wiuvTZH0N-w"
+  download_files = true
 }
 
 source "null" "basic-example" {
@@ -15,6 +15,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-login"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -50,20 +53,23 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the login from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the login using the UID from the config
-	login, err := keeperClient.GetLogin(*d.Config.Uid)
+	// Fetch the login using the uid or title from the config
+	login, err := keeperClient.GetLogin(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the secret filter for the login
-	packersdk.LogSecretFilter.Set(login.Login, login.Password)
+	packersdk.LogSecretFilter.Set(login.Login, login.Password, login.TOTP.Secret)
+	packersdk.LogSecretFilter.Set(login.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperLogin: *login,
 	}
@@ -11,14 +11,20 @@ import (
 // FlatDatasourceOutput is an auto-generated flat version of DatasourceOutput.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatDatasourceOutput struct {
-	Uid      *string                         `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string                         `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string                         `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	Login    *string                         `mapstructure:"login" cty:"login" hcl:"login"`
-	Password *string                         `mapstructure:"password" cty:"password" hcl:"password"`
-	Url      *string                         `mapstructure:"url" cty:"url" hcl:"url"`
+	Uid                  *string                         `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type                 *string                         `mapstructure:"type" cty:"type" hcl:"type"`
+	Title                *string                         `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes                *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs             []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths            map[string]string               `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields         keeper_datasource.CustomFields  `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Login                *string                         `mapstructure:"login" cty:"login" hcl:"login"`
+	Password             *string                         `mapstructure:"password" cty:"password" hcl:"password"`
+	Url                  *string                         `mapstructure:"url" cty:"url" hcl:"url"`
+	Urls                 []string                        `mapstructure:"urls" cty:"urls" hcl:"urls"`
+	TOTP                 *keeper_datasource.FlatTOTP     `mapstructure:"totp" cty:"totp" hcl:"totp"`
+	TOTPCode             *string                         `mapstructure:"totp_code" cty:"totp_code" hcl:"totp_code"`
+	TOTPSecondsRemaining *int                            `mapstructure:"totp_seconds_remaining" cty:"totp_seconds_remaining" hcl:"totp_seconds_remaining"`
 }
 
 // FlatMapstructure returns a new FlatDatasourceOutput.
@@ -33,14 +39,20 @@ func (*DatasourceOutput) FlatMapstructure() interface{ HCL2Spec() map[string]hcl
 // The decoded values from this spec will then be applied to a FlatDatasourceOutput.
 func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
-		"login":     &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
-		"password":  &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
-		"url":       &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"uid":                    &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":                   &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":                  &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":                  &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":              &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":             &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":          (&keeper_datasource.CustomFields{}).HCL2Spec(),
+		"login":                  &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
+		"password":               &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
+		"url":                    &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"urls":                   &hcldec.AttrSpec{Name: "urls", Type: cty.List(cty.String), Required: false},
+		"totp":                   &hcldec.BlockSpec{TypeName: "totp", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatTOTP)(nil).HCL2Spec())},
+		"totp_code":              &hcldec.AttrSpec{Name: "totp_code", Type: cty.String, Required: false},
+		"totp_seconds_remaining": &hcldec.AttrSpec{Name: "totp_seconds_remaining", Type: cty.Number, Required: false},
 	}
 	return s
 }
@@ -0,0 +1,96 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DatasourceOutput
+package keeper_notation
+
+import (
+	"errors"
+
+	keeper "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-notation"
+
+var ErrNotationsRequired = errors.New("notations is a required field")
+
+type Datasource struct {
+	Config Config
+}
+
+type Config struct {
+	keeper.ClientConfig `mapstructure:",squash"`
+	// Notations maps names to Keeper notations (ex: `keeper://UID/field/password`). Each notation
+	// references a single value of a record by uid, see the
+	// [Keeper notation documentation](https://docs.keeper.io/en/keeperpam/secrets-manager/about/keeper-notation).
+	Notations map[string]string `mapstructure:"notations" required:"true"`
+}
+
+type DatasourceOutput struct {
+	// Values maps the names in `notations` to the resolved values. Values that aren't strings,
+	// or fields with more than one value when no index is given, are JSON encoded.
+	// File contents are returned as text, or base64 encoded if the file isn't valid UTF-8.
+	Values map[string]string `mapstructure:"values"`
+}
+
+// ConfigSpec converts the config struct to a spec for HCL2
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.Config.FlatMapstructure().HCL2Spec()
+}
+
+// Configure decodes the raw configuration into the Datasource struct
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.Config, nil, raws...)
+	if err != nil {
+		return err
+	}
+
+	if len(d.Config.Notations) == 0 {
+		return ErrNotationsRequired
+	}
+
+	// Catch malformed notations before fetching any record
+	for _, notation := range d.Config.Notations {
+		if _, err := keeper.ParseNotation(notation); err != nil {
+			return err
+		}
+	}
+
+	return keeper.ValidateClientConfig(d.Config.ClientConfig)
+}
+
+// OutputSpec converts the output struct to a spec for HCL2
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+// Execute resolves the notations from Keeper and returns them as a cty.Value
+func (d *Datasource) Execute() (cty.Value, error) {
+	// Get the Keeper client
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, keeper.RecordQuery{}, err)
+	}
+
+	// Resolve every notation, fetching each record once
+	notations, err := keeperClient.GetNotations(d.Config.Notations)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, keeper.RecordQuery{}, err)
+	}
+
+	// Set the secret filter for every resolved value
+	packersdk.LogSecretFilter.Set(notations.SecretValues()...)
+
+	output := &DatasourceOutput{
+		Values: notations.Values,
+	}
+
+	return hcl2helper.HCL2ValueFromConfig(output, d.OutputSpec()), nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keeper_notation
+
+import (
+	_ "embed"
+	"os/exec"
+	"testing"
+
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/template.pkr.hcl
+var testDatasourceHCL2Basic string
+
+// Run with: PACKER_ACC=1 go test -count 1 -v ./datasource/scaffolding/data_acc_test.go  -timeout=120m
+// TestAccKeeperNotation is an integration test that resolves Keeper notations from a real Keeper account and checks the output. Don't use real secrets in this test.
+func TestAccKeeperNotation(t *testing.T) {
+	testCase := &acctest.PluginTestCase{
+		Name: "keeper_notation_basic_test",
+		Setup: func() error {
+			return nil
+		},
+		Teardown: func() error {
+			return nil
+		},
+		Template: testDatasourceHCL2Basic,
+		Type:     "keeper-notation",
+		Check: func(buildCommand *exec.Cmd, logfile string) error {
+			logLines := []string{
+				"null.basic-example: Login: test@selinc.com",
+				"null.basic-example: Password: testing123",
+			}
+
+			if err := keeper_datasource.RunPackerAcceptanceTest(t, buildCommand, logfile, logLines); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	acctest.TestPlugin(t, testCase)
+}
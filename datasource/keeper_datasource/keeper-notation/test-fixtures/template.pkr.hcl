@@ -0,0 +1,29 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-notation" "test" {
+  notations = {
+    # Test login record
+    login    = "keeper://A6En9kNc6HppPWDOi3MH9g/field/login"
+    password = "keeper://A6En9kNc6HppPWDOi3MH9g/field/password"
+    title    = "keeper://A6En9kNc6HppPWDOi3MH9g/title"
+  }
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Login: ${data.keeper-notation.test.values.login}",
+      "echo Password: ${data.keeper-notation.test.values.password}",
+      "echo Title: ${data.keeper-notation.test.values.title}",
+    ]
+  }
+}
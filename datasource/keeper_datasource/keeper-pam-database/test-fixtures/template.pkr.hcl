@@ -0,0 +1,27 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-pam-database" "test" {
+  # Single PAM database record
+  uid = "PomuiS8aaB20UoHxKZ7hRg"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Title: ${data.keeper-pam-database.test.title}",
+      "echo Host: ${data.keeper-pam-database.test.connection_details.host_name}",
+      "echo Port: ${data.keeper-pam-database.test.connection_details.port}",
+      "echo DatabaseType: ${data.keeper-pam-database.test.database_type}",
+      "echo ConnectDatabase: ${data.keeper-pam-database.test.connect_database}"
+    ]
+  }
+}
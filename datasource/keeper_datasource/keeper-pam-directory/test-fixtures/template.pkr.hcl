@@ -0,0 +1,27 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-pam-directory" "test" {
+  # Single PAM directory record
+  uid = "PomuiS8aaB20UoHxKZ7hRg"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Title: ${data.keeper-pam-directory.test.title}",
+      "echo Host: ${data.keeper-pam-directory.test.connection_details.host_name}",
+      "echo Port: ${data.keeper-pam-directory.test.connection_details.port}",
+      "echo DirectoryType: ${data.keeper-pam-directory.test.directory_type}",
+      "echo DomainName: ${data.keeper-pam-directory.test.domain_name}"
+    ]
+  }
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keeper_pam_machine
+
+import (
+	_ "embed"
+	"os/exec"
+	"testing"
+
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	"github.com/hashicorp/packer-plugin-sdk/acctest"
+)
+
+//go:embed test-fixtures/template.pkr.hcl
+var testDatasourceHCL2Basic string
+
+// Run with: PACKER_ACC=1 go test -count 1 -v ./datasource/keeper_datasource/keeper-pam-machine/data_keeper_pam_machine_acc_test.go  -timeout=120m
+// TestAccKeeperPamMachine is an integration test that pulls a real secret from Keeper and checks the output. Don't use real secrets in this test.
+func TestAccKeeperPamMachine(t *testing.T) {
+	testCase := &acctest.PluginTestCase{
+		Name: "keeper_pam_machine_basic_test",
+		Setup: func() error {
+			return nil
+		},
+		Teardown: func() error {
+			return nil
+		},
+		Template: testDatasourceHCL2Basic,
+		Type:     "keeper-pam-machine",
+		Check: func(buildCommand *exec.Cmd, logfile string) error {
+			logLines := []string{
+				"null.basic-example: Title: test-pam-machine",
+				"null.basic-example: Host: 127.0.0.1",
+				"null.basic-example: Port: 22",
+				"null.basic-example: OperatingSystem: linux",
+				"null.basic-example: User: test-user",
+			}
+
+			if err := keeper_datasource.RunPackerAcceptanceTest(t, buildCommand, logfile, logLines); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	acctest.TestPlugin(t, testCase)
+}
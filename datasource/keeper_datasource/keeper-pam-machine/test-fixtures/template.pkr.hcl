@@ -0,0 +1,27 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-pam-machine" "test" {
+  # Single PAM machine record
+  uid = "PomuiS8aaB20UoHxKZ7hRg"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Title: ${data.keeper-pam-machine.test.title}",
+      "echo Host: ${data.keeper-pam-machine.test.connection_details.host_name}",
+      "echo Port: ${data.keeper-pam-machine.test.connection_details.port}",
+      "echo OperatingSystem: ${data.keeper-pam-machine.test.operating_system}",
+      "echo User: ${data.keeper-pam-machine.test.users[0].login}"
+    ]
+  }
+}
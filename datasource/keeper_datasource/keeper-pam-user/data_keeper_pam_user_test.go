@@ -0,0 +1,58 @@
+package keeper_pam_user
+
+import (
+	"net/http"
+	"testing"
+
+	keeper "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig is a KSM config the mocked client registry accepts, it is never sent to Keeper.
+const testConfig = `{"appKey": "app-key", "clientId": "client-id", "privateKey": "private-key"}`
+
+// pamUserRecord is a PAM user whose credentials must not show up in the logs.
+const pamUserRecord = `{
+	"uid": "user-uid",
+	"title": "admin",
+	"type": "pamUser",
+	"fields": [
+		{"type": "login", "value": ["svc-builder"]},
+		{"type": "password", "value": ["builder-password"]},
+		{"type": "secret", "label": "privatePEMKey", "value": ["builder-private-key"]}
+	],
+	"custom": []
+}`
+
+// TestExecuteMasksCredentials tests that the login, password and private key of the user are masked in the logs.
+func TestExecuteMasksCredentials(t *testing.T) {
+	registry := keeper.DefaultRegistry
+	t.Cleanup(func() { keeper.DefaultRegistry = registry })
+
+	record := ksm.NewRecordFromJson(map[string]interface{}{}, nil, "")
+	record.RecordDict = ksm.JsonToDict(pamUserRecord)
+	record.Uid = "user-uid"
+
+	keeper.DefaultRegistry = keeper.NewClientRegistry(func(options *ksm.ClientOptions, transport http.RoundTripper) (keeper.KeeperClient, error) {
+		client := &keeper.MockKeeperClient{TestClient: &keeper.KSMClient{}}
+		client.On("GetSecret").Return(record, nil)
+		return client, nil
+	})
+
+	d := &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{
+		"uid":         "user-uid",
+		"config_json": testConfig,
+	}))
+
+	value, err := d.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "svc-builder", value.GetAttr("login").AsString())
+
+	for _, secret := range []string{"svc-builder", "builder-password", "builder-private-key"} {
+		assert.Equal(t, "<sensitive>", packersdk.LogSecretFilter.FilterString(secret))
+	}
+}
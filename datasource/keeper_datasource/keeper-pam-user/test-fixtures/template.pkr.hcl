@@ -0,0 +1,26 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-pam-user" "test" {
+  # Single PAM user record
+  uid = "PomuiS8aaB20UoHxKZ7hRg"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Title: ${data.keeper-pam-user.test.title}",
+      "echo Login: ${data.keeper-pam-user.test.login}",
+      "echo Password: ${data.keeper-pam-user.test.password}",
+      "echo Managed: ${data.keeper-pam-user.test.managed}"
+    ]
+  }
+}
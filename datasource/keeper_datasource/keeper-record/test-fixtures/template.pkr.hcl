@@ -0,0 +1,26 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+data "keeper-record" "test" {
+  # Test login record
+  uid = "A6En9kNc6HppPWDOi3MH9g"
+}
+
+source "null" "basic-example" {
+  communicator = "none"
+}
+
+build {
+  sources = [
+    "source.null.basic-example"
+  ]
+
+  provisioner "shell-local" {
+    inline = [
+      "echo Type: ${data.keeper-record.test.type}",
+      "echo Login: ${data.keeper-record.test.field_map["login"]}",
+      "echo Url: ${data.keeper-record.test.field_map["url"]}",
+      "echo Notes: ${data.keeper-record.test.notes}",
+    ]
+  }
+}
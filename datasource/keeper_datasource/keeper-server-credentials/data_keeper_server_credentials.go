@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-server-credential"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,19 +52,22 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the server credentials from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
-	keeperClient, err := keeper.GetSecretClient()
+	query := d.Config.RecordQuery()
+
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the server credentials using the UID from the config
-	creds, err := keeperClient.GetServerCredentials(*d.Config.Uid)
+	// Fetch the server credentials using the uid or title from the config
+	creds, err := keeperClient.GetServerCredentials(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the secret filter for the credentials
 	packersdk.LogSecretFilter.Set(creds.Login, creds.Password)
+	packersdk.LogSecretFilter.Set(creds.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperServerCredentials: *creds,
 	}
@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-software-license"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,20 +52,23 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the software license from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the software license using the UID from the config
-	license, err := keeperClient.GetSoftwareLicense(*d.Config.Uid)
+	// Fetch the software license using the uid or title from the config
+	license, err := keeperClient.GetSoftwareLicense(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
 	// Set the secret filter to mask the license number
 	packersdk.LogSecretFilter.Set(license.LicenseNumber)
+	packersdk.LogSecretFilter.Set(license.CustomSecretValues()...)
 	output := &DatasourceOutput{
 		KeeperSoftwareLicense: *license,
 	}
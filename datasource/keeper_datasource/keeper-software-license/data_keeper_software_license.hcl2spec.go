@@ -16,6 +16,8 @@ type FlatDatasourceOutput struct {
 	Title          *string                         `mapstructure:"title" cty:"title" hcl:"title"`
 	Notes          *string                         `mapstructure:"notes" cty:"notes" hcl:"notes"`
 	FileRefs       []keeper_datasource.FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string               `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   keeper_datasource.CustomFields  `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
 	LicenseNumber  *string                         `mapstructure:"license_number" cty:"license_number" hcl:"license_number"`
 	ActivationDate *string                         `mapstructure:"activation_date" cty:"activation_date" hcl:"activation_date"`
 	ExpirationDate *string                         `mapstructure:"expiration_date" cty:"expiration_date" hcl:"expiration_date"`
@@ -38,6 +40,8 @@ func (*FlatDatasourceOutput) HCL2Spec() map[string]hcldec.Spec {
 		"title":           &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":           &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":       &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*keeper_datasource.FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":      &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":   (&keeper_datasource.CustomFields{}).HCL2Spec(),
 		"license_number":  &hcldec.AttrSpec{Name: "license_number", Type: cty.String, Required: false},
 		"activation_date": &hcldec.AttrSpec{Name: "activation_date", Type: cty.String, Required: false},
 		"expiration_date": &hcldec.AttrSpec{Name: "expiration_date", Type: cty.String, Required: false},
@@ -14,6 +14,9 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// datasourceName is the name of the datasource in templates, errors are prefixed with it.
+const datasourceName = "keeper-ssh-key"
+
 type Datasource struct {
 	Config keeper_datasource.Config
 }
@@ -49,20 +52,27 @@ func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
 
 // Execute fetches the SSH key from Keeper and returns it as a cty.Value
 func (d *Datasource) Execute() (cty.Value, error) {
+	query := d.Config.RecordQuery()
+
 	// Get the Keeper client
-	keeperClient, err := keeper.GetSecretClient()
+	keeperClient, err := keeper.GetSecretClient(d.Config.ClientConfig)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeper.WrapError(datasourceName, query, err)
 	}
 
-	// Fetch the SSH key using the UID from the config
-	sshKey, err := keeperClient.GetSSHKey(*d.Config.Uid)
+	// Fetch the SSH key using the uid or title from the config
+	sshKey, err := keeperClient.GetSSHKey(query)
 	if err != nil {
-		return cty.NullVal(cty.EmptyObject), err
+		return cty.NullVal(cty.EmptyObject), keeperClient.WrapError(datasourceName, query, err)
 	}
 
-	// Set the secret filter for the SSH key
-	packersdk.LogSecretFilter.Set(sshKey.Passphrase, sshKey.KeyPair.PrivateKey)
+	// Set the secret filter for the SSH key, in every format it is converted to
+	packersdk.LogSecretFilter.Set(sshKey.Passphrase)
+	packersdk.LogSecretFilter.Set(sshKey.KeyPair.SecretValues()...)
+	packersdk.LogSecretFilter.Set(sshKey.CustomSecretValues()...)
+	for _, keyPair := range sshKey.KeyPairs {
+		packersdk.LogSecretFilter.Set(keyPair.SecretValues()...)
+	}
 	output := &DatasourceOutput{
 		KeeperSSHKey: *sshKey,
 	}
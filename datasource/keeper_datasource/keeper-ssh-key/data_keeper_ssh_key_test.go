@@ -0,0 +1,110 @@
+package keeper_ssh_key
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"testing"
+
+	keeper "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testConfig is a KSM config the mocked client registry accepts, it is never sent to Keeper.
+const testConfig = `{"appKey": "app-key", "clientId": "client-id", "privateKey": "private-key"}`
+
+// mockRegistry makes the datasources read the record from a mocked Keeper client.
+func mockRegistry(t *testing.T, record *ksm.Record) {
+	registry := keeper.DefaultRegistry
+	t.Cleanup(func() { keeper.DefaultRegistry = registry })
+
+	keeper.DefaultRegistry = keeper.NewClientRegistry(func(options *ksm.ClientOptions, transport http.RoundTripper) (keeper.KeeperClient, error) {
+		client := &keeper.MockKeeperClient{TestClient: &keeper.KSMClient{}}
+		client.On("GetSecret").Return(record, nil)
+		return client, nil
+	})
+}
+
+// newSSHKeyRecord returns an SSH key record with an unlabelled and a labelled key pair.
+func newSSHKeyRecord(t *testing.T, first string, rotated string) *ksm.Record {
+	content, err := json.Marshal(map[string]interface{}{
+		"uid":   "ssh-uid",
+		"title": "Deploy key",
+		"type":  "sshKeys",
+		"fields": []map[string]interface{}{
+			{"type": "keyPair", "value": []map[string]string{{"privateKey": first}}},
+		},
+		"custom": []map[string]interface{}{
+			{"type": "keyPair", "label": "rotated", "value": []map[string]string{{"privateKey": rotated}}},
+		},
+	})
+	require.NoError(t, err)
+
+	record := ksm.NewRecordFromJson(map[string]interface{}{}, nil, "")
+	record.RecordDict = ksm.JsonToDict(string(content))
+	record.Uid = "ssh-uid"
+	return record
+}
+
+// newPrivateKey returns a new ed25519 private key in OpenSSH format.
+func newPrivateKey(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
+// TestExecuteSelectsKeyPair tests that field_label selects the key pair of the outputs and every private key
+// is masked in the logs.
+func TestExecuteSelectsKeyPair(t *testing.T) {
+	first, rotated := newPrivateKey(t), newPrivateKey(t)
+	mockRegistry(t, newSSHKeyRecord(t, first, rotated))
+
+	d := &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{
+		"uid":         "ssh-uid",
+		"config_json": testConfig,
+		"field_label": "rotated",
+	}))
+
+	value, err := d.Execute()
+	require.NoError(t, err)
+
+	keyPair := value.GetAttr("key_pair")
+	assert.Equal(t, rotated, keyPair.GetAttr("private_key").AsString())
+	assert.Equal(t, "ssh-ed25519", keyPair.GetAttr("key_type").AsString())
+	assert.Equal(t, 2, value.GetAttr("key_pairs").LengthInt())
+
+	for _, key := range []string{first, rotated} {
+		assert.NotContains(t, packersdk.LogSecretFilter.FilterString(key), "PRIVATE KEY")
+	}
+}
+
+// TestExecuteOnlySelectedKeyPairFails tests that a key pair that can't be decrypted only fails the datasource
+// when it is the selected one.
+func TestExecuteOnlySelectedKeyPairFails(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("older passphrase"))
+	require.NoError(t, err)
+	encrypted := string(pem.EncodeToMemory(block))
+
+	mockRegistry(t, newSSHKeyRecord(t, newPrivateKey(t), encrypted))
+
+	d := &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{"uid": "ssh-uid", "config_json": testConfig}))
+	_, err = d.Execute()
+	require.NoError(t, err)
+
+	d = &Datasource{}
+	require.NoError(t, d.Configure(map[string]interface{}{"uid": "ssh-uid", "config_json": testConfig, "field_index": 1}))
+	_, err = d.Execute()
+	require.ErrorIs(t, err, keeper.ErrSSHKeyPassphrase)
+}
@@ -1,11 +1,13 @@
 package keeper_datasource
 
 import (
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/keeper-security/secrets-manager-go/core"
@@ -26,102 +28,117 @@ const (
 	SOFTWARE_LICENSE_FIELD_TYPE = "softwareLicense"
 	SSH_KEY_FIELD_TYPE          = "sshKeys"
 	PASSWORD_FIELD_TYPE         = "password"
+	PAM_MACHINE_FIELD_TYPE      = "pamMachine"
+	PAM_DATABASE_FIELD_TYPE     = "pamDatabase"
+	PAM_DIRECTORY_FIELD_TYPE    = "pamDirectory"
+	PAM_USER_FIELD_TYPE         = "pamUser"
 )
 
 // Errors for handling configuration and record type issues.
 var (
-	ErrNoConfig        = errors.New("no config file specified in environment variable " + KEEPER_CONFIG_ENV_KEY + " and no config file set at " + KEEPER_CONFIG_FILE_ENV_KEY + " please set one of them")
+	ErrNoConfig        = errors.New("no config set via config_file, config_base64, config_json, token or config_command, no config specified in environment variable " + KEEPER_CONFIG_ENV_KEY + ", no config file set at " + KEEPER_CONFIG_FILE_ENV_KEY + ", no config command set at " + KEEPER_CONFIG_COMMAND_ENV_KEY + " and no one-time token set at " + KEEPER_TOKEN_ENV_KEY + " please set one of them")
 	ErrWrongRecordType = errors.New("record is wrong type")
+	ErrClientInit      = errors.New("failed to initialize the Keeper Secrets Manager client, check the KSM config")
 )
 
 // KSMClient implemnents the KeeperClient interface and wraps the Keeper Secrets Manager client.
 type KSMClient struct {
 	KeeperClient *ksm.SecretsManager
+
+	// transport downloads attachments, the default HTTP transport is used when nil.
+	transport http.RoundTripper
+
+	// offline is the offline copy Keeper falls back to when it can't be reached, nil when the offline
+	// fallback is disabled.
+	offline *offlineCache
+
+	// appTitle is the title of the KSM application from the last Keeper response.
+	mu       sync.Mutex
+	appTitle string
+
+	// now returns the time one-time codes and certificate expiry are computed at, tests replace it.
+	// Defaults to time.Now.
+	now func() time.Time
 }
 
 // Interface for a Keeper client
 type KeeperClient interface {
 	GetSecret(uid string) (*ksm.Record, error)
-	GetServerCredentials(r *ksm.Record) (*KeeperServerCredentials, error)
-	GetDatabaseCredentials(r *ksm.Record) (*KeeperDataBaseCredentials, error)
+	GetSecrets(uids []string) ([]*ksm.Record, error)
+	GetSecretsByTitle(title string) ([]*ksm.Record, error)
+	GetServerCredentials(r *ksm.Record, field FieldSelector) (*KeeperServerCredentials, error)
+	GetDatabaseCredentials(r *ksm.Record, field FieldSelector) (*KeeperDataBaseCredentials, error)
 	// Renamed for consistency: GetApiKey -> GetAPIKey to match KeeperAPIKey return type
 	GetAPIKey(r *ksm.Record) (*KeeperAPIKey, error)
 	GetEncryptedNote(r *ksm.Record) (*KeeperEncryptedNote, error)
 	GetFile(r *ksm.Record) (*KeeperFile, error)
 	GetSoftwareLicense(r *ksm.Record) (*KeeperSoftwareLicense, error)
-	GetLogin(r *ksm.Record) (*KeeperLogin, error)
-	GetSSHKey(r *ksm.Record) (*KeeperSSHKey, error)
+	GetLogin(r *ksm.Record, field FieldSelector) (*KeeperLogin, error)
+	GetSSHKey(r *ksm.Record, field FieldSelector) (*KeeperSSHKey, error)
+	GetGenericRecord(r *ksm.Record) (*KeeperRecord, error)
+	GetPamMachine(r *ksm.Record) (*KeeperPamMachine, error)
+	GetPamDatabase(r *ksm.Record) (*KeeperPamDatabase, error)
+	GetPamDirectory(r *ksm.Record) (*KeeperPamDirectory, error)
+	GetPamUser(r *ksm.Record) (*KeeperPamUser, error)
+	GetCertificate(r *ksm.Record, options CertificateOptions) (*KeeperCertificate, error)
 }
 
 // Convert KSMClient to KeeperClient interface (compile time check)
 var _ KeeperClient = (*KSMClient)(nil)
 
-// Returns a new Keeper client instance
-func NewKeeperSecretClient() (*KSMClient, error) {
-	// Get the client options based on environment variables.
-	// Packer doesn't provide a way to pass the config via HCL2.
-	clientOptions, err := getClientOptions()
-	if err != nil {
-		return nil, err
+// newKSMClient creates a Keeper client from already resolved and validated client options.
+// When transport is set it is used for every request Keeper makes.
+func newKSMClient(clientOptions *ksm.ClientOptions, transport http.RoundTripper) (*KSMClient, error) {
+	// Create a new Keeper client with the provided options.
+	// Keeper returns nil instead of an error when initialization fails.
+	ksmClient := ksm.NewSecretsManager(clientOptions, transportContext(transport)...)
+	if ksmClient == nil {
+		return nil, ErrClientInit
 	}
 
-	// Keeper doesn't validate the config content so we need to do it ourselves
-	// without validation, the client will panic.
-	if err := validateClientOptions(clientOptions); err != nil {
-		return nil, err
-	}
-
-	// Create a new Keeper client with the provided options.
-	ksmClient := ksm.NewSecretsManager(clientOptions)
-	packerClient := &KSMClient{KeeperClient: ksmClient}
+	packerClient := &KSMClient{KeeperClient: ksmClient, transport: transport}
 
 	return packerClient, nil
 }
 
-// GetServerCredentials retrieves a ServerCredentials record from Keeper
-func (k *KSMClient) GetServerCredentials(r *ksm.Record) (*KeeperServerCredentials, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, SERVER_FIELD_TYPE)
+// GetServerCredentials retrieves a ServerCredentials record from Keeper, field selects the host
+func (k *KSMClient) GetServerCredentials(record *ksm.Record, field FieldSelector) (*KeeperServerCredentials, error) {
+	hosts, host, err := getHosts(record, field)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
 	}
 
 	// Extract the server credentials from the record
 	return &KeeperServerCredentials{
 		KeeperRecordField: *getRecordFields(record),
-		HostConnection:    *getHostItemData(record),
-		Login:             record.GetFieldValueByType("login"),
-		Password:          record.GetFieldValueByType("password"),
+		HostConnection:    host,
+		Hosts:             hosts,
+		Login:             getFieldValue(record, "login"),
+		Password:          getFieldValue(record, "password"),
 	}, nil
 }
 
-// GetDatabaseCredentials retrieves a DatabaseCredentials record from Keeper
-func (k *KSMClient) GetDatabaseCredentials(r *ksm.Record) (*KeeperDataBaseCredentials, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, DATABASE_FIELD_TYPE)
+// GetDatabaseCredentials retrieves a DatabaseCredentials record from Keeper, field selects the host
+func (k *KSMClient) GetDatabaseCredentials(record *ksm.Record, field FieldSelector) (*KeeperDataBaseCredentials, error) {
+	hosts, host, err := getHosts(record, field)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
 	}
 
 	// Extract the database credentials from the record
 	return &KeeperDataBaseCredentials{
 		KeeperRecordField: *getRecordFields(record),
-		HostConnection:    *getHostItemData(record),
-		Login:             record.GetFieldValueByType("login"),
-		Password:          record.GetFieldValueByType("password"),
-		DbType:            record.GetFieldValueByType("text"),
+		HostConnection:    host,
+		Hosts:             hosts,
+		Login:             getFieldValue(record, "login"),
+		Password:          getFieldValue(record, "password"),
+		DbType:            getFieldValue(record, "text"),
 	}, nil
 }
 
 // GetAPIKey retrieves an API Key record from Keeper
 // Renamed from GetApiKey for consistency with KeeperAPIKey return type
-func (k *KSMClient) GetAPIKey(r *ksm.Record) (*KeeperAPIKey, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, API_KEY_FIELD_TYPE)
-	if err != nil {
-		return nil, err
-	}
-
+func (k *KSMClient) GetAPIKey(record *ksm.Record) (*KeeperAPIKey, error) {
 	// Extract the API key from the record
 	return &KeeperAPIKey{
 		KeeperRecordField: *getRecordFields(record),
@@ -131,30 +148,18 @@ func (k *KSMClient) GetAPIKey(r *ksm.Record) (*KeeperAPIKey, error) {
 }
 
 // GetEncryptedNote retrieves an EncryptedNote record from Keeper
-func (k *KSMClient) GetEncryptedNote(r *ksm.Record) (*KeeperEncryptedNote, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, ENCRYPTED_NOTE_FIELD_TYPE)
-	if err != nil {
-		return nil, err
-	}
-
+func (k *KSMClient) GetEncryptedNote(record *ksm.Record) (*KeeperEncryptedNote, error) {
 	// Extract the encrypted note from the record
-	noteContent := record.GetFieldValueByType("note")
+	noteContent := getFieldValue(record, "note")
 	return &KeeperEncryptedNote{
 		KeeperRecordField: *getRecordFields(record),
 		Note:              noteContent,
-		Date:              ConvertDateStr(record.GetFieldValueByType("date")),
+		Date:              ConvertDateStr(getFieldValue(record, "date")),
 	}, nil
 }
 
 // GetFile retrieves a File record from Keeper
-func (k *KSMClient) GetFile(r *ksm.Record) (*KeeperFile, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, FILE_FIELD_TYPE)
-	if err != nil {
-		return nil, err
-	}
-
+func (k *KSMClient) GetFile(record *ksm.Record) (*KeeperFile, error) {
 	// Extract the file record from the record
 	return &KeeperFile{
 		KeeperRecordField: *getRecordFields(record),
@@ -162,69 +167,110 @@ func (k *KSMClient) GetFile(r *ksm.Record) (*KeeperFile, error) {
 }
 
 // GetSoftwareLicense retrieves a SoftwareLicense record from Keeper
-func (k *KSMClient) GetSoftwareLicense(r *ksm.Record) (*KeeperSoftwareLicense, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, SOFTWARE_LICENSE_FIELD_TYPE)
-	if err != nil {
-		return nil, err
-	}
-
+func (k *KSMClient) GetSoftwareLicense(record *ksm.Record) (*KeeperSoftwareLicense, error) {
 	// Extract the software license from the record
 	return &KeeperSoftwareLicense{
 		KeeperRecordField: *getRecordFields(record),
-		LicenseNumber:     record.GetFieldValueByType("licenseNumber"),
-		ActivationDate:    ConvertDateStr(record.GetFieldValueByType("date")),
-		ExpirationDate:    ConvertDateStr(record.GetFieldValueByType("expirationDate")),
+		LicenseNumber:     getFieldValue(record, "licenseNumber"),
+		ActivationDate:    ConvertDateStr(getFieldValue(record, "date")),
+		ExpirationDate:    ConvertDateStr(getFieldValue(record, "expirationDate")),
 	}, nil
 }
 
-// GetLogin retrieves a Login record from Keeper
-func (k *KSMClient) GetLogin(r *ksm.Record) (*KeeperLogin, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, LOGIN_FIELD_TYPE)
+// GetLogin retrieves a Login record from Keeper, field selects the url
+func (k *KSMClient) GetLogin(record *ksm.Record, field FieldSelector) (*KeeperLogin, error) {
+	urls, url, err := getFieldStrings(record, "url", field)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
 	}
 
 	// Extract the login record from the record
-	return &KeeperLogin{
+	login := &KeeperLogin{
 		KeeperRecordField: *getRecordFields(record),
-		Login:             record.GetFieldValueByType(LOGIN_FIELD_TYPE),
-		Password:          record.GetFieldValueByType(PASSWORD_FIELD_TYPE),
-		Url:               record.GetFieldValueByType("url"),
-	}, nil
+		Login:             getFieldValue(record, LOGIN_FIELD_TYPE),
+		Password:          getFieldValue(record, PASSWORD_FIELD_TYPE),
+		Url:               url,
+		Urls:              urls,
+	}
 
+	// Compute the current one-time code when the record has one. One-time codes the plugin can't
+	// compute (ex: HOTP) leave the TOTP outputs empty rather than failing templates reading the login.
+	totp, err := getTOTP(record)
+	if err != nil {
+		log.Printf("[WARN] record %s: unable to compute its one-time code, leaving totp and totp_code empty: %s", record.Uid, err)
+		return login, nil
+	}
+	if totp != nil {
+		now := time.Now
+		if k.now != nil {
+			now = k.now
+		}
+
+		code, remaining, err := totp.Code(now())
+		if err != nil {
+			log.Printf("[WARN] record %s: unable to compute its one-time code, leaving totp and totp_code empty: %s", record.Uid, err)
+			return login, nil
+		}
+
+		login.TOTP = *totp
+		login.TOTPCode, login.TOTPSecondsRemaining = code, remaining
+	}
+
+	return login, nil
 }
 
-// GetSSHKey retrieves an SSH Key record from Keeper
-func (k *KSMClient) GetSSHKey(r *ksm.Record) (*KeeperSSHKey, error) {
-	// Validate the record is of the correct type
-	record, err := k.validateRecord(r, SSH_KEY_FIELD_TYPE)
+// GetSSHKey retrieves an SSH Key record from Keeper, field selects the key pair
+func (k *KSMClient) GetSSHKey(record *ksm.Record, field FieldSelector) (*KeeperSSHKey, error) {
+	keyPairs, selected, err := getKeyPairs(record, field)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	// Decrypt the private keys and derive what the record doesn't store. Only the selected key pair
+	// fails the datasource, the passphrase of the record may not be the one of older key pairs.
+	passphrase := getFieldValue(record, PASSWORD_FIELD_TYPE)
+	var keyPair KeyPair
+	for i := range keyPairs {
+		err := normalizeKeyPair(&keyPairs[i], passphrase)
+		if i != selected {
+			if err != nil {
+				log.Printf("[WARN] record %s: %s", record.Uid, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+		}
+		keyPair = keyPairs[i]
 	}
 
+	// The host isn't selected by field, SSH key records rarely hold more than one.
+	hosts, host, _ := getHosts(record, FieldSelector{})
+
 	// Extract the SSH key from the record
 	return &KeeperSSHKey{
 		KeeperRecordField: *getRecordFields(record),
-		Login:             record.GetFieldValueByType(LOGIN_FIELD_TYPE),
-		Passphrase:        record.GetFieldValueByType(PASSWORD_FIELD_TYPE),
-		KeyPair:           *getKeyPairItemData(record),
-		HostConnection:    *getHostItemData(record),
+		Login:             getFieldValue(record, LOGIN_FIELD_TYPE),
+		Passphrase:        passphrase,
+		KeyPair:           keyPair,
+		KeyPairs:          keyPairs,
+		HostConnection:    host,
+		Hosts:             hosts,
 	}, nil
 }
 
 // GetSecret retrieves a generic record by uid from Keeper
 func (k *KSMClient) GetSecret(uid string) (*ksm.Record, error) {
 	// Fetch the record from Keeper using the provided uid
-	records, err := k.KeeperClient.GetSecrets([]string{uid})
+	records, err := k.GetSecrets([]string{uid})
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if any records were found, if not return an error
 	if len(records) == 0 {
-		return nil, fmt.Errorf("no records found for uid %s", uid)
+		return nil, fmt.Errorf("%w for uid %s", ErrRecordNotFound, uid)
 	}
 
 	// Get the first record from the list and return it (there should only be one)
@@ -232,6 +278,96 @@ func (k *KSMClient) GetSecret(uid string) (*ksm.Record, error) {
 	return record, nil
 }
 
+// GetSecrets retrieves the records for the uids from Keeper in a single request
+func (k *KSMClient) GetSecrets(uids []string) ([]*ksm.Record, error) {
+	if k.offline == nil {
+		return k.fetch(uids)
+	}
+
+	// With the offline fallback the response can hold every record shared with the application.
+	fetched, err := k.fetch(uids)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, uid := range uids {
+		wanted[uid] = true
+	}
+
+	records := []*ksm.Record{}
+	for _, r := range fetched {
+		if wanted[r.Uid] {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// fetch retrieves the records for the uids, or every record shared with the application when uids is
+// empty. With the offline fallback every record is fetched until the offline copy is saved, so it can
+// serve any record later.
+func (k *KSMClient) fetch(uids []string) ([]*ksm.Record, error) {
+	if k.offline == nil {
+		return k.fetchResponse(uids)
+	}
+
+	var records []*ksm.Record
+	err := k.offline.Request(len(uids) == 0, func(all bool) error {
+		query := uids
+		if all {
+			query = []string{}
+		}
+
+		var err error
+		records, err = k.fetchResponse(query)
+		return err
+	})
+
+	return records, err
+}
+
+// fetchResponse sends a single request for the records and remembers the title of the application
+// for error hints.
+func (k *KSMClient) fetchResponse(uids []string) ([]*ksm.Record, error) {
+	resp, err := k.KeeperClient.GetSecretsFullResponse(uids)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.AppData.Title != "" {
+		k.mu.Lock()
+		k.appTitle = resp.AppData.Title
+		k.mu.Unlock()
+	}
+
+	return resp.Records, nil
+}
+
+// ApplicationName returns the title of the KSM application, known once Keeper answered a request.
+func (k *KSMClient) ApplicationName() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.appTitle
+}
+
+// SetOfflineCache makes Keeper save responses holding every record to the cache and read the last
+// one back when a request fails.
+func (k *KSMClient) SetOfflineCache(cache *offlineCache) {
+	k.KeeperClient.SetCache(cache)
+	k.offline = cache
+}
+
+// GetSecretsByTitle retrieves every record shared with the application whose title matches exactly
+func (k *KSMClient) GetSecretsByTitle(title string) ([]*ksm.Record, error) {
+	records, err := k.fetch([]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	return ksm.FindSecretsByTitle(title, records), nil
+}
+
 // ConvertDateStr converts a date (unix timestamp) to a time string
 // the string field is misleading as it is actually a unix timestamp in milliseconds,
 // but keeper coerces it to a string when returning the record value.
@@ -247,66 +383,116 @@ func ConvertDateStr(dateStr string) string {
 	return timeStr
 }
 
-// validateRecord checks if the record is of the expected type
-func (k *KSMClient) validateRecord(r *ksm.Record, recordType string) (*ksm.Record, error) {
-	if r.Type() != recordType {
-		return nil, fmt.Errorf("%w Uid: %s ExpectedType: %s, ActualType: %s", ErrWrongRecordType, r.Uid, recordType, r.Type())
-	}
-
-	return r, nil
-}
-
 // getRecordFields extracts the common fields from a Keeper record
 func getRecordFields(r *ksm.Record) *KeeperRecordField {
+	customFields, customSecrets := getCustomFields(r)
 	return &KeeperRecordField{
-		Uid:      r.Uid,
-		Type:     r.Type(),
-		Title:    r.Title(),
-		Notes:    r.Notes(),
-		FileRefs: getFileRecords(r),
+		Uid:           r.Uid,
+		Type:          r.Type(),
+		Title:         r.Title(),
+		Notes:         r.Notes(),
+		FileRefs:      getFileRecords(r),
+		CustomFields:  customFields,
+		customSecrets: customSecrets,
 	}
 }
 
-// getClientOptions retrieves the Keeper client options from environment variables
-func getClientOptions() (*ksm.ClientOptions, error) {
+// getClientOptions retrieves the Keeper client options from the HCL config, falling back
+// to environment variables when no config attribute is set. The returned source describes
+// where the config was loaded from so errors can point the user at the right place.
+func getClientOptions(c ClientConfig) (*ksm.ClientOptions, string, error) {
+	// Config set on the datasource takes precedence over the environment so each
+	// data block can choose which KSM application to use.
+	if c.ConfigFile != "" {
+		return readConfigFile(c.ConfigFile, fmt.Sprintf("config_file (%s)", c.ConfigFile), configReadOnly(c))
+	}
+
+	if c.ConfigBase64 != "" {
+		return newClientOptions(c.ConfigBase64), "config_base64", nil
+	}
+
+	if c.ConfigJSON != "" {
+		return newClientOptions(c.ConfigJSON), "config_json", nil
+	}
+
+	if c.Token != "" {
+		token, configFile, ok := getTokenOptions(c)
+		if !ok {
+			return nil, "", ErrInvalidToken
+		}
+		return getTokenClientOptions(c, token, configFile)
+	}
+
+	if len(c.ConfigCommand) > 0 {
+		command, timeout, _, err := getCommandOptions(c)
+		if err != nil {
+			return nil, "", err
+		}
+		return getCommandClientOptions(command, timeout)
+	}
+
 	// Check if the KSM_CONFIG_FILE environment variable is set if so, read the file from disk
 	// and initialize the client options with the file content.
 	configFile, ok := os.LookupEnv(KEEPER_CONFIG_FILE_ENV_KEY)
 	if ok {
-		content, err := os.ReadFile(configFile)
-		if err != nil {
-			return nil, err
-		}
-
-		return &ksm.ClientOptions{
-			Config: ksm.NewMemoryKeyValueStorage(string(content)),
-		}, nil
+		return readConfigFile(configFile, fmt.Sprintf("environment variable %s (%s)", KEEPER_CONFIG_FILE_ENV_KEY, configFile), configReadOnly(c))
 	}
 
 	// Check if the KSM_CONFIG environment variable is set, if so, use it to initialize the client options.
 	// the environment variable content should be the base64 encoded config content
 	configContent, ok := os.LookupEnv(KEEPER_CONFIG_ENV_KEY)
 	if ok {
-		return &ksm.ClientOptions{
-			Config: ksm.NewMemoryKeyValueStorage(configContent),
-		}, nil
+		return newClientOptions(configContent), "environment variable " + KEEPER_CONFIG_ENV_KEY, nil
+	}
+
+	// Check if the KEEPER_CONFIG_COMMAND environment variable is set, if so, run it to get the config.
+	command, timeout, ok, err := getCommandOptions(c)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		return getCommandClientOptions(command, timeout)
+	}
+
+	// Fall back to bootstrapping the config from a one-time token set in the environment.
+	if token, configFile, ok := getTokenOptions(c); ok {
+		return getTokenClientOptions(c, token, configFile)
 	}
 
-	return nil, ErrNoConfig
+	return nil, "", ErrNoConfig
+}
+
+// readConfigFile loads a KSM config from disk into a file backed storage, so updates
+// Keeper makes to the config are written back to the file unless readOnly is set.
+func readConfigFile(path string, source string, readOnly bool) (*ksm.ClientOptions, string, error) {
+	storage, err := NewFileConfigStorage(path, readOnly)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read KSM config from %s: %w", source, err)
+	}
+
+	return &ksm.ClientOptions{Config: storage}, source, nil
+}
+
+// newClientOptions initializes client options from a raw JSON or base64 encoded config.
+func newClientOptions(content string) *ksm.ClientOptions {
+	return &ksm.ClientOptions{
+		Config: ksm.NewMemoryKeyValueStorage(content),
+	}
 }
 
 // validateClientOptions checks if the provided client options are valid
 // keeper doesn't validate the config content so we need to do it ourselves
-func validateClientOptions(c *ksm.ClientOptions) error {
+func validateClientOptions(c *ksm.ClientOptions, source string) error {
 	if c.Config.Get(core.KEY_APP_KEY) == "" || c.Config.Get(core.KEY_CLIENT_ID) == "" || c.Config.Get(core.KEY_PRIVATE_KEY) == "" {
-		return fmt.Errorf("Invalid credentials - please provide a valid base64 encoded KSM config. One-time tokens are not allowed.")
+		return fmt.Errorf("Invalid credentials loaded from %s - please provide a valid base64 encoded KSM config. To use a one-time token set token and token_config_file instead.", source)
 	}
 
 	return nil
 }
 
 // getFileRecords extracts the file records from a Keeper record
-// this is a common record type that are part of all Keeper records.
+// this is a common record type that are part of all Keeper records. Only the metadata of the
+// files is listed, their content is downloaded by downloadFiles when the datasource asks for it.
 func getFileRecords(r *ksm.Record) []FileRef {
 	fileRefs := []FileRef{}
 	for _, f := range r.Files {
@@ -317,7 +503,6 @@ func getFileRecords(r *ksm.Record) []FileRef {
 			Type:         f.Type,
 			Size:         f.Size,
 			LastModified: f.LastModified,
-			Base64Data:   base64.StdEncoding.EncodeToString(f.GetFileData()),
 		}
 
 		fileRefs = append(fileRefs, fileRef)
@@ -325,80 +510,3 @@ func getFileRecords(r *ksm.Record) []FileRef {
 
 	return fileRefs
 }
-
-// getHostItemData extracts the host connection data from a Keeper record
-func getHostItemData(secret *core.Record) *HostConnection {
-	// Host data is stored in the host key in Keeper
-	fields := secret.GetFieldsByType("host")
-	if len(fields) == 0 {
-		return &HostConnection{}
-	}
-
-	hc := &HostConnection{}
-
-	// Make sure the field is a list of interface
-	values, ok := fields[0]["value"].([]interface{})
-	if !ok || len(values) == 0 {
-		return hc
-	}
-
-	// Make sure the value is a map
-	valuesMap, ok := values[0].(map[string]interface{})
-	if !ok {
-		return hc
-	}
-
-	// Extract the host connection data from the map
-	if val, ok := valuesMap["hostName"].(string); ok {
-		hc.HostName = val
-	}
-
-	// Extract the port from the map
-	if val, ok := valuesMap["port"].(string); ok {
-		// Attempt to convert the port from a string to an int
-		// if it fails return -1
-		port, err := strconv.Atoi(val)
-		if err != nil {
-			hc.Port = -1
-			return hc
-		}
-
-		hc.Port = port
-	}
-
-	return hc
-}
-
-// getKeyPairItemData extracts the key pair data from a Keeper SSH key
-func getKeyPairItemData(secret *core.Record) *KeyPair {
-	// Key pair data is stored in the keyPair key in Keeper
-	fields := secret.GetFieldsByType("keyPair")
-	if len(fields) == 0 {
-		return &KeyPair{}
-	}
-
-	keypair := &KeyPair{}
-
-	// Make sure the field is a list of interface
-	values, ok := fields[0]["value"].([]interface{})
-	if !ok || len(values) == 0 {
-		return keypair
-	}
-
-	// Make sure the value is a map
-	valuesMap, ok := values[0].(map[string]interface{})
-	if !ok {
-		return keypair
-	}
-
-	// Extract the key pair data from the map
-	if pub, ok := valuesMap["publicKey"].(string); ok {
-		keypair.PublicKey = pub
-	}
-
-	if priv, ok := valuesMap["privateKey"].(string); ok {
-		keypair.PrivateKey = priv
-	}
-
-	return keypair
-}
@@ -6,7 +6,7 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-// MockWrapper wraps a real KeeperClient and allows mocking the GetSecret method.
+// MockWrapper wraps a real KeeperClient and allows mocking the GetSecret and GetSecretsByTitle methods.
 type MockKeeperClient struct {
 	mock.Mock
 	TestClient KeeperClient
@@ -14,15 +14,37 @@ type MockKeeperClient struct {
 
 var _ KeeperClient = (*MockKeeperClient)(nil)
 
-// Mock only GetSecret, delegate the rest to the real client
+// Mock GetSecret, delegate the rest to the real client
 func (m *MockKeeperClient) GetSecret(uid string) (*core.Record, error) {
 	args := m.Called()
 	return args.Get(0).(*core.Record), args.Error(1)
 }
 
+// GetSecrets answers each uid with the mocked GetSecret, the record is returned under the requested uid
+func (m *MockKeeperClient) GetSecrets(uids []string) ([]*core.Record, error) {
+	records := []*core.Record{}
+	for _, uid := range uids {
+		record, err := m.GetSecret(uid)
+		if err != nil {
+			return nil, err
+		}
+
+		record = copyRecord(record)
+		record.Uid = uid
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Mock GetSecretsByTitle so tests can return records for a title lookup
+func (m *MockKeeperClient) GetSecretsByTitle(title string) ([]*core.Record, error) {
+	args := m.Called(title)
+	return args.Get(0).([]*core.Record), args.Error(1)
+}
+
 // Delegate the rest of the methods to the real client
-func (m *MockKeeperClient) GetLogin(r *ksm.Record) (*KeeperLogin, error) {
-	return m.TestClient.GetLogin(r)
+func (m *MockKeeperClient) GetLogin(r *ksm.Record, field FieldSelector) (*KeeperLogin, error) {
+	return m.TestClient.GetLogin(r, field)
 }
 
 func (m *MockKeeperClient) GetAPIKey(r *ksm.Record) (*KeeperAPIKey, error) {
@@ -41,14 +63,38 @@ func (m *MockKeeperClient) GetEncryptedNote(r *ksm.Record) (*KeeperEncryptedNote
 	return m.TestClient.GetEncryptedNote(r)
 }
 
-func (m *MockKeeperClient) GetDatabaseCredentials(r *ksm.Record) (*KeeperDataBaseCredentials, error) {
-	return m.TestClient.GetDatabaseCredentials(r)
+func (m *MockKeeperClient) GetDatabaseCredentials(r *ksm.Record, field FieldSelector) (*KeeperDataBaseCredentials, error) {
+	return m.TestClient.GetDatabaseCredentials(r, field)
+}
+
+func (m *MockKeeperClient) GetServerCredentials(r *ksm.Record, field FieldSelector) (*KeeperServerCredentials, error) {
+	return m.TestClient.GetServerCredentials(r, field)
+}
+
+func (m *MockKeeperClient) GetSSHKey(r *ksm.Record, field FieldSelector) (*KeeperSSHKey, error) {
+	return m.TestClient.GetSSHKey(r, field)
+}
+
+func (m *MockKeeperClient) GetGenericRecord(r *ksm.Record) (*KeeperRecord, error) {
+	return m.TestClient.GetGenericRecord(r)
+}
+
+func (m *MockKeeperClient) GetPamMachine(r *ksm.Record) (*KeeperPamMachine, error) {
+	return m.TestClient.GetPamMachine(r)
+}
+
+func (m *MockKeeperClient) GetPamDatabase(r *ksm.Record) (*KeeperPamDatabase, error) {
+	return m.TestClient.GetPamDatabase(r)
+}
+
+func (m *MockKeeperClient) GetPamDirectory(r *ksm.Record) (*KeeperPamDirectory, error) {
+	return m.TestClient.GetPamDirectory(r)
 }
 
-func (m *MockKeeperClient) GetServerCredentials(r *ksm.Record) (*KeeperServerCredentials, error) {
-	return m.TestClient.GetServerCredentials(r)
+func (m *MockKeeperClient) GetPamUser(r *ksm.Record) (*KeeperPamUser, error) {
+	return m.TestClient.GetPamUser(r)
 }
 
-func (m *MockKeeperClient) GetSSHKey(r *ksm.Record) (*KeeperSSHKey, error) {
-	return m.TestClient.GetSSHKey(r)
+func (m *MockKeeperClient) GetCertificate(r *ksm.Record, options CertificateOptions) (*KeeperCertificate, error) {
+	return m.TestClient.GetCertificate(r, options)
 }
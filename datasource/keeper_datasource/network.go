@@ -0,0 +1,274 @@
+package keeper_datasource
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Environment variables controlling TLS verification. Skipping verification is only
+// meant for pointing the plugin at a local KSM stand-in, so it can't be enabled from HCL.
+const (
+	KEEPER_INSECURE_SKIP_VERIFY_ENV_KEY = "KEEPER_INSECURE_SKIP_VERIFY"
+	KSM_SKIP_VERIFY_ENV_KEY             = "KSM_SKIP_VERIFY"
+)
+
+// keeperRegions maps the region shortcuts accepted by hostname to Keeper servers.
+// These mirror the regions Keeper accepts as a one-time token prefix.
+var keeperRegions = map[string]string{
+	"US":  "keepersecurity.com",
+	"EU":  "keepersecurity.eu",
+	"AU":  "keepersecurity.com.au",
+	"GOV": "govcloud.keepersecurity.us",
+	"JP":  "keepersecurity.jp",
+	"CA":  "keepersecurity.ca",
+}
+
+// Errors for handling network settings.
+var (
+	ErrInvalidHostname = errors.New("invalid hostname, expected a region (US, EU, AU, GOV, JP, CA) or a host with an optional port")
+	ErrInvalidProxyURL = errors.New("invalid proxy_url, expected an http, https or socks5 URL")
+	ErrInvalidCABundle = errors.New("ca_bundle_file doesn't contain any PEM encoded certificates")
+)
+
+// networkSettings controls where and how the plugin connects to Keeper.
+type networkSettings struct {
+	Hostname           string
+	ProxyURL           string
+	CABundleFile       string
+	InsecureSkipVerify bool
+	Retry              retrySettings
+}
+
+// getNetworkSettings returns the network settings for the client config. The hostname
+// is resolved from a region shortcut when needed.
+func getNetworkSettings(c ClientConfig) (networkSettings, error) {
+	hostname, err := resolveHostname(c.Hostname)
+	if err != nil {
+		return networkSettings{}, err
+	}
+
+	if c.ProxyURL != "" {
+		if _, err := parseProxyURL(c.ProxyURL); err != nil {
+			return networkSettings{}, err
+		}
+	}
+
+	retry, err := getRetrySettings(c)
+	if err != nil {
+		return networkSettings{}, err
+	}
+
+	return networkSettings{
+		Hostname:           hostname,
+		ProxyURL:           c.ProxyURL,
+		CABundleFile:       c.CABundleFile,
+		InsecureSkipVerify: insecureSkipVerify(),
+		Retry:              retry,
+	}, nil
+}
+
+// resolveHostname converts a region shortcut to its Keeper server and checks other values
+// are a host with an optional port. URLs are accepted and reduced to their host.
+func resolveHostname(hostname string) (string, error) {
+	hostname = strings.TrimSpace(hostname)
+	if hostname == "" {
+		return "", nil
+	}
+
+	if server, ok := keeperRegions[strings.ToUpper(hostname)]; ok {
+		return server, nil
+	}
+
+	raw := hostname
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || strings.Trim(u.Path, "/") != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidHostname, hostname)
+	}
+
+	return u.Host, nil
+}
+
+// parseProxyURL parses and validates the proxy URL.
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProxyURL, proxyURL)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidProxyURL, proxyURL)
+}
+
+// insecureSkipVerify reports whether TLS verification is disabled through the environment.
+// KSM_SKIP_VERIFY is honored as well since Keeper reads it when the default transport is used.
+func insecureSkipVerify() bool {
+	for _, key := range []string{KEEPER_INSECURE_SKIP_VERIFY_ENV_KEY, KSM_SKIP_VERIFY_ENV_KEY} {
+		if skip, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key))); err == nil && skip {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyHostname overrides the hostname in the KSM config without persisting it,
+// so a config file is never rewritten to point at another server.
+func applyHostname(options *ksm.ClientOptions, hostname string) {
+	if hostname == "" {
+		return
+	}
+
+	options.Config = &hostnameStorage{IKeyValueStorage: options.Config, hostname: hostname}
+}
+
+// hostnameStorage wraps a KSM config storage and pins the hostname. Writes to the hostname
+// are dropped while every other key is read from and written to the wrapped storage.
+type hostnameStorage struct {
+	ksm.IKeyValueStorage
+	hostname string
+}
+
+func (s *hostnameStorage) Get(key ksm.ConfigKey) string {
+	if key == ksm.KEY_HOSTNAME {
+		return s.hostname
+	}
+
+	return s.IKeyValueStorage.Get(key)
+}
+
+func (s *hostnameStorage) Set(key ksm.ConfigKey, value interface{}) map[string]interface{} {
+	if key == ksm.KEY_HOSTNAME {
+		return s.ReadStorage()
+	}
+
+	return s.IKeyValueStorage.Set(key, value)
+}
+
+func (s *hostnameStorage) ReadStorage() map[string]interface{} {
+	config := s.IKeyValueStorage.ReadStorage()
+	config[string(ksm.KEY_HOSTNAME)] = s.hostname
+	return config
+}
+
+func (s *hostnameStorage) SaveStorage(updatedConfig map[string]interface{}) {
+	// Keep the hostname of the wrapped storage so the override is never saved.
+	config := make(map[string]interface{}, len(updatedConfig))
+	for k, v := range updatedConfig {
+		config[k] = v
+	}
+
+	if hostname := s.IKeyValueStorage.Get(ksm.KEY_HOSTNAME); hostname != "" {
+		config[string(ksm.KEY_HOSTNAME)] = hostname
+	} else {
+		delete(config, string(ksm.KEY_HOSTNAME))
+	}
+
+	s.IKeyValueStorage.SaveStorage(config)
+}
+
+// newTransport builds the HTTP transport used to reach Keeper. nil is returned when no
+// setting requires a custom transport, so Keeper keeps using its default one.
+func newTransport(n networkSettings) (http.RoundTripper, error) {
+	_, port, _ := net.SplitHostPort(n.Hostname)
+	if n.ProxyURL == "" && n.CABundleFile == "" && !n.InsecureSkipVerify && port == "" {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if n.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(n.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if n.CABundleFile != "" {
+		pool, err := loadCABundle(n.CABundleFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if n.InsecureSkipVerify {
+		log.Printf("[WARN] TLS certificate verification is disabled for Keeper requests")
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	// Keeper drops the port from the hostname when building request URLs,
+	// so add it back for servers that don't listen on 443.
+	if port != "" {
+		return &hostPortTransport{base: transport, hostPort: n.Hostname}, nil
+	}
+
+	return transport, nil
+}
+
+// loadCABundle returns the system certificate pool with the certificates in path appended.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ca_bundle_file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCABundle, path)
+	}
+
+	return pool, nil
+}
+
+// hostPortTransport restores the port of the configured hostname on requests to that host.
+type hostPortTransport struct {
+	base     http.RoundTripper
+	hostPort string
+}
+
+func (t *hostPortTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host, _, _ := net.SplitHostPort(t.hostPort)
+	if req.URL.Host == host {
+		req = req.Clone(req.Context())
+		req.URL.Host = t.hostPort
+		req.Host = t.hostPort
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// transportContext returns the extra arguments for ksm.NewSecretsManager that make Keeper use
+// the transport, Keeper only accepts a custom transport through its context.
+func transportContext(transport http.RoundTripper) []interface{} {
+	if transport == nil {
+		return nil
+	}
+
+	ctx := &ksm.Context{Transport: transport}
+	return []interface{}{&ctx}
+}
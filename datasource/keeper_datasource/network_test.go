@@ -0,0 +1,151 @@
+package keeper_datasource
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateTestConfig returns a KSM config with real keys, so Keeper can sign requests to a stand-in server.
+func generateTestConfig(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	appKey := make([]byte, 32)
+	clientId := make([]byte, 64)
+	_, err = rand.Read(appKey)
+	require.NoError(t, err)
+	_, err = rand.Read(clientId)
+	require.NoError(t, err)
+
+	config, err := json.Marshal(map[string]string{
+		"appKey":     base64.StdEncoding.EncodeToString(appKey),
+		"clientId":   base64.StdEncoding.EncodeToString(clientId),
+		"privateKey": base64.StdEncoding.EncodeToString(der),
+	})
+	require.NoError(t, err)
+
+	return string(config)
+}
+
+// TestResolveHostname tests that region shortcuts, hosts and URLs are accepted and anything else is rejected.
+func TestResolveHostname(t *testing.T) {
+	tests := map[string]string{
+		"":                              "",
+		"eu":                            "keepersecurity.eu",
+		"GOV":                           "govcloud.keepersecurity.us",
+		"ksm.example.com":               "ksm.example.com",
+		"ksm.example.com:8443":          "ksm.example.com:8443",
+		"https://ksm.example.com:8443/": "ksm.example.com:8443",
+		"https://keepersecurity.com.au": "keepersecurity.com.au",
+	}
+	for hostname, expected := range tests {
+		resolved, err := resolveHostname(hostname)
+		require.NoError(t, err, hostname)
+		assert.Equal(t, expected, resolved)
+	}
+
+	_, err := resolveHostname("https://ksm.example.com/api")
+	require.ErrorIs(t, err, ErrInvalidHostname)
+
+	err = ValidateClientConfig(ClientConfig{ProxyURL: "ftp://proxy.example.com"})
+	require.ErrorIs(t, err, ErrInvalidProxyURL)
+}
+
+// TestHostnameOverrideIsNotPersisted tests that the hostname override is used by Keeper but never written to the config file.
+func TestHostnameOverrideIsNotPersisted(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"appKey": "app-key-a", "hostname": "keepersecurity.com"}`), 0600))
+
+	options, _, err := readConfigFile(configFile, "test", false)
+	require.NoError(t, err)
+	applyHostname(options, "keepersecurity.eu")
+
+	assert.Equal(t, "keepersecurity.eu", ksm.GetServerHostname("", options.Config))
+	options.Config.Set(ksm.KEY_HOSTNAME, "keepersecurity.jp")
+	options.Config.Set(ksm.KEY_SERVER_PUBLIC_KEY_ID, "10")
+	assert.Equal(t, "keepersecurity.eu", options.Config.Get(ksm.KEY_HOSTNAME))
+
+	content, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"keepersecurity.com"`)
+	assert.Contains(t, string(content), `"serverPublicKeyId"`)
+}
+
+// TestNewTransport tests that the default Keeper transport is kept unless a setting needs a custom one.
+func TestNewTransport(t *testing.T) {
+	transport, err := newTransport(networkSettings{Hostname: "keepersecurity.com"})
+	require.NoError(t, err)
+	assert.Nil(t, transport)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caBundle, []byte("not a certificate"), 0600))
+	_, err = newTransport(networkSettings{CABundleFile: caBundle})
+	require.ErrorIs(t, err, ErrInvalidCABundle)
+}
+
+// TestStandInServer tests that the hostname, including its port, and the CA bundle are used to reach a KSM stand-in.
+func TestStandInServer(t *testing.T) {
+	var path atomic.Value
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path.Store(r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"result_code": "access_denied", "message": "stand-in"}`))
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certPEM, 0600))
+
+	registry := NewClientRegistry(DefaultClientFactory)
+	client, err := registry.Get(ClientConfig{
+		ConfigJSON:   generateTestConfig(t),
+		Hostname:     server.Listener.Addr().String(),
+		CABundleFile: caBundle,
+	})
+	require.NoError(t, err)
+
+	_, err = client.KeeperClient.GetSecret("record-uid")
+	require.Error(t, err)
+	assert.Equal(t, "/api/rest/sm/v1/get_secret", path.Load())
+}
+
+// TestProxyURL tests that requests to Keeper go through the configured proxy.
+func TestProxyURL(t *testing.T) {
+	var method atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method.Store(r.Method + " " + r.Host)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	registry := NewClientRegistry(DefaultClientFactory)
+	client, err := registry.Get(ClientConfig{
+		ConfigJSON: generateTestConfig(t),
+		Hostname:   "EU",
+		ProxyURL:   proxy.URL,
+		MaxRetries: -1,
+	})
+	require.NoError(t, err)
+
+	_, err = client.KeeperClient.GetSecret("record-uid")
+	require.Error(t, err)
+	assert.Equal(t, "CONNECT keepersecurity.eu:443", method.Load())
+}
@@ -0,0 +1,216 @@
+package keeper_datasource
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+var ErrInvalidNotation = errors.New("invalid Keeper notation")
+
+// Notation is a parsed Keeper notation (ex: keeper://UID/field/password). Keeper notation is the
+// format other Keeper integrations use to reference a single value of a record.
+type Notation struct {
+	Raw       string
+	Uid       string
+	Selector  string
+	Parameter string
+	// Index is the value index, -1 selects every value of the field.
+	Index    int
+	Property string
+}
+
+// ParseNotation parses a Keeper notation. The record must be referenced by uid.
+func ParseNotation(notation string) (*Notation, error) {
+	sections, err := ksm.ParseNotation(notation)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrInvalidNotation, notation, err)
+	}
+
+	// Sections are the prefix, record, selector and footer. The parser validates
+	// the record and selector are present.
+	record, selector := sections[1], sections[2]
+	n := &Notation{
+		Raw:      notation,
+		Uid:      record.Text.Text,
+		Selector: strings.ToLower(selector.Text.Text),
+		Index:    -1,
+	}
+
+	if selector.Parameter != nil {
+		n.Parameter = selector.Parameter.Text
+	}
+
+	if selector.Index1 != nil && selector.Index1.Text != "" {
+		n.Index, err = strconv.Atoi(selector.Index1.Text)
+		if err != nil || n.Index < 0 {
+			return nil, fmt.Errorf("%w %q: invalid index %s", ErrInvalidNotation, notation, selector.Index1.RawText)
+		}
+	}
+
+	if selector.Index2 != nil {
+		n.Property = selector.Index2.Text
+	}
+
+	return n, nil
+}
+
+// KeeperNotations are the values of resolved Keeper notations, by name.
+type KeeperNotations struct {
+	Values map[string]string
+}
+
+// SecretValues returns the values to filter from logs. Notations can reference any value of a record,
+// so every value is secret except empty ones, which would mask nothing.
+func (k *KeeperNotations) SecretValues() []string {
+	values := []string{}
+	for _, value := range k.Values {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// GetNotations resolves a map of Keeper notations to their values. Each record is
+// fetched once no matter how many notations reference it.
+func (c *PackerKeeperClient) GetNotations(notations map[string]string) (*KeeperNotations, error) {
+	// Sort the names so errors are reported in a stable order.
+	names := make([]string, 0, len(notations))
+	for name := range notations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := make(map[string]*Notation, len(notations))
+	for _, name := range names {
+		n, err := ParseNotation(notations[name])
+		if err != nil {
+			return nil, fmt.Errorf("notation %s: %w", name, err)
+		}
+		parsed[name] = n
+	}
+
+	// Fetch every record in a single batch.
+	uids := make([]string, 0, len(parsed))
+	for _, name := range names {
+		uids = append(uids, parsed[name].Uid)
+	}
+
+	fetched, err := c.fetcher.Get(uids...)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]*ksm.Record, len(fetched))
+	for _, r := range fetched {
+		records[r.Uid] = r
+	}
+
+	resolved := &KeeperNotations{
+		Values: make(map[string]string, len(parsed)),
+	}
+	for _, name := range names {
+		n := parsed[name]
+		value, err := n.Resolve(records[n.Uid], c.transport)
+		if err != nil {
+			return nil, fmt.Errorf("notation %s: %w", name, err)
+		}
+		resolved.Values[name] = value
+	}
+
+	return resolved, nil
+}
+
+// fields returns the fields of the record matching the notation parameter in the section of the selector.
+func (n *Notation) fields(r *ksm.Record) []map[string]interface{} {
+	section := ksm.FieldSectionFields
+	if n.Selector == "custom_field" {
+		section = ksm.FieldSectionCustom
+	}
+
+	return r.GetFieldsByMask(n.Parameter, ksm.FieldTokenBoth, section)
+}
+
+// Resolve returns the value the notation references in the record. Values that aren't
+// strings are JSON encoded, as is the full value of a field holding more than one value.
+// File contents are downloaded through transport and returned as text, or base64 encoded if the
+// file isn't valid UTF-8. The default HTTP transport is used when transport is nil.
+func (n *Notation) Resolve(r *ksm.Record, transport http.RoundTripper) (string, error) {
+	switch n.Selector {
+	case "type":
+		return r.Type(), nil
+	case "title":
+		return r.Title(), nil
+	case "notes":
+		return r.Notes(), nil
+	case "file":
+		return n.resolveFile(r, transport)
+	}
+
+	fields := n.fields(r)
+	if len(fields) != 1 {
+		return "", fmt.Errorf("%w %q: record %s has %d fields matching %q", ErrInvalidNotation, n.Raw, r.Uid, len(fields), n.Parameter)
+	}
+
+	values, _ := fields[0]["value"].([]interface{})
+	if n.Index >= len(values) {
+		return "", fmt.Errorf("%w %q: index %d is out of range, field %q has %d values", ErrInvalidNotation, n.Raw, n.Index, n.Parameter, len(values))
+	}
+
+	if n.Index >= 0 {
+		values = values[n.Index : n.Index+1]
+	}
+
+	if n.Property != "" {
+		properties := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			object, _ := v.(map[string]interface{})
+			property, ok := object[n.Property]
+			if !ok {
+				return "", fmt.Errorf("%w %q: field %q has no property %q", ErrInvalidNotation, n.Raw, n.Parameter, n.Property)
+			}
+			properties = append(properties, property)
+		}
+		values = properties
+	}
+
+	if len(values) == 1 {
+		return fieldValueString(values[0])
+	}
+
+	return fieldValueString(values)
+}
+
+// resolveFile returns the content of the file matching the notation parameter by name, title or uid.
+func (n *Notation) resolveFile(r *ksm.Record, transport http.RoundTripper) (string, error) {
+	files := []*ksm.KeeperFile{}
+	for _, f := range r.Files {
+		if n.Parameter == f.Name || n.Parameter == f.Title || n.Parameter == f.Uid {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) != 1 {
+		return "", fmt.Errorf("%w %q: record %s has %d files matching %q", ErrInvalidNotation, n.Raw, r.Uid, len(files), n.Parameter)
+	}
+
+	data, err := downloadFile(transport, files[0], 0)
+	if err != nil {
+		return "", err
+	}
+
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
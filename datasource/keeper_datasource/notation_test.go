@@ -0,0 +1,82 @@
+package keeper_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notationRecord = `{
+	"uid": "notation-uid",
+	"title": "Web server",
+	"type": "login",
+	"fields": [
+		{"type": "login", "value": ["admin"]},
+		{"type": "password", "value": ["hunter2"]},
+		{"type": "host", "value": [{"hostName": "web.example.com", "port": "443"}]}
+	],
+	"custom": [
+		{"type": "text", "label": "Backup Codes", "value": ["code-1", "code-2"]}
+	],
+	"files": [
+		{"uid": "file-uid", "name": "cert.pem", "title": "Certificate", "type": "text/plain", "size": 6, "last_modified": 0}
+	]
+}`
+
+// TestParseNotation tests that notations are parsed into their parts and invalid notations are rejected.
+func TestParseNotation(t *testing.T) {
+	n, err := ParseNotation("keeper://notation-uid/custom_field/Backup Codes[1]")
+	require.NoError(t, err)
+	assert.Equal(t, "notation-uid", n.Uid)
+	assert.Equal(t, "custom_field", n.Selector)
+	assert.Equal(t, "Backup Codes", n.Parameter)
+	assert.Equal(t, 1, n.Index)
+
+	_, err = ParseNotation("keeper://notation-uid/password")
+	require.ErrorIs(t, err, ErrInvalidNotation)
+
+	// Negative indexes are rejected rather than selecting every value.
+	_, err = ParseNotation("keeper://notation-uid/field/url[-1]")
+	require.ErrorIs(t, err, ErrInvalidNotation)
+}
+
+// TestGetNotations tests that each notation resolves to the referenced value and the record is fetched once.
+func TestGetNotations(t *testing.T) {
+	mockClient := &MockKeeperClient{TestClient: &KSMClient{}}
+	mockClient.On("GetSecret").Return(recordFromJSON(notationRecord), nil)
+	client := NewClient(mockClient)
+
+	notations, err := client.GetNotations(map[string]string{
+		"title":      "keeper://notation-uid/title",
+		"login":      "keeper://notation-uid/field/login",
+		"password":   "notation-uid/field/password[0]",
+		"hostname":   "keeper://notation-uid/field/host[0][hostName]",
+		"host":       "keeper://notation-uid/field/host",
+		"codes":      "keeper://notation-uid/custom_field/Backup Codes",
+		"secondCode": "keeper://notation-uid/custom_field/Backup Codes[1]",
+		"file":       "keeper://notation-uid/file/cert.pem",
+		"notes":      "keeper://notation-uid/notes",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"title":      "Web server",
+		"login":      "admin",
+		"password":   "hunter2",
+		"hostname":   "web.example.com",
+		"host":       `{"hostName":"web.example.com","port":"443"}`,
+		"codes":      `["code-1","code-2"]`,
+		"secondCode": "code-2",
+		"file":       "string",
+		"notes":      "",
+	}, notations.Values)
+	assert.ElementsMatch(t, []string{"Web server", "admin", "hunter2", "web.example.com", `{"hostName":"web.example.com","port":"443"}`,
+		`["code-1","code-2"]`, "code-2", "string"}, notations.SecretValues(), "every value but empty ones is secret")
+	mockClient.AssertNumberOfCalls(t, "GetSecret", 1)
+
+	_, err = client.GetNotations(map[string]string{"missing": "keeper://notation-uid/field/oneTimeCode"})
+	require.ErrorIs(t, err, ErrInvalidNotation)
+
+	_, err = client.GetNotations(map[string]string{"outOfRange": "keeper://notation-uid/field/password[3]"})
+	require.ErrorIs(t, err, ErrInvalidNotation)
+}
@@ -0,0 +1,310 @@
+package keeper_datasource
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Environment variables controlling the offline fallback.
+const (
+	KEEPER_OFFLINE_FALLBACK_ENV_KEY      = "KEEPER_OFFLINE_FALLBACK"
+	KEEPER_OFFLINE_DIR_ENV_KEY           = "KEEPER_OFFLINE_DIR"
+	KEEPER_OFFLINE_MAX_STALENESS_ENV_KEY = "KEEPER_OFFLINE_MAX_STALENESS"
+)
+
+// defaultOfflineMaxStaleness is how old the offline copy can be when no maximum staleness is configured.
+const defaultOfflineMaxStaleness = 24 * time.Hour
+
+// offlineKeyInfo binds the offline copy key derived from the KSM app key to the offline fallback.
+const offlineKeyInfo = "packer-plugin-keeper offline fallback"
+
+var ErrInvalidOfflineMaxStaleness = errors.New("offline_max_staleness can't be negative")
+
+// offlineSettings controls the fallback to the last Keeper response when Keeper is unreachable.
+type offlineSettings struct {
+	Enabled      bool
+	Dir          string
+	MaxStaleness time.Duration
+}
+
+// getOfflineSettings returns the offline fallback settings for the client config, preferring the
+// HCL config over the environment. The offline copy is kept in the user cache directory by default.
+func getOfflineSettings(c ClientConfig) (offlineSettings, error) {
+	settings := offlineSettings{
+		Enabled:      c.OfflineFallback || envBool(KEEPER_OFFLINE_FALLBACK_ENV_KEY),
+		Dir:          c.OfflineDir,
+		MaxStaleness: c.OfflineMaxStaleness,
+	}
+
+	if settings.Dir == "" {
+		settings.Dir = os.Getenv(KEEPER_OFFLINE_DIR_ENV_KEY)
+	}
+
+	if settings.MaxStaleness == 0 {
+		if env := os.Getenv(KEEPER_OFFLINE_MAX_STALENESS_ENV_KEY); env != "" {
+			maxStaleness, err := time.ParseDuration(env)
+			if err != nil {
+				return offlineSettings{}, fmt.Errorf("invalid duration in %s: %w", KEEPER_OFFLINE_MAX_STALENESS_ENV_KEY, err)
+			}
+			settings.MaxStaleness = maxStaleness
+		}
+	}
+
+	if settings.MaxStaleness < 0 {
+		return offlineSettings{}, ErrInvalidOfflineMaxStaleness
+	}
+
+	if settings.MaxStaleness == 0 {
+		settings.MaxStaleness = defaultOfflineMaxStaleness
+	}
+
+	if !settings.Enabled {
+		return settings, nil
+	}
+
+	if settings.Dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return offlineSettings{}, fmt.Errorf("unable to find a directory for the offline copy, set offline_dir: %w", err)
+		}
+		settings.Dir = filepath.Join(cacheDir, "packer-plugin-keeper", "offline")
+	}
+
+	return settings, nil
+}
+
+// offlineCache is a ksm.ICache holding the last successful response Keeper sent when fetching
+// every record. Keeper reads it back whenever a request fails, but records are only served from it
+// when Keeper couldn't be reached, so rejected credentials never fall back to stale records. The
+// response is stored along with the key needed to read it, so the file is encrypted with a key
+// derived from the app key.
+type offlineCache struct {
+	path         string
+	aead         cipher.AEAD
+	maxStaleness time.Duration
+
+	// mu serializes the requests sent with Request. Keeper reads the offline copy back without saying
+	// which request failed, so only one request can be in flight for its reachability to be known.
+	mu sync.Mutex
+	// unreachable is set when the request in flight failed with a network error or a server error.
+	unreachable atomic.Bool
+	// full is set when the request in flight fetches every record, only its response is saved.
+	full bool
+	// saved is set once this process saved the response of a request fetching every record. It is
+	// cleared when Keeper can't be reached, so the copy is refreshed once it can again.
+	saved bool
+}
+
+var _ ksm.ICache = (*offlineCache)(nil)
+
+// offlineCopy is the content of the offline cache file.
+type offlineCopy struct {
+	SavedAt time.Time `json:"saved_at"`
+	Data    []byte    `json:"data"`
+}
+
+// newOfflineCache opens the offline copy of the KSM application in the config.
+func newOfflineCache(config ksm.IKeyValueStorage, settings offlineSettings) (*offlineCache, error) {
+	aead, err := newAppKeyCipher(config, offlineKeyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(settings.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create offline_dir: %w", err)
+	}
+
+	return &offlineCache{
+		path:         filepath.Join(settings.Dir, appFileName(config)+".offline"),
+		aead:         aead,
+		maxStaleness: settings.MaxStaleness,
+	}, nil
+}
+
+// Request sends a request for records to Keeper with fetch, which must go through the transport
+// returned by Transport. fetch is told to fetch every record shared with the application when all is
+// set or until the offline copy was saved in this build, only those responses are saved as the
+// offline copy. Other requests only fetch the records they need.
+func (c *offlineCache) Request(all bool, fetch func(all bool) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unreachable.Store(false)
+	c.full = all || !c.savedThisBuild()
+
+	err := fetch(c.full)
+	if c.unreachable.Load() {
+		c.saved = false
+		if err := os.Remove(c.path + ".saved"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] Unable to remove the Keeper offline copy marker %s: %s", c.path+".saved", err)
+		}
+	}
+
+	return err
+}
+
+// savedThisBuild reports whether the offline copy was saved during this build. Packer starts a plugin
+// process for each datasource, so the process saving the copy writes the run id Packer gives every
+// process of the build to a marker file. Without a run id (ex: an older Packer) each process saves
+// the copy once.
+func (c *offlineCache) savedThisBuild() bool {
+	if c.saved {
+		return true
+	}
+
+	build := os.Getenv(PACKER_RUN_UUID_ENV_KEY)
+	if build == "" {
+		return false
+	}
+
+	content, err := os.ReadFile(c.path + ".saved")
+	return err == nil && strings.TrimSpace(string(content)) == build
+}
+
+// SaveCachedValue stores the last successful Keeper response when it holds every record.
+func (c *offlineCache) SaveCachedValue(data []byte) error {
+	if !c.full {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(offlineCopy{SavedAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	// Keeper ignores the error, so log it to explain why the fallback isn't available later.
+	if err := writeFileAtomic(c.path, c.aead.Seal(nonce, nonce, plaintext, nil)); err != nil {
+		log.Printf("[WARN] Unable to save the Keeper offline copy %s: %s", c.path, err)
+		return err
+	}
+
+	c.saved = true
+	if build := os.Getenv(PACKER_RUN_UUID_ENV_KEY); build != "" {
+		if err := os.WriteFile(c.path+".saved", []byte(build+"\n"), 0600); err != nil {
+			log.Printf("[WARN] Unable to write the Keeper offline copy marker %s: %s", c.path+".saved", err)
+		}
+	}
+
+	return nil
+}
+
+// GetCachedValue returns the last successful Keeper response. Keeper reads it when a request fails,
+// the copy is only returned when the failure came from the network or a server error, and a warning
+// is logged whenever it is used. Otherwise, or when the copy is older than the maximum staleness,
+// nothing is returned, which makes Keeper return the original error.
+func (c *offlineCache) GetCachedValue() ([]byte, error) {
+	if !c.unreachable.Load() {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(c.path)
+	if err != nil {
+		log.Printf("[WARN] Keeper is unreachable and there is no offline copy of its records at %s", c.path)
+		return nil, nil
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(content) < nonceSize {
+		log.Printf("[WARN] Keeper is unreachable and the offline copy %s is malformed", c.path)
+		return nil, nil
+	}
+
+	plaintext, err := c.aead.Open(nil, content[:nonceSize], content[nonceSize:], nil)
+	if err != nil {
+		log.Printf("[WARN] Keeper is unreachable and the offline copy %s can't be decrypted with the current KSM config", c.path)
+		return nil, nil
+	}
+
+	var copy offlineCopy
+	if err := json.Unmarshal(plaintext, &copy); err != nil {
+		log.Printf("[WARN] Keeper is unreachable and the offline copy %s is malformed", c.path)
+		return nil, nil
+	}
+
+	age := time.Since(copy.SavedAt)
+	if age > c.maxStaleness {
+		log.Printf("[WARN] Keeper is unreachable and the offline copy of its records is %s old, more than offline_max_staleness (%s)", age.Round(time.Second), c.maxStaleness)
+		return nil, nil
+	}
+
+	log.Printf("[WARN] ************************************************************************")
+	log.Printf("[WARN] Keeper is unreachable, using the offline copy of its records saved %s ago (%s).", age.Round(time.Second), copy.SavedAt.Format(time.RFC3339))
+	log.Printf("[WARN] Secrets may be out of date until Keeper can be reached again.")
+	log.Printf("[WARN] ************************************************************************")
+
+	return copy.Data, nil
+}
+
+// Purge removes the offline copy.
+func (c *offlineCache) Purge() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Transport wraps the transport Keeper requests are sent through to record whether Keeper could be
+// reached. When base is nil the default HTTP transport is used.
+func (c *offlineCache) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &reachabilityTransport{base: base, cache: c}
+}
+
+// reachabilityTransport records on the offline cache whether the request in flight reached Keeper.
+// Network errors, timeouts and server errors left after retries count as unreachable, any other
+// response (ex: access denied) means Keeper answered.
+type reachabilityTransport struct {
+	base  http.RoundTripper
+	cache *offlineCache
+}
+
+func (t *reachabilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	t.cache.unreachable.Store(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
+
+// newOfflineFallback returns the offline copy of the KSM application in the config, or nil when the
+// offline fallback is disabled.
+func newOfflineFallback(config ksm.IKeyValueStorage, settings offlineSettings) (*offlineCache, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	return newOfflineCache(config, settings)
+}
+
+// enableOfflineFallback makes the client fall back to the offline copy when Keeper can't be reached.
+// Only clients talking to Keeper directly support the fallback, other clients are returned as is.
+// The client must send its requests through the transport returned by cache.Transport.
+func enableOfflineFallback(client KeeperClient, cache *offlineCache) {
+	ksmClient, ok := client.(*KSMClient)
+	if cache == nil || !ok {
+		return
+	}
+
+	ksmClient.SetOfflineCache(cache)
+}
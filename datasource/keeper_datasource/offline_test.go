@@ -0,0 +1,348 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineTransport answers Keeper requests with the records they ask for, every record when they ask for none,
+// encrypted for the app key. It fails when down is set or the request asks for the record in downFor, or answers
+// with the error status and body when status is set. The records each request asked for are kept in requested.
+type offlineTransport struct {
+	ctx       **ksm.Context
+	appKey    []byte
+	appTitle  string
+	records   map[string]string
+	down      atomic.Bool
+	downFor   string
+	status    int
+	body      string
+	mu        sync.Mutex
+	requested [][]string
+}
+
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+
+	// Keeper sets the transmission key of the request on the context before sending it.
+	transmissionKey := (*t.ctx).TransmissionKey.Key
+
+	encrypted, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := ksm.Decrypt(encrypted, transmissionKey)
+	if err != nil {
+		return nil, err
+	}
+	var query ksm.GetPayload
+	if err := json.Unmarshal(payload, &query); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.requested = append(t.requested, query.RequestedRecords)
+	t.mu.Unlock()
+
+	if t.downFor != "" && slices.Contains(query.RequestedRecords, t.downFor) {
+		return nil, errors.New("connection refused")
+	}
+
+	if t.status != 0 {
+		return &http.Response{
+			StatusCode: t.status,
+			Status:     http.StatusText(t.status),
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader([]byte(t.body))),
+			Request:    req,
+		}, nil
+	}
+
+	records := []map[string]string{}
+	for uid, title := range t.records {
+		if len(query.RequestedRecords) > 0 && !slices.Contains(query.RequestedRecords, uid) {
+			continue
+		}
+
+		recordKey, err := ksm.GenerateRandomBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		encryptedKey, err := ksm.EncryptAesGcm(recordKey, t.appKey)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ksm.EncryptAesGcm([]byte(`{"title": "`+title+`", "type": "login", "fields": []}`), recordKey)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, map[string]string{
+			"recordUid": uid,
+			"recordKey": ksm.BytesToBase64(encryptedKey),
+			"data":      ksm.BytesToBase64(data),
+		})
+	}
+
+	content := map[string]interface{}{"records": records}
+	if t.appTitle != "" {
+		appData, err := ksm.EncryptAesGcm([]byte(`{"title": "`+t.appTitle+`", "type": "general"}`), t.appKey)
+		if err != nil {
+			return nil, err
+		}
+		content["appData"] = base64.RawURLEncoding.EncodeToString(appData)
+	}
+
+	response, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ksm.EncryptAesGcm(response, transmissionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// newOfflineTestClient returns a Keeper client with the offline fallback stored in dir, talking to Keeper through the transport.
+func newOfflineTestClient(t *testing.T, dir string) (*KSMClient, *offlineTransport, *offlineCache) {
+	return newOfflineTestClientWithConfig(t, dir, generateTestConfig(t))
+}
+
+// newOfflineTestClientWithConfig returns a client like newOfflineTestClient for the KSM config, so clients of
+// different plugin processes can share the offline copy.
+func newOfflineTestClientWithConfig(t *testing.T, dir string, config string) (*KSMClient, *offlineTransport, *offlineCache) {
+	storage := ksm.NewMemoryKeyValueStorage(config)
+
+	transport := &offlineTransport{
+		appKey:  ksm.Base64ToBytes(storage.Get(ksm.KEY_APP_KEY)),
+		records: map[string]string{"uid-a": "Record A", "uid-b": "Record B"},
+	}
+
+	cache, err := newOfflineCache(storage, offlineSettings{Enabled: true, Dir: dir, MaxStaleness: time.Hour})
+	require.NoError(t, err)
+
+	ctx := &ksm.Context{Transport: cache.Transport(transport)}
+	transport.ctx = &ctx
+
+	sm := ksm.NewSecretsManager(&ksm.ClientOptions{Config: storage}, &ctx)
+	require.NotNil(t, sm)
+
+	client := &KSMClient{KeeperClient: sm}
+	client.SetOfflineCache(cache)
+	return client, transport, cache
+}
+
+// TestOfflineFallback tests that records are served from the last response while Keeper is unreachable.
+func TestOfflineFallback(t *testing.T) {
+	client, transport, cache := newOfflineTestClient(t, t.TempDir())
+
+	record, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+	assert.Equal(t, "Record A", record.Title())
+
+	transport.down.Store(true)
+
+	// Every record was fetched, so records that weren't requested yet are available offline.
+	records, err := client.GetSecrets([]string{"uid-b"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "Record B", records[0].Title())
+
+	_, err = client.GetSecret("missing")
+	require.Error(t, err)
+
+	// Once the copy is too old Keeper's error is returned.
+	cache.maxStaleness = time.Nanosecond
+	_, err = client.GetSecret("uid-a")
+	require.ErrorContains(t, err, "connection refused")
+
+	// The copy is updated when Keeper is reachable again.
+	transport.down.Store(false)
+	transport.records["uid-c"] = "Record C"
+	_, err = client.GetSecret("uid-c")
+	require.NoError(t, err)
+
+	transport.down.Store(true)
+	cache.maxStaleness = time.Hour
+	record, err = client.GetSecret("uid-c")
+	require.NoError(t, err)
+	assert.Equal(t, "Record C", record.Title())
+}
+
+// TestOfflineFallbackRequests tests that only the requests saving the offline copy fetch every record, and that
+// concurrent requests each fall back to the copy depending on whether they reached Keeper.
+func TestOfflineFallbackRequests(t *testing.T) {
+	client, transport, _ := newOfflineTestClient(t, t.TempDir())
+
+	_, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+	_, err = client.GetSecrets([]string{"uid-a", "uid-b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{nil, {"uid-a", "uid-b"}}, transport.requested)
+
+	// Requests for uid-b fail while requests for uid-a reach Keeper.
+	transport.downFor = "uid-b"
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		uid := []string{"uid-a", "uid-b"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record, err := client.GetSecret(uid)
+			if assert.NoError(t, err, uid) {
+				assert.Equal(t, uid, record.Uid)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Keeper couldn't be reached, so the next request refreshes the copy.
+	transport.down.Store(true)
+	record, err := client.GetSecret("uid-b")
+	require.NoError(t, err)
+	assert.Equal(t, "Record B", record.Title())
+
+	transport.down.Store(false)
+	transport.downFor = ""
+	transport.requested = nil
+	_, err = client.GetSecret("uid-a")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{nil}, transport.requested)
+}
+
+// TestOfflineFallbackOncePerBuild tests that only the first plugin process of a Packer run fetches every record to
+// save the offline copy, and that every process does when Packer doesn't give the run an id.
+func TestOfflineFallbackOncePerBuild(t *testing.T) {
+	dir := t.TempDir()
+	config := generateTestConfig(t)
+	t.Setenv(PACKER_RUN_UUID_ENV_KEY, "run-1")
+
+	requested := func() [][]string {
+		client, transport, _ := newOfflineTestClientWithConfig(t, dir, config)
+		_, err := client.GetSecret("uid-a")
+		require.NoError(t, err)
+		return transport.requested
+	}
+
+	assert.Equal(t, [][]string{nil}, requested())
+	assert.Equal(t, [][]string{{"uid-a"}}, requested())
+
+	// The next build saves the copy again.
+	t.Setenv(PACKER_RUN_UUID_ENV_KEY, "run-2")
+	assert.Equal(t, [][]string{nil}, requested())
+	assert.Equal(t, [][]string{{"uid-a"}}, requested())
+
+	// Once Keeper couldn't be reached the next process refreshes the copy.
+	client, transport, _ := newOfflineTestClientWithConfig(t, dir, config)
+	transport.down.Store(true)
+	_, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{nil}, requested())
+
+	os.Unsetenv(PACKER_RUN_UUID_ENV_KEY)
+	assert.Equal(t, [][]string{nil}, requested())
+	assert.Equal(t, [][]string{nil}, requested())
+}
+
+// TestOfflineFallbackRejected tests that the offline copy is only served when Keeper can't be reached,
+// not when Keeper rejects the credentials.
+func TestOfflineFallbackRejected(t *testing.T) {
+	client, transport, _ := newOfflineTestClient(t, t.TempDir())
+
+	_, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+
+	// Server errors left after retries mean Keeper is unavailable.
+	transport.status = http.StatusServiceUnavailable
+	record, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+	assert.Equal(t, "Record A", record.Title())
+
+	// The application was removed or its access revoked.
+	transport.status = http.StatusForbidden
+	transport.body = `{"result_code": "access_denied", "message": "Access denied"}`
+	_, err = client.GetSecret("uid-a")
+	require.ErrorContains(t, err, "access_denied")
+
+	// The client id is unknown to Keeper.
+	transport.status = http.StatusUnauthorized
+	transport.body = `{"result_code": "invalid_client", "message": "Client ID is invalid"}`
+	_, err = client.GetSecret("uid-a")
+	require.ErrorContains(t, err, "invalid_client")
+}
+
+// TestOfflineCopyIsEncrypted tests that the offline copy can't be read without the app key it was written with.
+func TestOfflineCopyIsEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	client, transport, cache := newOfflineTestClient(t, dir)
+
+	_, err := client.GetSecret("uid-a")
+	require.NoError(t, err)
+
+	info, err := os.Stat(cache.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	content, err := os.ReadFile(cache.path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "uid-a")
+
+	// The copy of another application isn't used.
+	other, otherTransport, otherCache := newOfflineTestClient(t, dir)
+	require.NoError(t, os.Rename(cache.path, otherCache.path))
+	transport.down.Store(true)
+	otherTransport.down.Store(true)
+
+	_, err = other.GetSecret("uid-a")
+	require.ErrorContains(t, err, "connection refused")
+}
+
+// TestOfflineSettings tests that offline settings fall back to the environment and are validated.
+func TestOfflineSettings(t *testing.T) {
+	t.Setenv(KEEPER_OFFLINE_FALLBACK_ENV_KEY, "true")
+	t.Setenv(KEEPER_OFFLINE_DIR_ENV_KEY, "/tmp/keeper-offline")
+	t.Setenv(KEEPER_OFFLINE_MAX_STALENESS_ENV_KEY, "2h")
+
+	settings, err := getOfflineSettings(ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, offlineSettings{Enabled: true, Dir: "/tmp/keeper-offline", MaxStaleness: 2 * time.Hour}, settings)
+
+	settings, err = getOfflineSettings(ClientConfig{OfflineDir: "offline", OfflineMaxStaleness: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, offlineSettings{Enabled: true, Dir: "offline", MaxStaleness: time.Minute}, settings)
+
+	t.Setenv(KEEPER_OFFLINE_DIR_ENV_KEY, "")
+	t.Setenv(KEEPER_OFFLINE_MAX_STALENESS_ENV_KEY, "")
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	settings, err = getOfflineSettings(ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, offlineSettings{Enabled: true, Dir: filepath.Join("/tmp/cache", "packer-plugin-keeper", "offline"), MaxStaleness: defaultOfflineMaxStaleness}, settings)
+
+	err = ValidateClientConfig(ClientConfig{OfflineMaxStaleness: -time.Minute})
+	require.ErrorIs(t, err, ErrInvalidOfflineMaxStaleness)
+}
@@ -0,0 +1,250 @@
+package keeper_datasource
+
+import (
+	"fmt"
+	"slices"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// GetPamMachine retrieves a PAM machine record from Keeper
+func (k *KSMClient) GetPamMachine(record *ksm.Record) (*KeeperPamMachine, error) {
+	host, err := getPamHost(record)
+	if err != nil {
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	// Extract the machine from the record, linked users are resolved by the caller
+	return &KeeperPamMachine{
+		KeeperRecordField: *getRecordFields(record),
+		HostConnection:    host,
+		OperatingSystem:   getLabeledFieldValue(record, "text", "operatingSystem"),
+		SSLVerification:   getCheckbox(record, "sslVerification"),
+		ProviderGroup:     getLabeledFieldValue(record, "text", "providerGroup"),
+		ProviderRegion:    getLabeledFieldValue(record, "text", "providerRegion"),
+		RotationSchedule:  getRotationSchedule(record),
+		Users:             []PamUser{},
+	}, nil
+}
+
+// GetPamDatabase retrieves a PAM database record from Keeper
+func (k *KSMClient) GetPamDatabase(record *ksm.Record) (*KeeperPamDatabase, error) {
+	host, err := getPamHost(record)
+	if err != nil {
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	// Extract the database from the record, linked users are resolved by the caller
+	return &KeeperPamDatabase{
+		KeeperRecordField: *getRecordFields(record),
+		HostConnection:    host,
+		DatabaseType:      getFieldValue(record, "databaseType"),
+		ConnectDatabase:   getLabeledFieldValue(record, "text", "connectDatabase"),
+		SSLVerification:   getCheckbox(record, "useSSL"),
+		ProviderGroup:     getLabeledFieldValue(record, "text", "providerGroup"),
+		ProviderRegion:    getLabeledFieldValue(record, "text", "providerRegion"),
+		RotationSchedule:  getRotationSchedule(record),
+		Users:             []PamUser{},
+	}, nil
+}
+
+// GetPamDirectory retrieves a PAM directory record from Keeper
+func (k *KSMClient) GetPamDirectory(record *ksm.Record) (*KeeperPamDirectory, error) {
+	host, err := getPamHost(record)
+	if err != nil {
+		return nil, fmt.Errorf("record %s: %w", record.Uid, err)
+	}
+
+	// Extract the directory from the record, linked users are resolved by the caller
+	return &KeeperPamDirectory{
+		KeeperRecordField: *getRecordFields(record),
+		HostConnection:    host,
+		DirectoryType:     getFieldValue(record, "directoryType"),
+		DomainName:        getLabeledFieldValue(record, "text", "domainName"),
+		SSLVerification:   getCheckbox(record, "useSSL"),
+		ProviderGroup:     getLabeledFieldValue(record, "text", "providerGroup"),
+		ProviderRegion:    getLabeledFieldValue(record, "text", "providerRegion"),
+		RotationSchedule:  getRotationSchedule(record),
+		Users:             []PamUser{},
+	}, nil
+}
+
+// GetPamUser retrieves a PAM user record from Keeper
+func (k *KSMClient) GetPamUser(record *ksm.Record) (*KeeperPamUser, error) {
+	// Extract the user from the record
+	return &KeeperPamUser{
+		KeeperRecordField: *getRecordFields(record),
+		Login:             getFieldValue(record, LOGIN_FIELD_TYPE),
+		Password:          getFieldValue(record, PASSWORD_FIELD_TYPE),
+		PrivateKey:        getLabeledFieldValue(record, "secret", "privatePEMKey"),
+		DistinguishedName: getLabeledFieldValue(record, "text", "distinguishedName"),
+		ConnectDatabase:   getLabeledFieldValue(record, "text", "connectDatabase"),
+		Managed:           getCheckbox(record, "managed"),
+		RotationSchedule:  getRotationSchedule(record),
+	}, nil
+}
+
+// pamUserSecrets returns the logins, passwords and private keys of the users.
+func pamUserSecrets(users []PamUser) []string {
+	secrets := []string{}
+	for _, u := range users {
+		secrets = append(secrets, u.Login, u.Password, u.PrivateKey)
+	}
+
+	return secrets
+}
+
+// SecretValues returns the logins, passwords and private keys of the users linked to the machine.
+func (m *KeeperPamMachine) SecretValues() []string {
+	return pamUserSecrets(m.Users)
+}
+
+// SecretValues returns the logins, passwords and private keys of the users linked to the database.
+func (d *KeeperPamDatabase) SecretValues() []string {
+	return pamUserSecrets(d.Users)
+}
+
+// SecretValues returns the logins, passwords and private keys of the users linked to the directory.
+func (d *KeeperPamDirectory) SecretValues() []string {
+	return pamUserSecrets(d.Users)
+}
+
+// getPamHost returns the host of the pamHostname field, the hostname of PAM resources isn't a
+// multi-value field so it isn't selected by field_index.
+func getPamHost(r *ksm.Record) (HostConnection, error) {
+	entries := getFieldEntries(r, "pamHostname")
+	if len(entries) == 0 {
+		return HostConnection{}, nil
+	}
+
+	if _, ok := entries[0].value.(map[string]interface{}); !ok {
+		return HostConnection{}, fmt.Errorf("%w: pamHostname value isn't an object", ErrMalformedField)
+	}
+
+	return parseHost(entries[0].value), nil
+}
+
+// getLabeledFieldValue returns the values of the first standard or custom field of the type with the label.
+func getLabeledFieldValue(r *ksm.Record, fieldType string, label string) string {
+	for _, f := range r.GetFieldsByMask(fieldType, ksm.FieldTokenType, ksm.FieldSectionFields|ksm.FieldSectionCustom) {
+		if l, _ := f["label"].(string); l == label {
+			return formatFieldValues(f)
+		}
+	}
+
+	return ""
+}
+
+// getCheckbox returns whether the first checkbox field with the label is checked.
+func getCheckbox(r *ksm.Record, label string) bool {
+	for _, f := range r.GetFieldsByMask("checkbox", ksm.FieldTokenType, ksm.FieldSectionFields|ksm.FieldSectionCustom) {
+		if l, _ := f["label"].(string); l != label {
+			continue
+		}
+
+		values, _ := f["value"].([]interface{})
+		if len(values) == 0 {
+			return false
+		}
+		checked, _ := values[0].(bool)
+		return checked
+	}
+
+	return false
+}
+
+// getRotationSchedule extracts the first schedule field value of the record
+func getRotationSchedule(r *ksm.Record) RotationSchedule {
+	schedule := RotationSchedule{}
+
+	entries := getFieldEntries(r, "schedule")
+	if len(entries) == 0 {
+		return schedule
+	}
+
+	// Make sure the value is a map
+	valuesMap, ok := entries[0].value.(map[string]interface{})
+	if !ok {
+		return schedule
+	}
+
+	schedule.Type, _ = valuesMap["type"].(string)
+	schedule.Cron, _ = valuesMap["cron"].(string)
+	schedule.Time, _ = valuesMap["time"].(string)
+	schedule.Timezone, _ = valuesMap["tz"].(string)
+	schedule.Weekday, _ = valuesMap["weekday"].(string)
+	if count, ok := valuesMap["intervalCount"].(float64); ok {
+		schedule.IntervalCount = int(count)
+	}
+
+	// Older records store the time in utcTime instead of time and tz
+	if utcTime, ok := valuesMap["utcTime"].(string); ok && schedule.Time == "" {
+		schedule.Time = utcTime
+		schedule.Timezone = "UTC"
+	}
+
+	return schedule
+}
+
+// getLinkedRecordUids returns the uids of the records linked to a PAM resource, from its resources
+// and the users of its connections, without duplicates.
+func getLinkedRecordUids(r *ksm.Record) []string {
+	uids := []string{}
+	add := func(values interface{}) {
+		list, _ := values.([]interface{})
+		for _, v := range list {
+			if uid, ok := v.(string); ok && uid != "" && !slices.Contains(uids, uid) {
+				uids = append(uids, uid)
+			}
+		}
+	}
+
+	for _, e := range getFieldEntries(r, "pamResources") {
+		if resource, ok := e.value.(map[string]interface{}); ok {
+			add(resource["resourceRef"])
+		}
+	}
+
+	for _, e := range getFieldEntries(r, "pamSettings") {
+		settings, _ := e.value.(map[string]interface{})
+		connections, _ := settings["connection"].([]interface{})
+		for _, c := range connections {
+			if connection, ok := c.(map[string]interface{}); ok {
+				add(connection["userRecords"])
+			}
+		}
+	}
+
+	return uids
+}
+
+// getLinkedUsers returns the credentials of the PAM users linked to the record. Linked records that
+// aren't shared with the KSM application, or aren't PAM users, are left out.
+func (c *PackerKeeperClient) getLinkedUsers(r *ksm.Record) ([]PamUser, error) {
+	uids := getLinkedRecordUids(r)
+	if len(uids) == 0 {
+		return []PamUser{}, nil
+	}
+
+	records, err := c.fetcher.GetShared(uids...)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []PamUser{}
+	for _, linked := range records {
+		if linked.Type() != PAM_USER_FIELD_TYPE {
+			continue
+		}
+
+		users = append(users, PamUser{
+			Uid:        linked.Uid,
+			Title:      linked.Title(),
+			Login:      getFieldValue(linked, LOGIN_FIELD_TYPE),
+			Password:   getFieldValue(linked, PASSWORD_FIELD_TYPE),
+			PrivateKey: getLabeledFieldValue(linked, "secret", "privatePEMKey"),
+		})
+	}
+
+	return users, nil
+}
@@ -0,0 +1,112 @@
+package keeper_datasource
+
+import (
+	"encoding/json"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// secretFieldTypes are the Keeper field types holding sensitive values. Values of these
+// fields are registered with the Packer secret filter by datasources that read any field.
+var secretFieldTypes = map[string]bool{
+	"password":         true,
+	"secret":           true,
+	"note":             true,
+	"pinCode":          true,
+	"oneTimeCode":      true,
+	"otp":              true,
+	"keyPair":          true,
+	"licenseNumber":    true,
+	"securityQuestion": true,
+	"paymentCard":      true,
+	"bankAccount":      true,
+}
+
+// IsSecretFieldType reports whether values of the Keeper field type are sensitive.
+func IsSecretFieldType(fieldType string) bool {
+	return secretFieldTypes[fieldType]
+}
+
+// GetGenericRecord retrieves any record type from Keeper, including every standard and custom field
+func (k *KSMClient) GetGenericRecord(r *ksm.Record) (*KeeperRecord, error) {
+	fields := getAllRecordFields(r)
+
+	fieldMap := map[string]string{}
+	for _, f := range fields {
+		key := f.Label
+		if key == "" {
+			key = f.Type
+		}
+
+		if _, ok := fieldMap[key]; ok || len(f.Values) == 0 {
+			continue
+		}
+		fieldMap[key] = f.Values[0]
+	}
+
+	return &KeeperRecord{
+		KeeperRecordField: *getRecordFields(r),
+		Fields:            fields,
+		FieldMap:          fieldMap,
+	}, nil
+}
+
+// SecretValues returns the values of the record fields holding sensitive values, along with
+// the string properties of values that are objects (ex: the private key of a key pair).
+func (r *KeeperRecord) SecretValues() []string {
+	secrets := []string{}
+	for _, f := range r.Fields {
+		if IsSecretFieldType(f.Type) {
+			secrets = append(secrets, secretValues(f.Values)...)
+		}
+	}
+
+	return secrets
+}
+
+// getAllRecordFields extracts the standard and custom fields from a Keeper record
+func getAllRecordFields(r *ksm.Record) []RecordField {
+	fields := []RecordField{}
+	for _, section := range []ksm.FieldSectionFlag{ksm.FieldSectionFields, ksm.FieldSectionCustom} {
+		for _, f := range r.GetFieldsBySection(section) {
+			fieldMap, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			field := RecordField{
+				Custom: section == ksm.FieldSectionCustom,
+				Values: []string{},
+			}
+			field.Type, _ = fieldMap["type"].(string)
+			field.Label, _ = fieldMap["label"].(string)
+
+			values, _ := fieldMap["value"].([]interface{})
+			for _, v := range values {
+				value, err := fieldValueString(v)
+				if err != nil {
+					continue
+				}
+				field.Values = append(field.Values, value)
+			}
+
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// fieldValueString converts a field value to a string, JSON encoding anything that isn't a string.
+func fieldValueString(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,410 @@
+package keeper_datasource
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Environment variables controlling the record cache.
+const (
+	KEEPER_CACHE_DIR_ENV_KEY    = "KEEPER_CACHE_DIR"
+	KEEPER_CACHE_TTL_ENV_KEY    = "KEEPER_CACHE_TTL"
+	KEEPER_CACHE_BYPASS_ENV_KEY = "KEEPER_CACHE_BYPASS"
+	KEEPER_CACHE_FLUSH_ENV_KEY  = "KEEPER_CACHE_FLUSH"
+)
+
+// PACKER_RUN_UUID_ENV_KEY is the environment variable Packer sets to an id unique to each run,
+// shared by every plugin process it starts.
+const PACKER_RUN_UUID_ENV_KEY = "PACKER_RUN_UUID"
+
+// defaultCacheTTL is how long cached records are used when no TTL is configured.
+const defaultCacheTTL = 5 * time.Minute
+
+// cacheKeyInfo binds the cache key derived from the KSM app key to the record cache.
+const cacheKeyInfo = "packer-plugin-keeper record cache"
+
+// Errors for handling the record cache.
+var (
+	ErrInvalidCacheTTL = errors.New("cache_ttl can't be negative")
+	ErrNoCacheKey      = errors.New("the KSM config has no app key to encrypt cached records with")
+)
+
+// cacheSettings controls the on-disk record cache. The cache is disabled when Dir is empty.
+type cacheSettings struct {
+	Dir    string
+	TTL    time.Duration
+	Bypass bool
+	Flush  bool
+}
+
+// getCacheSettings returns the record cache settings for the client config, preferring the HCL
+// config over the environment.
+func getCacheSettings(c ClientConfig) (cacheSettings, error) {
+	settings := cacheSettings{
+		Dir:    c.CacheDir,
+		TTL:    c.CacheTTL,
+		Bypass: c.CacheBypass || envBool(KEEPER_CACHE_BYPASS_ENV_KEY),
+		Flush:  envBool(KEEPER_CACHE_FLUSH_ENV_KEY),
+	}
+
+	if settings.Dir == "" {
+		settings.Dir = os.Getenv(KEEPER_CACHE_DIR_ENV_KEY)
+	}
+
+	if settings.TTL == 0 {
+		if env := os.Getenv(KEEPER_CACHE_TTL_ENV_KEY); env != "" {
+			ttl, err := time.ParseDuration(env)
+			if err != nil {
+				return cacheSettings{}, fmt.Errorf("invalid duration in %s: %w", KEEPER_CACHE_TTL_ENV_KEY, err)
+			}
+			settings.TTL = ttl
+		}
+	}
+
+	if settings.TTL < 0 {
+		return cacheSettings{}, ErrInvalidCacheTTL
+	}
+
+	if settings.TTL == 0 {
+		settings.TTL = defaultCacheTTL
+	}
+
+	return settings, nil
+}
+
+// envBool reports whether the environment variable is set to a true value.
+func envBool(key string) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	return err == nil && v
+}
+
+// recordCache is an encrypted file holding the records of a single KSM application, shared by
+// every plugin process using that application. The file is encrypted with AES-256-GCM using a
+// key derived from the app key, so only clients holding the KSM config can read it.
+type recordCache struct {
+	path string
+	aead cipher.AEAD
+	ttl  time.Duration
+}
+
+// cacheEntry is a cached record, with enough of the record to rebuild it without Keeper.
+type cacheEntry struct {
+	FetchedAt      time.Time `json:"fetched_at"`
+	Uid            string    `json:"uid"`
+	FolderUid      string    `json:"folder_uid,omitempty"`
+	InnerFolderUid string    `json:"inner_folder_uid,omitempty"`
+	Revision       int64     `json:"revision"`
+	IsEditable     bool      `json:"is_editable"`
+	RecordKey      []byte    `json:"record_key"`
+	Data           string    `json:"data"`
+}
+
+// newRecordCache opens the cache of the KSM application in the config. The cache file is
+// named after the client id and encrypted with a key derived from the app key.
+func newRecordCache(config ksm.IKeyValueStorage, settings cacheSettings) (*recordCache, error) {
+	aead, err := newAppKeyCipher(config, cacheKeyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(settings.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create cache_dir: %w", err)
+	}
+
+	return &recordCache{
+		path: filepath.Join(settings.Dir, appFileName(config)+".cache"),
+		aead: aead,
+		ttl:  settings.TTL,
+	}, nil
+}
+
+// newAppKeyCipher returns an AES-256-GCM cipher with a key derived from the app key of the KSM
+// config. info binds the key to its use, so files written for one purpose can't be read as another.
+func newAppKeyCipher(config ksm.IKeyValueStorage, info string) (cipher.AEAD, error) {
+	appKey, err := base64.StdEncoding.DecodeString(config.Get(ksm.KEY_APP_KEY))
+	if err != nil || len(appKey) == 0 {
+		return nil, ErrNoCacheKey
+	}
+
+	key, err := hkdf.Key(sha256.New, appKey, nil, info, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// appFileName returns a file name identifying the KSM application of the config without revealing its client id.
+func appFileName(config ksm.IKeyValueStorage) string {
+	clientId := sha256.Sum256([]byte(config.Get(ksm.KEY_CLIENT_ID)))
+	return hex.EncodeToString(clientId[:8])
+}
+
+// Get returns the cached records for the uids that haven't expired.
+func (c *recordCache) Get(uids []string) (map[string]*ksm.Record, error) {
+	unlock, err := acquireFileLock(c.path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	entries := c.read()
+	unlock()
+
+	records := map[string]*ksm.Record{}
+	for _, uid := range uids {
+		entry, ok := entries[uid]
+		if !ok || time.Since(entry.FetchedAt) > c.ttl {
+			continue
+		}
+
+		records[uid] = entry.record()
+	}
+
+	return records, nil
+}
+
+// Put adds the records to the cache and drops expired entries. Records with file attachments
+// aren't cached as their download URLs expire.
+func (c *recordCache) Put(records []*ksm.Record) error {
+	unlock, err := acquireFileLock(c.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries := c.read()
+	for uid, entry := range entries {
+		if time.Since(entry.FetchedAt) > c.ttl {
+			delete(entries, uid)
+		}
+	}
+
+	now := time.Now()
+	for _, r := range records {
+		if len(r.Files) > 0 {
+			continue
+		}
+
+		entries[r.Uid] = &cacheEntry{
+			FetchedAt:      now,
+			Uid:            r.Uid,
+			FolderUid:      r.FolderUid(),
+			InnerFolderUid: r.InnerFolderUid(),
+			Revision:       r.Revision,
+			IsEditable:     r.IsEditable,
+			RecordKey:      r.RecordKeyBytes,
+			Data:           r.RawJson,
+		}
+	}
+
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	return replaceFile(c.path, c.aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+// Flush removes every cached record, once per build. Packer starts a plugin process for each
+// datasource, so the process flushing the cache writes the run id Packer gives every process of the
+// build to a marker file, and later processes of the same build keep the records fetched since.
+// Without a run id (ex: an older Packer) the build can't be told apart, so the cache is flushed by
+// every process.
+func (c *recordCache) Flush() error {
+	unlock, err := acquireFileLock(c.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	marker := c.path + ".flushed"
+	build := os.Getenv(PACKER_RUN_UUID_ENV_KEY)
+	if content, err := os.ReadFile(marker); err == nil && build != "" && strings.TrimSpace(string(content)) == build {
+		log.Printf("[INFO] Keeper record cache %s was already flushed in this build", c.path)
+		return nil
+	}
+
+	log.Printf("[INFO] Flushing Keeper record cache %s", c.path)
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if build == "" {
+		return nil
+	}
+
+	return os.WriteFile(marker, []byte(build+"\n"), 0600)
+}
+
+// read decrypts the cache file. A missing or unreadable cache is treated as empty, so a
+// corrupted file or a rotated app key only costs a fetch from Keeper.
+func (c *recordCache) read() map[string]*cacheEntry {
+	entries := map[string]*cacheEntry{}
+
+	content, err := os.ReadFile(c.path)
+	if err != nil {
+		return entries
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(content) < nonceSize {
+		log.Printf("[WARN] Ignoring malformed Keeper record cache %s", c.path)
+		return entries
+	}
+
+	plaintext, err := c.aead.Open(nil, content[:nonceSize], content[nonceSize:], nil)
+	if err != nil {
+		log.Printf("[WARN] Ignoring Keeper record cache %s, it can't be decrypted with the current KSM config", c.path)
+		return entries
+	}
+
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		log.Printf("[WARN] Ignoring malformed Keeper record cache %s", c.path)
+		return map[string]*cacheEntry{}
+	}
+
+	return entries
+}
+
+// record rebuilds the Keeper record from the cache entry.
+func (e *cacheEntry) record() *ksm.Record {
+	// The folder uid can only be set through the Keeper constructor.
+	r := ksm.NewRecordFromJson(map[string]interface{}{
+		"recordUid":      e.Uid,
+		"innerFolderUid": e.InnerFolderUid,
+		"revision":       float64(e.Revision),
+		"isEditable":     e.IsEditable,
+	}, nil, e.FolderUid)
+
+	r.RecordKeyBytes = e.RecordKey
+	r.RawJson = e.Data
+	r.RecordDict = ksm.JsonToDict(e.Data)
+	return r
+}
+
+// cachedKeeperClient is a KeeperClient reading records from the record cache before Keeper.
+type cachedKeeperClient struct {
+	KeeperClient
+	cache  *recordCache
+	bypass bool
+}
+
+// newCachedKeeperClient wraps the client with the record cache, the client is returned as is
+// when the cache is disabled.
+func newCachedKeeperClient(client KeeperClient, config ksm.IKeyValueStorage, settings cacheSettings) (KeeperClient, error) {
+	if settings.Dir == "" {
+		return client, nil
+	}
+
+	cache, err := newRecordCache(config, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Flush {
+		if err := cache.Flush(); err != nil {
+			return nil, fmt.Errorf("unable to flush the record cache: %w", err)
+		}
+	}
+
+	return &cachedKeeperClient{KeeperClient: client, cache: cache, bypass: settings.Bypass}, nil
+}
+
+// ApplicationName returns the title of the KSM application when the wrapped client knows it.
+func (c *cachedKeeperClient) ApplicationName() string {
+	if namer, ok := c.KeeperClient.(applicationNamer); ok {
+		return namer.ApplicationName()
+	}
+
+	return ""
+}
+
+// GetSecret retrieves a record by uid from the cache, or Keeper when it isn't cached
+func (c *cachedKeeperClient) GetSecret(uid string) (*ksm.Record, error) {
+	records, err := c.GetSecrets([]string{uid})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w for uid %s", ErrRecordNotFound, uid)
+	}
+
+	return records[0], nil
+}
+
+// GetSecrets retrieves the records cached for the uids and fetches the rest from Keeper in a single request.
+// Records fetched from Keeper are added to the cache, even when the cache is bypassed.
+func (c *cachedKeeperClient) GetSecrets(uids []string) ([]*ksm.Record, error) {
+	cached := map[string]*ksm.Record{}
+	if c.bypass {
+		log.Printf("[INFO] Bypassing Keeper record cache for %s", strings.Join(uids, ", "))
+	} else {
+		var err error
+		cached, err = c.cache.Get(uids)
+		if err != nil {
+			log.Printf("[WARN] Unable to read Keeper record cache: %s", err)
+			cached = map[string]*ksm.Record{}
+		}
+	}
+
+	records := []*ksm.Record{}
+	hits, misses := []string{}, []string{}
+	for _, uid := range uids {
+		if r, ok := cached[uid]; ok {
+			records = append(records, r)
+			hits = append(hits, uid)
+		} else {
+			misses = append(misses, uid)
+		}
+	}
+
+	if len(hits) > 0 {
+		log.Printf("[INFO] Keeper record cache hit for %s", strings.Join(hits, ", "))
+	}
+
+	if len(misses) == 0 {
+		return records, nil
+	}
+
+	if !c.bypass {
+		log.Printf("[INFO] Keeper record cache miss for %s", strings.Join(misses, ", "))
+	}
+
+	fetched, err := c.KeeperClient.GetSecrets(misses)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.Put(fetched); err != nil {
+		log.Printf("[WARN] Unable to write Keeper record cache: %s", err)
+	}
+
+	return append(records, fetched...), nil
+}
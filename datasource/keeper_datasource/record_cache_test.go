@@ -0,0 +1,143 @@
+package keeper_datasource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCachedClient returns a client caching the records of keeperClient in dir, as a separate plugin process would.
+func newTestCachedClient(t *testing.T, keeperClient KeeperClient, config string, settings cacheSettings) KeeperClient {
+	if settings.TTL == 0 {
+		settings.TTL = defaultCacheTTL
+	}
+
+	client, err := newCachedKeeperClient(keeperClient, ksm.NewMemoryKeyValueStorage(config), settings)
+	require.NoError(t, err)
+	return client
+}
+
+// TestRecordCache tests that records are shared through the cache until they expire, are bypassed or flushed.
+func TestRecordCache(t *testing.T) {
+	dir := t.TempDir()
+	config := generateTestConfig(t)
+	keeperClient := &batchKeeperClient{}
+
+	record, err := newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir}).GetSecret("uid-1")
+	require.NoError(t, err)
+	assert.Equal(t, "uid-1", record.Title())
+	require.Len(t, keeperClient.batches, 1)
+
+	// Another process reuses the cached record.
+	record, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir}).GetSecret("uid-1")
+	require.NoError(t, err)
+	assert.Equal(t, "uid-1", record.Uid)
+	assert.Equal(t, "uid-1", record.Title())
+	assert.Equal(t, LOGIN_FIELD_TYPE, record.Type())
+	require.Len(t, keeperClient.batches, 1)
+
+	// Only the uids missing from the cache are fetched.
+	records, err := newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir}).GetSecrets([]string{"uid-1", "uid-2"})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	require.Len(t, keeperClient.batches, 2)
+	assert.Equal(t, []string{"uid-2"}, keeperClient.batches[1])
+
+	_, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Bypass: true}).GetSecret("uid-1")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 3)
+
+	_, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Flush: true}).GetSecret("uid-2")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 4)
+
+	client := newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, TTL: time.Millisecond})
+	time.Sleep(5 * time.Millisecond)
+	_, err = client.GetSecret("uid-2")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 5)
+}
+
+// TestRecordCacheFlushOncePerBuild tests that the cache is only flushed by the first datasource of a Packer run,
+// and by every datasource when Packer doesn't give the run an id.
+func TestRecordCacheFlushOncePerBuild(t *testing.T) {
+	dir := t.TempDir()
+	config := generateTestConfig(t)
+	keeperClient := &batchKeeperClient{}
+	t.Setenv(PACKER_RUN_UUID_ENV_KEY, "run-1")
+
+	_, err := newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Flush: true}).GetSecret("uid-1")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 1)
+
+	// Other datasources of the build reuse the records fetched after the flush.
+	_, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Flush: true}).GetSecret("uid-1")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 1)
+
+	// The next build flushes the cache again.
+	t.Setenv(PACKER_RUN_UUID_ENV_KEY, "run-2")
+	_, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Flush: true}).GetSecret("uid-1")
+	require.NoError(t, err)
+	require.Len(t, keeperClient.batches, 2)
+
+	// Without a run id every datasource flushes the cache.
+	os.Unsetenv(PACKER_RUN_UUID_ENV_KEY)
+	for i := 0; i < 2; i++ {
+		_, err = newTestCachedClient(t, keeperClient, config, cacheSettings{Dir: dir, Flush: true}).GetSecret("uid-1")
+		require.NoError(t, err)
+	}
+	require.Len(t, keeperClient.batches, 4)
+}
+
+// TestRecordCacheIsEncrypted tests that the cache can't be read without the app key it was written with.
+func TestRecordCacheIsEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	keeperClient := &batchKeeperClient{}
+
+	_, err := newTestCachedClient(t, keeperClient, generateTestConfig(t), cacheSettings{Dir: dir}).GetSecret("secret-title")
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.cache"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret-title")
+
+	// A cache written with another app key is ignored.
+	other, err := newRecordCache(ksm.NewMemoryKeyValueStorage(generateTestConfig(t)), cacheSettings{Dir: dir, TTL: defaultCacheTTL})
+	require.NoError(t, err)
+	other.path = files[0]
+
+	records, err := other.Get([]string{"secret-title"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+// TestCacheSettings tests that cache settings fall back to the environment and are validated.
+func TestCacheSettings(t *testing.T) {
+	t.Setenv(KEEPER_CACHE_DIR_ENV_KEY, "/tmp/keeper-cache")
+	t.Setenv(KEEPER_CACHE_TTL_ENV_KEY, "1h")
+
+	settings, err := getCacheSettings(ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, cacheSettings{Dir: "/tmp/keeper-cache", TTL: time.Hour}, settings)
+
+	settings, err = getCacheSettings(ClientConfig{CacheDir: "cache", CacheTTL: time.Minute, CacheBypass: true})
+	require.NoError(t, err)
+	assert.Equal(t, cacheSettings{Dir: "cache", TTL: time.Minute, Bypass: true}, settings)
+
+	err = ValidateClientConfig(ClientConfig{CacheTTL: -time.Minute})
+	require.ErrorIs(t, err, ErrInvalidCacheTTL)
+}
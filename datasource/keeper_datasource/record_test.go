@@ -0,0 +1,52 @@
+package keeper_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetGenericRecord tests that every standard and custom field is returned for a custom record type.
+func TestGetGenericRecord(t *testing.T) {
+	client := getMockedClient(`{
+		"uid": "record-uid",
+		"title": "Replicated database",
+		"type": "myCustomType",
+		"fields": [
+			{"type": "login", "value": ["admin"]},
+			{"type": "password", "value": ["hunter2"]},
+			{"type": "keyPair", "value": [{"publicKey": "ssh-ed25519 AAAA", "privateKey": "PRIVATE KEY"}]},
+			{"type": "text", "value": []}
+		],
+		"custom": [
+			{"type": "text", "label": "Replica Host", "value": ["replica-1", "replica-2"]},
+			{"type": "secret", "label": "Replication Token", "value": ["token"]},
+			{"type": "text", "label": "Replica Host", "value": ["ignored"]}
+		]
+	}`)
+
+	record, err := client.GetGenericRecord(RecordQuery{Uid: "record-uid"})
+	require.NoError(t, err)
+	assert.Equal(t, "myCustomType", record.Type)
+	require.Len(t, record.Fields, 7)
+	assert.Equal(t, RecordField{Type: "keyPair", Values: []string{`{"privateKey":"PRIVATE KEY","publicKey":"ssh-ed25519 AAAA"}`}}, record.Fields[2])
+	assert.Equal(t, RecordField{Type: "text", Label: "Replica Host", Custom: true, Values: []string{"replica-1", "replica-2"}}, record.Fields[4])
+
+	assert.Equal(t, map[string]string{
+		"login":             "admin",
+		"password":          "hunter2",
+		"keyPair":           `{"privateKey":"PRIVATE KEY","publicKey":"ssh-ed25519 AAAA"}`,
+		"Replica Host":      "replica-1",
+		"Replication Token": "token",
+	}, record.FieldMap)
+
+	secrets := record.SecretValues()
+	assert.ElementsMatch(t, []string{
+		"hunter2",
+		`{"privateKey":"PRIVATE KEY","publicKey":"ssh-ed25519 AAAA"}`,
+		"PRIVATE KEY",
+		"ssh-ed25519 AAAA",
+		"token",
+	}, secrets)
+}
@@ -0,0 +1,211 @@
+package keeper_datasource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// DefaultRegistry is the registry used by GetSecretClient. Tests can replace it with
+// a registry built from a custom ClientFactory.
+var DefaultRegistry = NewClientRegistry(DefaultClientFactory)
+
+// fingerprintKeys are the config keys that identify a KSM application. Keys that Keeper
+// rewrites at runtime (ex: the server public key id) are left out so the fingerprint is stable.
+var fingerprintKeys = []ksm.ConfigKey{
+	ksm.KEY_HOSTNAME,
+	ksm.KEY_CLIENT_ID,
+	ksm.KEY_APP_KEY,
+	ksm.KEY_PRIVATE_KEY,
+}
+
+// ClientFactory creates a KeeperClient from resolved and validated KSM client options.
+// transport retries failed requests and wraps a custom HTTP transport when the network settings require one.
+type ClientFactory func(options *ksm.ClientOptions, transport http.RoundTripper) (KeeperClient, error)
+
+// DefaultClientFactory creates a real Keeper Secrets Manager client.
+func DefaultClientFactory(options *ksm.ClientOptions, transport http.RoundTripper) (KeeperClient, error) {
+	return newKSMClient(options, transport)
+}
+
+// ClientRegistry holds one PackerKeeperClient per KSM config. Clients are keyed by a
+// fingerprint of the config content, so datasources that load the same KSM application
+// from different sources still share a client. Clients are also cached by the ClientConfig
+// they were built from, so the options of a config are only resolved (and config_command
+// only run) the first time it is used.
+type ClientRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+	configs map[string]*registryEntry
+	factory ClientFactory
+}
+
+// registryEntry guards the initialization of a single client. Each entry has its own lock
+// so clients for different configs can be initialized concurrently.
+type registryEntry struct {
+	mu     sync.Mutex
+	client *PackerKeeperClient
+}
+
+// NewClientRegistry creates an empty registry that builds clients with the given factory.
+func NewClientRegistry(factory ClientFactory) *ClientRegistry {
+	return &ClientRegistry{
+		entries: map[string]*registryEntry{},
+		configs: map[string]*registryEntry{},
+		factory: factory,
+	}
+}
+
+// Get returns the client for the given config, initializing it on first use.
+// Failed initializations are not cached so the next call retries.
+func (r *ClientRegistry) Get(config ClientConfig) (*PackerKeeperClient, error) {
+	// Hold the config lock while resolving the options so concurrent callers with
+	// the same config wait for a single resolution.
+	entry := r.entry(r.configs, configKey(config))
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.client != nil {
+		return entry.client, nil
+	}
+
+	client, err := r.resolve(config)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.client = client
+	return client, nil
+}
+
+// resolve resolves the client options of config and returns the client for the KSM config
+// they load, initializing it when no other config loaded the same KSM config before.
+func (r *ClientRegistry) resolve(config ClientConfig) (*PackerKeeperClient, error) {
+	options, source, err := getClientOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keeper doesn't validate the config content so we need to do it ourselves
+	// without validation, the client will panic.
+	if err := validateClientOptions(options, source); err != nil {
+		return nil, err
+	}
+
+	network, err := getNetworkSettings(config)
+	if err != nil {
+		return nil, err
+	}
+	applyHostname(options, network.Hostname)
+
+	cache, err := getCacheSettings(config)
+	if err != nil {
+		return nil, err
+	}
+
+	offline, err := getOfflineSettings(config)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := r.entry(r.entries, fingerprintConfig(options.Config, network, cache, offline))
+
+	// Hold the entry lock while initializing so concurrent callers with the
+	// same config wait for a single initialization.
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.client != nil {
+		return entry.client, nil
+	}
+
+	transport, err := newTransport(network)
+	if err != nil {
+		return nil, err
+	}
+	downloads := newDownloadTransport(transport, network.Retry)
+	transport = newRetryTransport(transport, network.Retry)
+
+	offlineCache, err := newOfflineFallback(options.Config, offline)
+	if err != nil {
+		return nil, err
+	}
+	if offlineCache != nil {
+		transport = offlineCache.Transport(transport)
+	}
+
+	kc, err := r.factory(options, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	enableOfflineFallback(kc, offlineCache)
+
+	kc, err = newCachedKeeperClient(kc, options.Config, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.client = newClient(kc, downloads)
+	return entry.client, nil
+}
+
+// entry returns the registry entry for key in entries, creating it if needed.
+func (r *ClientRegistry) entry(entries map[string]*registryEntry, key string) *registryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := entries[key]
+	if !ok {
+		e = &registryEntry{}
+		entries[key] = e
+	}
+
+	return e
+}
+
+// configKey returns a hash of the client config and of the environment variables the client
+// options fall back to, identifying a config before its options are resolved.
+func configKey(config ClientConfig) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%#v", config)
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, "KEEPER_") || strings.HasPrefix(env, "KSM_") {
+			h.Write([]byte{0})
+			h.Write([]byte(env))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fingerprintConfig returns a stable hash of the values identifying a KSM application,
+// how the plugin connects to it and how its records are cached.
+func fingerprintConfig(config ksm.IKeyValueStorage, network networkSettings, cache cacheSettings, offline offlineSettings) string {
+	h := sha256.New()
+	for _, key := range fingerprintKeys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(config.Get(key)))
+		h.Write([]byte{0})
+	}
+
+	for _, v := range []string{
+		network.ProxyURL, network.CABundleFile, strconv.FormatBool(network.InsecureSkipVerify),
+		strconv.Itoa(network.Retry.MaxRetries), network.Retry.Delay.String(), network.Retry.MaxDelay.String(), network.Retry.RequestTimeout.String(),
+		cache.Dir, cache.TTL.String(), strconv.FormatBool(cache.Bypass),
+		strconv.FormatBool(offline.Enabled), offline.Dir, offline.MaxStaleness.String(),
+	} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,126 @@
+package keeper_datasource
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testConfigA = `{"appKey": "app-key-a", "clientId": "client-id-a", "privateKey": "private-key-a"}`
+	testConfigB = `{"appKey": "app-key-b", "clientId": "client-id-b", "privateKey": "private-key-b"}`
+)
+
+// countingFactory returns a factory that counts how many clients it created.
+func countingFactory(calls *int32) ClientFactory {
+	return func(options *ksm.ClientOptions, transport http.RoundTripper) (KeeperClient, error) {
+		atomic.AddInt32(calls, 1)
+		return &MockKeeperClient{TestClient: &KSMClient{}}, nil
+	}
+}
+
+// TestRegistrySharesClientPerConfig tests that the same KSM config returns the same client
+// and a different KSM config returns a different client.
+func TestRegistrySharesClientPerConfig(t *testing.T) {
+	var calls int32
+	registry := NewClientRegistry(countingFactory(&calls))
+
+	first, err := registry.Get(ClientConfig{ConfigJSON: testConfigA})
+	require.NoError(t, err)
+
+	// The same config passed as base64 should resolve to the same fingerprint.
+	second, err := registry.Get(ClientConfig{ConfigBase64: base64.StdEncoding.EncodeToString([]byte(testConfigA))})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := registry.Get(ClientConfig{ConfigJSON: testConfigB})
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestRegistryResolvesConfigOnce tests that the options of a config, here its config command, are
+// only resolved the first time the config is used.
+func TestRegistryResolvesConfigOnce(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	t.Setenv("TEST_CONFIG_COMMAND_RUNS", runs)
+
+	var calls int32
+	registry := NewClientRegistry(countingFactory(&calls))
+	config := ClientConfig{ConfigCommand: helperCommand(t, "count")}
+
+	first, err := registry.Get(config)
+	require.NoError(t, err)
+	second, err := registry.Get(config)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	content, err := os.ReadFile(runs)
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(content), "the config command must only run once")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// TestRegistryRetriesFailedInit tests that a failed initialization isn't cached.
+func TestRegistryRetriesFailedInit(t *testing.T) {
+	initErr := errors.New("init failed")
+	fail := true
+	registry := NewClientRegistry(func(options *ksm.ClientOptions, transport http.RoundTripper) (KeeperClient, error) {
+		if fail {
+			return nil, initErr
+		}
+		return &MockKeeperClient{TestClient: &KSMClient{}}, nil
+	})
+
+	c, err := registry.Get(ClientConfig{ConfigJSON: testConfigA})
+	require.ErrorIs(t, err, initErr)
+	assert.Nil(t, c)
+
+	fail = false
+	c, err = registry.Get(ClientConfig{ConfigJSON: testConfigA})
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+}
+
+// TestRegistryInvalidConfig tests that invalid configs never reach the factory.
+func TestRegistryInvalidConfig(t *testing.T) {
+	var calls int32
+	registry := NewClientRegistry(countingFactory(&calls))
+
+	_, err := registry.Get(ClientConfig{ConfigJSON: `{"clientId": "only-a-client-id"}`})
+	require.Error(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+// TestRegistryConcurrentGet tests that concurrent callers with the same config initialize a single client.
+func TestRegistryConcurrentGet(t *testing.T) {
+	var calls int32
+	registry := NewClientRegistry(countingFactory(&calls))
+
+	var wg sync.WaitGroup
+	results := make([]*PackerKeeperClient, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := registry.Get(ClientConfig{ConfigJSON: testConfigA})
+			assert.NoError(t, err)
+			results[i] = c
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, c := range results {
+		assert.Same(t, results[0], c)
+	}
+}
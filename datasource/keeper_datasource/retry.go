@@ -0,0 +1,343 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for retrying Keeper requests.
+const (
+	defaultMaxRetries     = 3
+	defaultRetryDelay     = time.Second
+	defaultRetryMaxDelay  = 30 * time.Second
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// Errors for handling retry settings.
+var (
+	ErrInvalidRetryDelay     = errors.New("retry_delay and retry_max_delay can't be negative")
+	ErrInvalidRequestTimeout = errors.New("request_timeout can't be negative")
+)
+
+// throttleDelayPattern finds the delay Keeper asks for in throttling messages (ex: "Try again in 2 minutes").
+var throttleDelayPattern = regexp.MustCompile(`(?i)try again in (\d+) (second|minute)`)
+
+// retrySettings controls how failed Keeper requests are retried.
+type retrySettings struct {
+	MaxRetries     int
+	Delay          time.Duration
+	MaxDelay       time.Duration
+	RequestTimeout time.Duration
+}
+
+// getRetrySettings returns the retry settings for the client config with defaults applied.
+// A negative max_retries disables retries.
+func getRetrySettings(c ClientConfig) (retrySettings, error) {
+	if c.RetryDelay < 0 || c.RetryMaxDelay < 0 {
+		return retrySettings{}, ErrInvalidRetryDelay
+	}
+
+	if c.RequestTimeout < 0 {
+		return retrySettings{}, ErrInvalidRequestTimeout
+	}
+
+	settings := retrySettings{
+		MaxRetries:     c.MaxRetries,
+		Delay:          c.RetryDelay,
+		MaxDelay:       c.RetryMaxDelay,
+		RequestTimeout: c.RequestTimeout,
+	}
+
+	switch {
+	case settings.MaxRetries == 0:
+		settings.MaxRetries = defaultMaxRetries
+	case settings.MaxRetries < 0:
+		settings.MaxRetries = 0
+	}
+
+	if settings.Delay == 0 {
+		settings.Delay = defaultRetryDelay
+	}
+
+	if settings.MaxDelay == 0 {
+		settings.MaxDelay = defaultRetryMaxDelay
+	}
+
+	if settings.MaxDelay < settings.Delay {
+		settings.MaxDelay = settings.Delay
+	}
+
+	if settings.RequestTimeout == 0 {
+		settings.RequestTimeout = defaultRequestTimeout
+	}
+
+	return settings, nil
+}
+
+// retryTransport retries Keeper requests failing with a timeout, a refused or reset connection,
+// a server error or throttling. Each attempt is bounded by the request timeout, including reading the response.
+type retryTransport struct {
+	base     http.RoundTripper
+	settings retrySettings
+	// stream returns the response body unread, the request timeout only bounds receiving the response headers.
+	stream bool
+
+	// sleep waits between attempts until ctx is done, tests replace it to avoid waiting.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport wraps the transport with retries. When base is nil the default HTTP transport
+// is used, like Keeper does without a custom transport.
+func newRetryTransport(base http.RoundTripper, settings retrySettings) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &retryTransport{base: base, settings: settings, sleep: sleepContext}
+}
+
+// sleepContext waits for d, returning early with the error of ctx when it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newDownloadTransport wraps the transport with retries for attachment downloads. Attachments can be
+// far larger than Keeper responses, so their body is handed to the caller as it arrives instead of
+// being buffered by the transport, and isn't bound by the request timeout.
+func newDownloadTransport(base http.RoundTripper, settings retrySettings) *retryTransport {
+	t := newRetryTransport(base, settings)
+	t.stream = true
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests without a body can always be sent again, others only when their body can be recreated.
+	resendable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.attempt(req)
+
+		delay, reason, retry := t.retryDelay(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+
+		if attempt >= t.settings.MaxRetries || !resendable {
+			log.Printf("[WARN] Keeper request to %s failed after %d attempts: %s", req.URL.Path, attempt+1, reason)
+			return resp, err
+		}
+
+		// Keeper can ask to wait far longer than a build should stall, fail instead so the throttling is reported.
+		if delay > t.settings.MaxDelay {
+			log.Printf("[WARN] Keeper request to %s failed: %s, Keeper asked to wait %s which is longer than retry_max_delay",
+				req.URL.Path, reason, delay.Round(time.Second))
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		log.Printf("[WARN] Keeper request to %s failed: %s, retrying in %s (retry %d of %d)",
+			req.URL.Path, reason, delay.Round(time.Millisecond), attempt+1, t.settings.MaxRetries)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends the request once. The response body is read before returning, so a hung
+// connection is cut by the request timeout instead of stalling the build.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.stream {
+		return t.attemptStream(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.settings.RequestTimeout)
+	defer cancel()
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("request timed out after %s: %w", t.settings.RequestTimeout, err)
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("reading the response timed out after %s: %w", t.settings.RequestTimeout, err)
+		}
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// attemptStream sends the request once and returns the response with its body unread. The request
+// timeout is cancelled once the response headers are received, the body is read as slowly as it comes.
+func (t *retryTransport) attemptStream(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(t.settings.RequestTimeout, cancel)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("request timed out after %s: %w", t.settings.RequestTimeout, context.DeadlineExceeded)
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody cancels the context of its request once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay reports whether the attempt should be retried, how long to wait first and why.
+// Throttled requests wait as long as Keeper asks, other failures back off exponentially with jitter.
+// RoundTrip gives up on throttled requests asking to wait longer than the maximum delay.
+func (t *retryTransport) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, string, bool) {
+	if err != nil {
+		return t.backoff(attempt), err.Error(), retryableError(err)
+	}
+
+	if delay, ok := throttleDelay(resp); ok {
+		if delay == 0 {
+			delay = t.backoff(attempt)
+		}
+		return delay, "throttled by Keeper", true
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return t.backoff(attempt), resp.Status, true
+	}
+
+	return 0, "", false
+}
+
+// retryableError reports whether a failed request may succeed when sent again. Only timeouts and
+// refused or reset connections are retried, other errors such as certificate errors fail the same way.
+func retryableError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	for _, connErr := range connectionErrors {
+		if errors.Is(err, connErr) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the delay before the retry following the attempt. The delay doubles with each
+// attempt up to the maximum delay, and is randomized between half and all of it.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.settings.MaxDelay
+	if attempt < 32 {
+		if d := t.settings.Delay << attempt; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// throttleDelay reports whether Keeper throttled the request and the delay it asked for, zero when
+// no delay was given. Keeper throttles with a "throttled" result code, proxies in front of it with 429.
+// Only the body of failed requests is read, successful ones can be streamed attachments.
+func throttleDelay(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode < http.StatusBadRequest {
+		return 0, false
+	}
+
+	var body struct {
+		ResultCode string `json:"result_code"`
+		Error      string `json:"error"`
+		Message    string `json:"message"`
+	}
+
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	_ = json.Unmarshal(content, &body)
+
+	throttled := resp.StatusCode == http.StatusTooManyRequests || body.ResultCode == "throttled" || body.Error == "throttled"
+	if !throttled {
+		return 0, false
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(at), 0), true
+		}
+	}
+
+	if match := throttleDelayPattern.FindStringSubmatch(body.Message); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		unit := time.Second
+		if strings.EqualFold(match[2], "minute") {
+			unit = time.Minute
+		}
+		return time.Duration(n) * unit, true
+	}
+
+	return 0, true
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package keeper_datasource
+
+import "syscall"
+
+// connectionErrors are the errors of refused or reset connections.
+var connectionErrors = []error{syscall.ECONNREFUSED, syscall.ECONNRESET}
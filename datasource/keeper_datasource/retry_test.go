@@ -0,0 +1,305 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRetryTransport returns a retry transport recording its delays instead of sleeping.
+func newTestRetryTransport(settings retrySettings) (*retryTransport, *[]time.Duration) {
+	delays := &[]time.Duration{}
+	transport := newRetryTransport(nil, settings)
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return transport, delays
+}
+
+// postStub sends a request with a body to the stub server, like Keeper does.
+func postStub(t *testing.T, transport http.RoundTripper, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString("payload"))
+	require.NoError(t, err)
+	return (&http.Client{Transport: transport}).Do(req)
+}
+
+// TestRetryTransport tests that server errors back off exponentially and throttled requests wait as long as Keeper asks.
+func TestRetryTransport(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "payload", string(body))
+
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 3:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"result_code": "throttled", "message": "Due to repeated attempts, your request has been throttled. Try again in 2 minutes."}`))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	transport, delays := newTestRetryTransport(retrySettings{MaxRetries: 3, Delay: 10 * time.Millisecond, MaxDelay: 5 * time.Minute, RequestTimeout: time.Second})
+	resp, err := postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(4), attempts)
+
+	require.Len(t, *delays, 3)
+	assert.GreaterOrEqual(t, (*delays)[0], 5*time.Millisecond)
+	assert.LessOrEqual(t, (*delays)[0], 10*time.Millisecond)
+	assert.Equal(t, 7*time.Second, (*delays)[1])
+	assert.Equal(t, 2*time.Minute, (*delays)[2])
+
+	assert.Equal(t, 3, strings.Count(logs.String(), "retrying in"))
+	assert.Contains(t, logs.String(), "throttled by Keeper")
+}
+
+// TestRetryTransportGivesUp tests that the last response is returned once retries run out and client errors aren't retried.
+func TestRetryTransportGivesUp(t *testing.T) {
+	var attempts int32
+	status := int32(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	transport, delays := newTestRetryTransport(retrySettings{MaxRetries: 2, Delay: 10 * time.Millisecond, MaxDelay: 15 * time.Millisecond, RequestTimeout: time.Second})
+	resp, err := postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(3), attempts)
+
+	// The delay is capped by the maximum delay.
+	require.Len(t, *delays, 2)
+	assert.LessOrEqual(t, (*delays)[1], 15*time.Millisecond)
+
+	atomic.StoreInt32(&attempts, 0)
+	atomic.StoreInt32(&status, http.StatusForbidden)
+	resp, err = postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), attempts)
+}
+
+// TestRetryTransportThrottledTooLong tests that requests Keeper asks to wait longer than the maximum delay
+// fail as throttled instead of stalling the build, and that waiting between retries stops with the request.
+func TestRetryTransportThrottledTooLong(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, delays := newTestRetryTransport(retrySettings{MaxRetries: 3, Delay: time.Millisecond, MaxDelay: 30 * time.Second, RequestTimeout: time.Second})
+	resp, err := postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), attempts)
+	assert.Empty(t, *delays)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.ErrorIs(t, sleepContext(ctx, time.Hour), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestRetryTransportFinalErrors tests that certificate errors and client errors are attempted exactly once.
+func TestRetryTransportFinalErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	settings := retrySettings{MaxRetries: 3, Delay: time.Millisecond, MaxDelay: time.Millisecond, RequestTimeout: time.Second}
+
+	// The default transport doesn't trust the test server certificate.
+	transport, delays := newTestRetryTransport(settings)
+	_, err := postStub(t, transport, server.URL)
+	var unknownAuthority x509.UnknownAuthorityError
+	require.ErrorAs(t, err, &unknownAuthority)
+	assert.Empty(t, *delays)
+
+	transport, delays = newTestRetryTransport(settings)
+	transport.base = server.Client().Transport
+	resp, err := postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, int32(1), attempts)
+	assert.Empty(t, *delays)
+}
+
+// TestRetryTransportConnectionRefused tests that a refused connection is retried.
+func TestRetryTransportConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	transport, delays := newTestRetryTransport(retrySettings{MaxRetries: 2, Delay: time.Millisecond, MaxDelay: time.Millisecond, RequestTimeout: time.Second})
+	_, err = postStub(t, transport, "http://"+addr)
+	require.Error(t, err)
+	assert.True(t, retryableError(err))
+	assert.Len(t, *delays, 2)
+}
+
+// TestRetryTransportTimeout tests that a hung request is cut by the request timeout and retried.
+func TestRetryTransportTimeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRetryTransport(retrySettings{MaxRetries: 1, Delay: time.Millisecond, MaxDelay: time.Millisecond, RequestTimeout: 50 * time.Millisecond})
+	resp, err := postStub(t, transport, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), attempts)
+
+	transport.settings.MaxRetries = 0
+	atomic.StoreInt32(&attempts, 0)
+	_, err = postStub(t, transport, server.URL)
+	require.ErrorContains(t, err, "timed out after 50ms")
+}
+
+// TestDownloadTransport tests that downloads are retried and that a body taking longer than the request timeout
+// is streamed to the end.
+func TestDownloadTransport(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte("first half "))
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("second half"))
+	}))
+	defer server.Close()
+
+	settings := retrySettings{MaxRetries: 3, Delay: time.Millisecond, MaxDelay: time.Millisecond, RequestTimeout: 50 * time.Millisecond}
+	transport := newDownloadTransport(nil, settings)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "first half second half", string(body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+// TestDownloadTransportTimeout tests that a download whose headers don't arrive within the request timeout is retried.
+func TestDownloadTransportTimeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	settings := retrySettings{MaxRetries: 2, Delay: time.Millisecond, MaxDelay: time.Millisecond, RequestTimeout: 50 * time.Millisecond}
+	transport := newDownloadTransport(nil, settings)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+
+	_, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.ErrorContains(t, err, "timed out after 50ms")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+// TestRetryStandInServer tests that Keeper requests are retried against a KSM stand-in until it answers with a final error.
+func TestRetryStandInServer(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"result_code": "access_denied", "message": "stand-in"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv(KEEPER_INSECURE_SKIP_VERIFY_ENV_KEY, "true")
+	registry := NewClientRegistry(DefaultClientFactory)
+	client, err := registry.Get(ClientConfig{
+		ConfigJSON: generateTestConfig(t),
+		Hostname:   server.Listener.Addr().String(),
+		RetryDelay: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = client.KeeperClient.GetSecret("record-uid")
+	require.ErrorContains(t, err, "access_denied")
+	assert.Equal(t, int32(3), attempts)
+}
+
+// TestRetrySettings tests that retry settings get defaults and are validated.
+func TestRetrySettings(t *testing.T) {
+	settings, err := getRetrySettings(ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, retrySettings{MaxRetries: 3, Delay: time.Second, MaxDelay: 30 * time.Second, RequestTimeout: 30 * time.Second}, settings)
+
+	settings, err = getRetrySettings(ClientConfig{MaxRetries: -1, RetryDelay: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, retrySettings{MaxRetries: 0, Delay: time.Minute, MaxDelay: time.Minute, RequestTimeout: 30 * time.Second}, settings)
+
+	err = ValidateClientConfig(ClientConfig{RetryDelay: -time.Second})
+	require.ErrorIs(t, err, ErrInvalidRetryDelay)
+
+	err = ValidateClientConfig(ClientConfig{RequestTimeout: -time.Second})
+	require.ErrorIs(t, err, ErrInvalidRequestTimeout)
+}
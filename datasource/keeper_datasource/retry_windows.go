@@ -0,0 +1,9 @@
+//go:build windows
+
+package keeper_datasource
+
+import "syscall"
+
+// connectionErrors are the errors of refused or reset connections. Windows reports them with
+// Winsock error codes instead of the POSIX ones.
+var connectionErrors = []error{syscall.Errno(10061), syscall.WSAECONNRESET, syscall.ECONNREFUSED, syscall.ECONNRESET}
@@ -0,0 +1,154 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrSSHKeyPassphrase is returned for encrypted private keys the passphrase of the record doesn't decrypt.
+var ErrSSHKeyPassphrase = errors.New("unable to decrypt the SSH private key")
+
+// normalizeKeyPair decrypts the private key of the key pair with the passphrase when needed, derives
+// the public key when the record doesn't have it and fills in the key details and the formats the
+// private key can be converted to. Keys in formats that can't be parsed are left as stored.
+func normalizeKeyPair(kp *KeyPair, passphrase string) error {
+	var pub ssh.PublicKey
+
+	if kp.PrivateKey != "" {
+		key, err := parseSSHPrivateKey(kp.PrivateKey, passphrase)
+		if errors.Is(err, ErrSSHKeyPassphrase) {
+			return err
+		}
+
+		if err == nil {
+			pub, err = ssh.NewPublicKey(publicKeyOf(key))
+		}
+		if err != nil {
+			log.Printf("[WARN] unable to parse the SSH private key, it is returned as stored: %s", err)
+		} else {
+			convertPrivateKey(kp, key)
+		}
+	}
+
+	if pub == nil && kp.PublicKey != "" {
+		// Records holding only the public key still get its details.
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(kp.PublicKey))
+		if err != nil {
+			return nil
+		}
+		pub = parsed
+	}
+
+	if pub == nil {
+		return nil
+	}
+
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if kp.PublicKey == "" {
+		kp.PublicKey = authorizedKey
+	} else if stored, _, _, _, err := ssh.ParseAuthorizedKey([]byte(kp.PublicKey)); err == nil && !bytes.Equal(stored.Marshal(), pub.Marshal()) {
+		log.Printf("[WARN] the SSH public key of the record doesn't match its private key, the fingerprints are of the private key")
+	}
+
+	kp.KeyType = pub.Type()
+	kp.Bits = publicKeyBits(pub)
+	kp.FingerprintSHA256 = ssh.FingerprintSHA256(pub)
+	kp.FingerprintMD5 = ssh.FingerprintLegacyMD5(pub)
+
+	return nil
+}
+
+// parseSSHPrivateKey parses a private key in OpenSSH, PKCS#1, SEC 1 or PKCS#8 format, decrypting
+// it with the passphrase when it is encrypted.
+func parseSSHPrivateKey(privateKey string, passphrase string) (crypto.PrivateKey, error) {
+	key, err := ssh.ParseRawPrivateKey([]byte(privateKey))
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return normalizePrivateKey(key), err
+	}
+
+	if passphrase == "" {
+		return nil, fmt.Errorf("%w: the private key is encrypted and the record has no passphrase", ErrSSHKeyPassphrase)
+	}
+
+	key, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the passphrase of the record doesn't decrypt the private key: %s", ErrSSHKeyPassphrase, err)
+	}
+
+	return normalizePrivateKey(key), nil
+}
+
+// normalizePrivateKey returns ed25519 keys by value, OpenSSH keys are parsed as pointers.
+func normalizePrivateKey(key crypto.PrivateKey) crypto.PrivateKey {
+	if k, ok := key.(*ed25519.PrivateKey); ok {
+		return *k
+	}
+	return key
+}
+
+// publicKeyOf returns the public key of a private key.
+func publicKeyOf(key crypto.PrivateKey) crypto.PublicKey {
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+	return nil
+}
+
+// convertPrivateKey sets the unencrypted OpenSSH, PKCS#8 and PEM encodings of the private key. Formats
+// the key type doesn't support are left empty.
+func convertPrivateKey(kp *KeyPair, key crypto.PrivateKey) {
+	if block, err := ssh.MarshalPrivateKey(key, ""); err == nil {
+		kp.PrivateKeyOpenSSH = string(pem.EncodeToMemory(block))
+	}
+
+	if der, err := x509.MarshalPKCS8PrivateKey(key); err == nil {
+		kp.PrivateKeyPKCS8 = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	}
+
+	// PEM is the traditional format of the key type, ed25519 keys only have PKCS#8.
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		kp.PrivateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}))
+	case *ecdsa.PrivateKey:
+		if der, err := x509.MarshalECPrivateKey(k); err == nil {
+			kp.PrivateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+		}
+	default:
+		kp.PrivateKeyPEM = kp.PrivateKeyPKCS8
+	}
+}
+
+// publicKeyBits returns the size of the public key in bits.
+func publicKeyBits(pub ssh.PublicKey) int {
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+
+	switch k := cryptoPub.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return ed25519.PublicKeySize * 8
+	}
+	return 0
+}
+
+// SecretValues returns the private key of the key pair in every format, to be filtered from logs.
+func (kp KeyPair) SecretValues() []string {
+	return []string{kp.PrivateKey, kp.PrivateKeyOpenSSH, kp.PrivateKeyPKCS8, kp.PrivateKeyPEM}
+}
@@ -0,0 +1,143 @@
+package keeper_datasource
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// newSSHKeyClient returns a client reading an SSH key record with the key pairs and passphrase.
+func newSSHKeyClient(t *testing.T, passphrase string, keyPairs ...map[string]string) *PackerKeeperClient {
+	fields := []map[string]interface{}{{"type": "password", "value": []string{passphrase}}}
+	for _, keyPair := range keyPairs {
+		fields = append(fields, map[string]interface{}{"type": "keyPair", "value": []interface{}{keyPair}})
+	}
+
+	record, err := json.Marshal(map[string]interface{}{"uid": "ssh-uid", "type": "sshKeys", "fields": fields})
+	require.NoError(t, err)
+	return getMockedClient(string(record))
+}
+
+// marshalSSHKey returns the private key in OpenSSH format, encrypted when passphrase is set.
+func marshalSSHKey(t *testing.T, key crypto.PrivateKey, passphrase string) string {
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
+// TestGetSSHKeyDerivesPublicKey tests that the public key, details and conversions are derived from the private key.
+func TestGetSSHKeyDerivesPublicKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pub, err := ssh.NewPublicKey(key.Public())
+	require.NoError(t, err)
+
+	client := newSSHKeyClient(t, "", map[string]string{"privateKey": marshalSSHKey(t, key, "")})
+	sshKey, err := client.GetSSHKey(RecordQuery{Uid: "ssh-uid"})
+	require.NoError(t, err)
+
+	keyPair := sshKey.KeyPair
+	assert.Equal(t, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))), keyPair.PublicKey)
+	assert.Equal(t, "ssh-ed25519", keyPair.KeyType)
+	assert.Equal(t, 256, keyPair.Bits)
+	assert.Equal(t, ssh.FingerprintSHA256(pub), keyPair.FingerprintSHA256)
+	assert.Equal(t, ssh.FingerprintLegacyMD5(pub), keyPair.FingerprintMD5)
+	assert.Equal(t, []KeyPair{keyPair}, sshKey.KeyPairs)
+
+	// Every conversion holds the same key.
+	for _, converted := range []string{keyPair.PrivateKeyOpenSSH, keyPair.PrivateKeyPKCS8, keyPair.PrivateKeyPEM} {
+		parsed, err := ssh.ParseRawPrivateKey([]byte(converted))
+		require.NoError(t, err)
+		assert.Equal(t, key, normalizePrivateKey(parsed))
+	}
+	assert.Contains(t, keyPair.PrivateKeyPKCS8, "BEGIN PRIVATE KEY")
+	assert.Contains(t, keyPair.SecretValues(), keyPair.PrivateKeyOpenSSH)
+}
+
+// TestGetSSHKeyDecryptsPrivateKey tests that encrypted private keys are decrypted with the passphrase of the record.
+func TestGetSSHKeyDecryptsPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	encrypted := marshalSSHKey(t, key, "correct horse")
+
+	client := newSSHKeyClient(t, "correct horse", map[string]string{"privateKey": encrypted})
+	sshKey, err := client.GetSSHKey(RecordQuery{Uid: "ssh-uid"})
+	require.NoError(t, err)
+
+	keyPair := sshKey.KeyPair
+	assert.Equal(t, encrypted, keyPair.PrivateKey)
+	assert.Equal(t, "ssh-rsa", keyPair.KeyType)
+	assert.Equal(t, 2048, keyPair.Bits)
+	assert.True(t, strings.HasPrefix(keyPair.PublicKey, "ssh-rsa "))
+
+	block, _ := pem.Decode([]byte(keyPair.PrivateKeyPEM))
+	require.NotNil(t, block)
+	assert.Equal(t, "RSA PRIVATE KEY", block.Type)
+	parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = ssh.ParseRawPrivateKey([]byte(keyPair.PrivateKeyOpenSSH))
+	require.NoError(t, err, "the OpenSSH conversion is unencrypted")
+
+	// A passphrase that doesn't decrypt the selected key pair fails the datasource.
+	for _, passphrase := range []string{"", "wrong"} {
+		client = newSSHKeyClient(t, passphrase, map[string]string{"privateKey": encrypted})
+		_, err = client.GetSSHKey(RecordQuery{Uid: "ssh-uid"})
+		require.ErrorIs(t, err, ErrSSHKeyPassphrase)
+	}
+}
+
+// TestGetSSHKeyOtherFormats tests ECDSA keys in PKCS#8 format, public keys without a private key and keys that
+// can't be parsed.
+func TestGetSSHKeyOtherFormats(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+
+	encrypted := marshalSSHKey(t, key, "older passphrase")
+	client := newSSHKeyClient(t, "",
+		map[string]string{"privateKey": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))},
+		map[string]string{"publicKey": authorizedKey + " build@example.com"},
+		map[string]string{"publicKey": "ssh-ed25519 AAAA", "privateKey": "PRIVATE KEY"},
+		map[string]string{"privateKey": encrypted},
+	)
+
+	sshKey, err := client.GetSSHKey(RecordQuery{Uid: "ssh-uid"})
+	require.NoError(t, err, "only the selected key pair must be decrypted")
+	require.Len(t, sshKey.KeyPairs, 4)
+
+	ecdsaKey := sshKey.KeyPair
+	assert.Equal(t, "ecdsa-sha2-nistp384", ecdsaKey.KeyType)
+	assert.Equal(t, 384, ecdsaKey.Bits)
+	assert.Equal(t, authorizedKey, ecdsaKey.PublicKey)
+	assert.Contains(t, ecdsaKey.PrivateKeyPEM, "BEGIN EC PRIVATE KEY")
+
+	// Public keys are kept as stored and still get their details.
+	publicOnly := sshKey.KeyPairs[1]
+	assert.Equal(t, authorizedKey+" build@example.com", publicOnly.PublicKey)
+	assert.Equal(t, ssh.FingerprintSHA256(pub), publicOnly.FingerprintSHA256)
+	assert.Empty(t, publicOnly.PrivateKeyOpenSSH)
+
+	assert.Equal(t, KeyPair{PublicKey: "ssh-ed25519 AAAA", PrivateKey: "PRIVATE KEY"}, sshKey.KeyPairs[2])
+	assert.Equal(t, KeyPair{PrivateKey: encrypted}, sshKey.KeyPairs[3])
+}
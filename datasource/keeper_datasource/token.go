@@ -0,0 +1,199 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Environment variables used to bootstrap a KSM config from a one-time access token.
+const (
+	KEEPER_TOKEN_ENV_KEY             = "KSM_TOKEN"
+	KEEPER_TOKEN_CONFIG_FILE_ENV_KEY = "KEEPER_TOKEN_CONFIG_FILE"
+)
+
+// Errors for handling one-time access token redemption.
+var (
+	ErrTokenConfigFileRequired = errors.New("a one-time token was provided but no file to write the redeemed config to, set token_config_file or the " + KEEPER_TOKEN_CONFIG_FILE_ENV_KEY + " environment variable")
+	ErrInvalidToken            = errors.New("one-time token must be in the form REGION:TOKEN (ex: US:ONE_TIME_TOKEN) or HOST:TOKEN")
+	ErrTokenRedemption         = errors.New("failed to redeem one-time token, Keeper couldn't be reached or rejected the token (one-time tokens can only be redeemed once, if it was already redeemed generate a new token or use the config written by the first redemption)")
+	ErrTokenConfigMismatch     = errors.New("token_config_file was redeemed from a different one-time token")
+)
+
+// tokenFingerprintSuffix is appended to the token config file to name the file holding the
+// fingerprint of the token it was redeemed from.
+const tokenFingerprintSuffix = ".token-sha256"
+
+// getTokenOptions returns the one-time token and the file the redeemed config is written to,
+// preferring the HCL config over the environment. ok is false when no token is set.
+func getTokenOptions(c ClientConfig) (token string, configFile string, ok bool) {
+	token = c.Token
+	if token == "" {
+		token = os.Getenv(KEEPER_TOKEN_ENV_KEY)
+	}
+
+	configFile = c.TokenConfigFile
+	if configFile == "" {
+		configFile = os.Getenv(KEEPER_TOKEN_CONFIG_FILE_ENV_KEY)
+	}
+
+	return strings.TrimSpace(token), configFile, strings.TrimSpace(token) != ""
+}
+
+// getTokenClientOptions redeems the one-time token and writes the resulting config to configFile.
+// If configFile already exists and was redeemed from the same token it is used instead, this lets
+// every datasource in a build share the same token settings. The token is redeemed using the
+// network settings of the client config.
+func getTokenClientOptions(c ClientConfig, token string, configFile string) (*ksm.ClientOptions, string, error) {
+	if configFile == "" {
+		return nil, "", ErrTokenConfigFileRequired
+	}
+
+	if err := redeemTokenConfig(c, token, configFile); err != nil {
+		return nil, "", err
+	}
+
+	// Load the written config back from disk so later updates are persisted to it.
+	source := fmt.Sprintf("config redeemed from one-time token (%s)", configFile)
+	return readConfigFile(configFile, source, configReadOnly(c))
+}
+
+// redeemTokenConfig redeems the one-time token into configFile unless it already exists. The lock
+// file of configFile is held from the existence check until the config is written, so datasources
+// started at the same time redeem the token once and the others use the config it wrote.
+func redeemTokenConfig(c ClientConfig, token string, configFile string) error {
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return fmt.Errorf("unable to create directory for redeemed config %s: %w", configFile, err)
+	}
+
+	unlock, err := acquireFileLock(configFile + ".lock")
+	if err != nil {
+		return fmt.Errorf("unable to redeem one-time token to %s: %w", configFile, err)
+	}
+	defer unlock()
+
+	if _, err := os.Stat(configFile); err == nil {
+		if err := checkTokenFingerprint(configFile, token); err != nil {
+			return err
+		}
+		log.Printf("[INFO] KSM config %s already exists, skipping one-time token redemption", configFile)
+		return nil
+	}
+
+	network, err := getNetworkSettings(c)
+	if err != nil {
+		return err
+	}
+
+	storage, err := redeemToken(token, network)
+	if err != nil {
+		return err
+	}
+
+	return writeRedeemedConfig(configFile, storage, token)
+}
+
+// redeemToken binds the one-time token to a new KSM config, reaching Keeper through the proxy,
+// CA bundle, hostname and retries of the network settings.
+func redeemToken(token string, network networkSettings) (ksm.IKeyValueStorage, error) {
+	transport, err := newTransport(network)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &ksm.Context{Transport: newRetryTransport(transport, network.Retry)}
+	return bindToken(token, network.Hostname, &ctx)
+}
+
+// bindToken binds the one-time token to a new KSM config, sending requests through the transport
+// of ctx. Keeper only binds the token on the first call to the API, so we fetch the secrets shared
+// with the application once. hostname overrides the host of the token when set, the returned config
+// keeps the host of the token.
+func bindToken(token string, hostname string, ctx **ksm.Context) (ksm.IKeyValueStorage, error) {
+	// Keeper requires the region or host to be part of the token.
+	if parts := strings.SplitN(token, ":", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, ErrInvalidToken
+	}
+
+	storage := ksm.NewMemoryKeyValueStorage()
+	options := &ksm.ClientOptions{
+		Token:  token,
+		Config: storage,
+	}
+
+	sm := ksm.NewSecretsManager(options, ctx)
+	if sm == nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Override the hostname once Keeper stored the host of the token, the override is only used to
+	// reach Keeper and is applied again when the config is read back.
+	applyHostname(options, hostname)
+	sm.Config = options.Config
+
+	if _, err := sm.GetSecrets([]string{}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenRedemption, err)
+	}
+
+	return storage, nil
+}
+
+// writeRedeemedConfig writes the config redeemed from token as JSON with 0600 permissions, along
+// with the fingerprint of the token, the caller must hold the lock file of path. The fingerprint is
+// written first and the config is written to a temporary file renamed into place once complete, so
+// a config is never published partially written or without its fingerprint. A config written by
+// another process is never overwritten.
+func writeRedeemedConfig(path string, storage ksm.IKeyValueStorage, token string) error {
+	content, err := json.MarshalIndent(storage.ReadStorage(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("unable to write redeemed config to %s: %w", path, os.ErrExist)
+	}
+
+	if err := replaceFile(path+tokenFingerprintSuffix, []byte(tokenFingerprint(token)+"\n")); err != nil {
+		return fmt.Errorf("unable to write the token fingerprint of %s: %w", path, err)
+	}
+
+	if err := replaceFile(path, content); err != nil {
+		return fmt.Errorf("unable to write redeemed config to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// tokenFingerprint returns the hex encoded SHA-256 digest of the token, stored next to the
+// redeemed config so the token itself is never written to disk.
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkTokenFingerprint fails when the existing configFile was redeemed from another token, or
+// when its fingerprint is missing since it can't be told which token it was redeemed from.
+func checkTokenFingerprint(configFile string, token string) error {
+	fingerprintFile := configFile + tokenFingerprintSuffix
+	stored, err := os.ReadFile(fingerprintFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s is missing, remove %s to redeem the token again or set config_file to use it as is", ErrTokenConfigMismatch, fingerprintFile, configFile)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the token fingerprint of %s: %w", configFile, err)
+	}
+
+	if string(bytes.TrimSpace(stored)) != tokenFingerprint(token) {
+		return fmt.Errorf("%w: %s doesn't match the configured token, remove %s and %s to redeem the new token", ErrTokenConfigMismatch, fingerprintFile, configFile, fingerprintFile)
+	}
+	return nil
+}
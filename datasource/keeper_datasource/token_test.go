@@ -0,0 +1,229 @@
+package keeper_datasource
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTokenRequiresConfigFile tests that a token without a destination file returns an error.
+func TestTokenRequiresConfigFile(t *testing.T) {
+	t.Setenv(KEEPER_TOKEN_CONFIG_FILE_ENV_KEY, "")
+	_, _, err := getClientOptions(ClientConfig{Token: "US:ONE_TIME_TOKEN"})
+	require.ErrorIs(t, err, ErrTokenConfigFileRequired)
+}
+
+// TestTokenRequiresRegion tests that tokens without a region or host prefix are rejected before calling Keeper.
+func TestTokenRequiresRegion(t *testing.T) {
+	_, _, err := getClientOptions(ClientConfig{
+		Token:           "ONE_TIME_TOKEN",
+		TokenConfigFile: filepath.Join(t.TempDir(), "config.json"),
+	})
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, err = getClientOptions(ClientConfig{
+		Token:           "  ",
+		TokenConfigFile: filepath.Join(t.TempDir(), "config.json"),
+	})
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// newTokenServer starts a stand-in for Keeper binding the one-time token holding clientKey to the
+// app key. The token is rejected once redeemed. Responses are encrypted with the transmission key
+// Keeper sets on ctx.
+func newTokenServer(t *testing.T, ctx **ksm.Context, clientKey []byte, appKey []byte) (*httptest.Server, networkSettings) {
+	var redeemed atomic.Bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encrypted, _ := io.ReadAll(r.Body)
+		payload, err := ksm.Decrypt(encrypted, (*ctx).TransmissionKey.Key)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Clients send their public key until the token is bound, Keeper then fetches the records again.
+		var request struct {
+			PublicKey string `json:"publicKey"`
+		}
+		json.Unmarshal(payload, &request)
+
+		content := map[string]interface{}{"records": []interface{}{}}
+		if request.PublicKey != "" {
+			if redeemed.Swap(true) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"result_code": "access_denied", "message": "Unable to validate Keeper application access"}`))
+				return
+			}
+
+			encryptedAppKey, err := ksm.EncryptAesGcm(appKey, clientKey)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			content["encryptedAppKey"] = ksm.BytesToUrlSafeStr(encryptedAppKey)
+		}
+
+		response, _ := json.Marshal(content)
+		body, err := ksm.EncryptAesGcm(response, (*ctx).TransmissionKey.Key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certPEM, 0600))
+
+	retry, err := getRetrySettings(ClientConfig{MaxRetries: -1})
+	require.NoError(t, err)
+
+	return server, networkSettings{Hostname: server.Listener.Addr().String(), CABundleFile: caBundle, Retry: retry}
+}
+
+// TestRedeemToken tests that the token is redeemed through the network settings without saving the
+// hostname override, and that a token redeemed twice or a Keeper that can't be reached fail with the
+// underlying error.
+func TestRedeemToken(t *testing.T) {
+	clientKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	appKey, err := ksm.GenerateRandomBytes(32)
+	require.NoError(t, err)
+	token := "US:" + ksm.BytesToUrlSafeStr(clientKey)
+
+	ctx := &ksm.Context{}
+	server, network := newTokenServer(t, &ctx, clientKey, appKey)
+	transport, err := newTransport(network)
+	require.NoError(t, err)
+	ctx.Transport = newRetryTransport(transport, network.Retry)
+
+	storage, err := bindToken(token, network.Hostname, &ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ksm.BytesToBase64(appKey), storage.Get(ksm.KEY_APP_KEY))
+	// The hostname override only reaches Keeper, the config keeps the host of the token.
+	assert.Equal(t, "keepersecurity.com", storage.Get(ksm.KEY_HOSTNAME))
+	assert.Empty(t, storage.Get(ksm.KEY_CLIENT_KEY))
+
+	_, err = bindToken(token, network.Hostname, &ctx)
+	require.ErrorIs(t, err, ErrTokenRedemption)
+	assert.ErrorContains(t, err, "access_denied")
+
+	server.Close()
+	_, err = redeemToken(token, network)
+	require.ErrorIs(t, err, ErrTokenRedemption)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+// TestTokenReusesRedeemedConfig tests that an existing token config file is used instead of redeeming the token again.
+func TestTokenReusesRedeemedConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(testConfigA), 0600))
+	require.NoError(t, os.WriteFile(configFile+tokenFingerprintSuffix, []byte(tokenFingerprint("US:ONE_TIME_TOKEN")+"\n"), 0600))
+
+	// Set the token through the environment to check the environment fallback.
+	t.Setenv(KEEPER_CONFIG_FILE_ENV_KEY, "")
+	os.Unsetenv(KEEPER_CONFIG_FILE_ENV_KEY)
+	t.Setenv(KEEPER_CONFIG_ENV_KEY, "")
+	os.Unsetenv(KEEPER_CONFIG_ENV_KEY)
+	t.Setenv(KEEPER_TOKEN_ENV_KEY, "US:ONE_TIME_TOKEN")
+	t.Setenv(KEEPER_TOKEN_CONFIG_FILE_ENV_KEY, configFile)
+
+	options, source, err := getClientOptions(ClientConfig{})
+	require.NoError(t, err)
+	assert.Contains(t, source, configFile)
+	assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+}
+
+// TestTokenChecksRedeemedConfigFingerprint tests that a token config file redeemed from another token is not reused.
+func TestTokenChecksRedeemedConfigFingerprint(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(testConfigA), 0600))
+	require.NoError(t, os.WriteFile(configFile+tokenFingerprintSuffix, []byte(tokenFingerprint("US:ONE_TIME_TOKEN")+"\n"), 0600))
+
+	options, _, err := getClientOptions(ClientConfig{Token: "US:ONE_TIME_TOKEN", TokenConfigFile: configFile})
+	require.NoError(t, err)
+	assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+
+	_, _, err = getClientOptions(ClientConfig{Token: "US:OTHER_TOKEN", TokenConfigFile: configFile})
+	require.ErrorIs(t, err, ErrTokenConfigMismatch)
+	assert.Contains(t, err.Error(), configFile)
+
+	kind, _ := classifyError(err, "")
+	assert.Equal(t, ErrInvalidConfig, kind)
+
+	// Without its fingerprint the config can't be told apart from one redeemed from another token.
+	require.NoError(t, os.Remove(configFile+tokenFingerprintSuffix))
+	_, _, err = getClientOptions(ClientConfig{Token: "US:ONE_TIME_TOKEN", TokenConfigFile: configFile})
+	require.ErrorIs(t, err, ErrTokenConfigMismatch)
+	assert.Contains(t, err.Error(), "is missing")
+}
+
+// TestTokenRedemptionWaitsForLock tests that a redemption waits for the lock held by another process
+// and uses the config it wrote instead of redeeming the token again.
+func TestTokenRedemptionWaitsForLock(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	unlock, err := acquireFileLock(configFile + ".lock")
+	require.NoError(t, err)
+
+	written := make(chan error, 1)
+	go func() {
+		defer unlock()
+		time.Sleep(100 * time.Millisecond)
+		written <- writeRedeemedConfig(configFile, ksm.NewMemoryKeyValueStorage(testConfigA), "US:ONE_TIME_TOKEN")
+	}()
+
+	// Keeper can't be reached, the token must not be redeemed.
+	options, _, err := getClientOptions(ClientConfig{
+		Token:           "US:ONE_TIME_TOKEN",
+		TokenConfigFile: configFile,
+		Hostname:        "127.0.0.1:1",
+		MaxRetries:      -1,
+	})
+	require.NoError(t, <-written)
+	require.NoError(t, err)
+	assert.Equal(t, "app-key-a", options.Config.Get(ksm.KEY_APP_KEY))
+}
+
+// TestWriteRedeemedConfig tests that the redeemed config is written with 0600 permissions along with its fingerprint,
+// replaces the fingerprint left by a redemption that didn't complete and is never overwritten.
+func TestWriteRedeemedConfig(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	configFile := filepath.Join(dir, "config.json")
+	storage := ksm.NewMemoryKeyValueStorage(testConfigA)
+
+	// A redemption interrupted after writing the fingerprint.
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	require.NoError(t, os.WriteFile(configFile+tokenFingerprintSuffix, []byte(tokenFingerprint("US:OTHER_TOKEN")+"\n"), 0600))
+
+	require.NoError(t, writeRedeemedConfig(configFile, storage, "US:ONE_TIME_TOKEN"))
+
+	info, err := os.Stat(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	options, _, err := readConfigFile(configFile, "test", true)
+	require.NoError(t, err)
+	assert.Equal(t, "client-id-a", options.Config.Get(ksm.KEY_CLIENT_ID))
+	require.NoError(t, checkTokenFingerprint(configFile, "US:ONE_TIME_TOKEN"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Writing a second time must fail rather than overwrite the first config.
+	require.ErrorIs(t, writeRedeemedConfig(configFile, storage, "US:OTHER_TOKEN"), os.ErrExist)
+	require.NoError(t, checkTokenFingerprint(configFile, "US:ONE_TIME_TOKEN"))
+}
@@ -0,0 +1,145 @@
+package keeper_datasource
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	ksm "github.com/keeper-security/secrets-manager-go/core"
+)
+
+// Defaults of otpauth URIs leaving out a parameter.
+const (
+	defaultTOTPAlgorithm = "SHA1"
+	defaultTOTPDigits    = 6
+	defaultTOTPPeriod    = 30
+)
+
+// totpAlgorithms are the HMAC algorithms one-time codes can be computed with.
+var totpAlgorithms = map[string]func() hash.Hash{
+	"SHA1":   sha1.New,
+	"SHA256": sha256.New,
+	"SHA512": sha512.New,
+}
+
+// getTOTP returns the TOTP parameters of the first one-time code field of the record, nil when
+// the record has none.
+func getTOTP(r *ksm.Record) (*TOTP, error) {
+	uri := getFieldValue(r, "oneTimeCode")
+	if uri == "" {
+		uri = getFieldValue(r, "otp")
+	}
+	if uri == "" {
+		return nil, nil
+	}
+
+	return parseTOTP(uri)
+}
+
+// parseTOTP parses an otpauth URI (ex: otpauth://totp/Example:admin?secret=JBSWY3DPEHPK3PXP&issuer=Example).
+func parseTOTP(uri string) (*TOTP, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("%w: oneTimeCode value isn't an otpauth URI", ErrMalformedField)
+	}
+
+	if !strings.EqualFold(u.Host, "totp") {
+		return nil, fmt.Errorf("%w: oneTimeCode type %q isn't supported, only totp is", ErrMalformedField, u.Host)
+	}
+
+	totp := &TOTP{
+		Algorithm: defaultTOTPAlgorithm,
+		Digits:    defaultTOTPDigits,
+		Period:    defaultTOTPPeriod,
+	}
+
+	// The label is the account name, optionally prefixed by the issuer.
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		totp.Issuer = issuer
+		label = account
+	}
+	totp.AccountName = strings.TrimSpace(label)
+
+	query := u.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		totp.Issuer = issuer
+	}
+
+	totp.Secret = strings.ToUpper(strings.ReplaceAll(query.Get("secret"), " ", ""))
+	if _, err := decodeTOTPSecret(totp.Secret); err != nil || totp.Secret == "" {
+		return nil, fmt.Errorf("%w: oneTimeCode secret isn't base32 encoded", ErrMalformedField)
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		totp.Algorithm = strings.ToUpper(algorithm)
+		if _, ok := totpAlgorithms[totp.Algorithm]; !ok {
+			return nil, fmt.Errorf("%w: oneTimeCode algorithm %q isn't supported, use SHA1, SHA256 or SHA512", ErrMalformedField, algorithm)
+		}
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		totp.Digits, err = strconv.Atoi(digits)
+		if err != nil || totp.Digits < 1 || totp.Digits > 10 {
+			return nil, fmt.Errorf("%w: oneTimeCode digits %q must be between 1 and 10", ErrMalformedField, digits)
+		}
+	}
+
+	if period := query.Get("period"); period != "" {
+		totp.Period, err = strconv.Atoi(period)
+		if err != nil || totp.Period < 1 {
+			return nil, fmt.Errorf("%w: oneTimeCode period %q must be a positive number of seconds", ErrMalformedField, period)
+		}
+	}
+
+	return totp, nil
+}
+
+// decodeTOTPSecret decodes a base32 secret, otpauth URIs usually leave out the padding.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+}
+
+// Code returns the one-time code at the time along with how many seconds it stays valid for (RFC 6238).
+func (t *TOTP) Code(at time.Time) (string, int, error) {
+	secret, err := decodeTOTPSecret(t.Secret)
+	if err != nil {
+		return "", 0, err
+	}
+
+	newHash, ok := totpAlgorithms[t.Algorithm]
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported TOTP algorithm %q", t.Algorithm)
+	}
+
+	unix := at.Unix()
+	period := int64(t.Period)
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(unix/period))
+
+	mac := hmac.New(newHash, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation picks 4 bytes of the HMAC starting at the offset in its last nibble.
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	modulo := uint64(1)
+	for i := 0; i < t.Digits; i++ {
+		modulo *= 10
+	}
+
+	code := fmt.Sprintf("%0*d", t.Digits, value%modulo)
+	return code, int(period - unix%period), nil
+}
@@ -0,0 +1,146 @@
+package keeper_datasource
+
+import (
+	"bytes"
+	"encoding/base32"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTOTPCode tests one-time codes against the test vectors of RFC 6238.
+func TestTOTPCode(t *testing.T) {
+	secrets := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+
+	for _, test := range tests {
+		totp := &TOTP{
+			Algorithm: test.algorithm,
+			Digits:    8,
+			Period:    30,
+			Secret:    base32.StdEncoding.EncodeToString([]byte(secrets[test.algorithm])),
+		}
+
+		code, remaining, err := totp.Code(time.Unix(test.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, test.code, code, "%s at %d", test.algorithm, test.unix)
+		assert.Equal(t, int(30-test.unix%30), remaining)
+	}
+}
+
+// TestParseTOTP tests that otpauth URIs are parsed with defaults for the parameters they leave out.
+func TestParseTOTP(t *testing.T) {
+	totp, err := parseTOTP("otpauth://totp/Registry:admin@example.com?secret=jbsw%20y3dp%20ehpk3pxp")
+	require.NoError(t, err)
+	assert.Equal(t, &TOTP{Issuer: "Registry", AccountName: "admin@example.com", Algorithm: "SHA1", Digits: 6, Period: 30, Secret: "JBSWY3DPEHPK3PXP"}, totp)
+
+	totp, err = parseTOTP("otpauth://totp/admin?secret=JBSWY3DPEHPK3PXP&issuer=Appliance&algorithm=sha256&digits=8&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, &TOTP{Issuer: "Appliance", AccountName: "admin", Algorithm: "SHA256", Digits: 8, Period: 60, Secret: "JBSWY3DPEHPK3PXP"}, totp)
+
+	invalid := []string{
+		"https://example.com",
+		"otpauth://hotp/admin?secret=JBSWY3DPEHPK3PXP&counter=1",
+		"otpauth://totp/admin",
+		"otpauth://totp/admin?secret=not-base32!",
+		"otpauth://totp/admin?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/admin?secret=JBSWY3DPEHPK3PXP&digits=0",
+		"otpauth://totp/admin?secret=JBSWY3DPEHPK3PXP&period=-30",
+	}
+	for _, uri := range invalid {
+		_, err := parseTOTP(uri)
+		assert.ErrorIs(t, err, ErrMalformedField, uri)
+	}
+}
+
+// TestGetLoginTOTP tests that GetLogin computes the one-time code of the record at the time of the client clock.
+func TestGetLoginTOTP(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	record := fmt.Sprintf(`{
+	"uid": "login-uid",
+	"type": "login",
+	"fields": [
+		{"type": "login", "value": ["admin"]},
+		{"type": "oneTimeCode", "value": ["otpauth://totp/Console:admin?secret=%s&digits=8"]}
+	]
+}`, secret)
+
+	mockClient := &MockKeeperClient{
+		TestClient: &KSMClient{now: func() time.Time { return time.Unix(1111111109, 0) }},
+	}
+	mockClient.On("GetSecret").Return(recordFromJSON(record), nil)
+	client := NewClient(mockClient)
+
+	login, err := client.GetLogin(RecordQuery{Uid: "login-uid"})
+	require.NoError(t, err)
+	assert.Equal(t, "07081804", login.TOTPCode)
+	assert.Equal(t, 1, login.TOTPSecondsRemaining)
+	assert.Equal(t, TOTP{Issuer: "Console", AccountName: "admin", Algorithm: "SHA1", Digits: 8, Period: 30, Secret: secret}, login.TOTP)
+
+	// Logins without a one-time code have no TOTP outputs.
+	noTOTP := getMockedClient(`{"uid": "login-uid", "type": "login", "fields": [{"type": "oneTimeCode", "value": []}]}`)
+	login, err = noTOTP.GetLogin(RecordQuery{Uid: "login-uid"})
+	require.NoError(t, err)
+	assert.Empty(t, login.TOTPCode)
+	assert.Equal(t, TOTP{}, login.TOTP)
+}
+
+// TestGetLoginUnsupportedOTP tests that one-time codes the plugin can't compute are logged and leave the TOTP
+// outputs empty, the rest of the login is still returned.
+func TestGetLoginUnsupportedOTP(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	for _, uri := range []string{
+		"otpauth://hotp/Console:admin?secret=JBSWY3DPEHPK3PXP&counter=1",
+		"steam://JBSWY3DPEHPK3PXP",
+		" otpauth://totp/Console:admin?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/Console:admin?secret=not-base32!",
+	} {
+		record := fmt.Sprintf(`{
+	"uid": "login-uid",
+	"type": "login",
+	"fields": [
+		{"type": "login", "value": ["admin"]},
+		{"type": "password", "value": ["hunter2"]},
+		{"type": "oneTimeCode", "value": [%q]}
+	]
+}`, uri)
+
+		login, err := getMockedClient(record).GetLogin(RecordQuery{Uid: "login-uid"})
+		require.NoError(t, err, uri)
+		assert.Equal(t, "admin", login.Login)
+		assert.Equal(t, "hunter2", login.Password)
+		assert.Empty(t, login.TOTPCode)
+		assert.Equal(t, TOTP{}, login.TOTP)
+	}
+
+	assert.Equal(t, 4, strings.Count(logs.String(), "[WARN] record login-uid: unable to compute its one-time code"))
+	assert.NotContains(t, logs.String(), "JBSWY3DPEHPK3PXP")
+}
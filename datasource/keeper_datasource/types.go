@@ -1,8 +1,10 @@
 //go:generate packer-sdc struct-markdown
-//go:generate packer-sdc mapstructure-to-hcl2 -type KeeperLogin,FileRef,KeeperEncryptedNote,KeeperFile,KeeperRecordField,KeeperSoftwareLicense,KeeperSSHKey,KeyPair,HostConnection,KeeperServerCredentials,KeeperDataBaseCredentials,Config
+//go:generate packer-sdc mapstructure-to-hcl2 -type KeeperLogin,TOTP,FileRef,KeeperEncryptedNote,KeeperFile,KeeperRecordField,KeeperSoftwareLicense,KeeperSSHKey,KeyPair,HostConnection,KeeperServerCredentials,KeeperDataBaseCredentials,KeeperRecord,RecordField,KeeperPamMachine,KeeperPamDatabase,KeeperPamDirectory,KeeperPamUser,PamUser,RotationSchedule,KeeperCertificate,FileDestination,ClientConfig,Config
 
 package keeper_datasource
 
+import "time"
+
 type KeeperRecordField struct {
 	// uid is the unique identifier for the record .
 	Uid string `mapstructure:"uid"`
@@ -14,6 +16,14 @@ type KeeperRecordField struct {
 	Notes string `mapstructure:"notes"`
 	// FileRefs contain the list of file references associated with a record. See [FileRef](#nested-schema-for-fileref)
 	FileRefs []FileRef `mapstructure:"file_refs"`
+	// file_paths maps the name of each attachment written to `destination_dir` to the path of the file.
+	FilePaths map[string]string `mapstructure:"file_paths"`
+	// custom_fields maps the label of each custom field of the record, or its type when it has no label,
+	// to the values of the field. When more than one custom field has the same key the first field is used.
+	CustomFields CustomFields `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined"`
+
+	// customSecrets are the values of the custom fields holding sensitive values.
+	customSecrets []string
 }
 
 type KeeperLogin struct {
@@ -22,8 +32,33 @@ type KeeperLogin struct {
 	Login string `mapstructure:"login"`
 	// password is the password of the record.
 	Password string `mapstructure:"password"`
-	// url is the url associated withthe record.
+	// url is the url associated withthe record. When the record has more than one url,
+	// `field_index` and `field_label` select which one is used.
 	Url string `mapstructure:"url"`
+	// urls are all the urls of the record, from the standard url field followed by custom url fields.
+	Urls []string `mapstructure:"urls"`
+	// totp are the parameters of the one-time code field of the record, empty when it has none.
+	// See [TOTP](#nested-schema-for-totp)
+	TOTP TOTP `mapstructure:"totp"`
+	// totp_code is the current one-time code, computed when the datasource is read.
+	TOTPCode string `mapstructure:"totp_code"`
+	// totp_seconds_remaining is how many seconds `totp_code` stays valid for.
+	TOTPSecondsRemaining int `mapstructure:"totp_seconds_remaining"`
+}
+
+type TOTP struct {
+	// issuer is the service the one-time codes are for.
+	Issuer string `mapstructure:"issuer"`
+	// account_name is the account the one-time codes are for.
+	AccountName string `mapstructure:"account_name"`
+	// algorithm is the HMAC algorithm of the codes: `SHA1`, `SHA256` or `SHA512`.
+	Algorithm string `mapstructure:"algorithm"`
+	// digits is the number of digits of the codes.
+	Digits int `mapstructure:"digits"`
+	// period is how many seconds each code is valid for.
+	Period int `mapstructure:"period"`
+	// secret is the base32 encoded shared secret the codes are computed from.
+	Secret string `mapstructure:"secret"`
 }
 
 type FileRef struct {
@@ -39,8 +74,15 @@ type FileRef struct {
 	Size int `mapstructure:"size"`
 	// last_modified is the last modified date of the file .
 	LastModified int `mapstructure:"last_modified"`
-	// content_base64 is the base64 encoded content of the file .
+	// content_base64 is the base64 encoded content of the file, empty when the file isn't downloaded or is written
+	// to `destination_dir`.
 	Base64Data string `mapstructure:"content_base64"`
+	// path is the file the attachment was written to when `destination_dir` is set.
+	Path string `mapstructure:"path"`
+	// sha256 is the hex encoded SHA-256 digest of the content of the file, empty when the file isn't downloaded.
+	SHA256 string `mapstructure:"sha256"`
+	// sha512 is the hex encoded SHA-512 digest of the content of the file, empty when the file isn't downloaded.
+	SHA512 string `mapstructure:"sha512"`
 }
 
 type KeeperEncryptedNote struct {
@@ -67,16 +109,42 @@ type KeeperSoftwareLicense struct {
 
 type KeeperSSHKey struct {
 	KeeperRecordField `mapstructure:",squash"`
-	Login             string         `mapstructure:"login"`
-	Passphrase        string         `mapstructure:"passphrase"`
-	KeyPair           KeyPair        `mapstructure:"key_pair"`
-	HostConnection    HostConnection `mapstructure:"connection_details"`
+	// login is the username of the record.
+	Login string `mapstructure:"login"`
+	// passphrase is the passphrase of the private key, used to decrypt encrypted private keys.
+	Passphrase string `mapstructure:"passphrase"`
+	// key_pair is the key pair of the record selected by `field_index` and `field_label`.
+	// See [KeyPair](#nested-schema-for-keypair)
+	KeyPair KeyPair `mapstructure:"key_pair"`
+	// connection_details is the first host of the record. See [HostConnection](#nested-schema-for-hostconnection)
+	HostConnection HostConnection `mapstructure:"connection_details"`
+	// key_pairs are all the key pairs of the record. `field_index` and `field_label` select
+	// which one is used for `key_pair`. See [KeyPair](#nested-schema-for-keypair)
+	KeyPairs []KeyPair `mapstructure:"key_pairs"`
+	// hosts are all the hosts of the record. See [HostConnection](#nested-schema-for-hostconnection)
+	Hosts []HostConnection `mapstructure:"hosts"`
 }
 
 type KeyPair struct {
-	// Fixed: Changed mapstructure tag from "passphrase" to "public_key" to match field name
-	PublicKey  string `mapstructure:"public_key"`
+	// public_key is the public key in authorized_keys format, derived from the private key when the record doesn't have it.
+	PublicKey string `mapstructure:"public_key"`
+	// private_key is the private key as stored in the record, it can be encrypted with the passphrase.
 	PrivateKey string `mapstructure:"private_key"`
+	// key_type is the SSH type of the key (ex: `ssh-ed25519`, `ssh-rsa`, `ecdsa-sha2-nistp256`).
+	KeyType string `mapstructure:"key_type"`
+	// bits is the size of the key in bits.
+	Bits int `mapstructure:"bits"`
+	// fingerprint_sha256 is the SHA256 fingerprint of the key, as shown by `ssh-keygen -l` (ex: `SHA256:...`).
+	FingerprintSHA256 string `mapstructure:"fingerprint_sha256"`
+	// fingerprint_md5 is the legacy MD5 fingerprint of the key, as hex pairs separated by colons.
+	FingerprintMD5 string `mapstructure:"fingerprint_md5"`
+	// private_key_openssh is the unencrypted private key in OpenSSH format.
+	PrivateKeyOpenSSH string `mapstructure:"private_key_openssh"`
+	// private_key_pkcs8 is the unencrypted private key in PKCS#8 PEM format.
+	PrivateKeyPKCS8 string `mapstructure:"private_key_pkcs8"`
+	// private_key_pem is the unencrypted private key in the traditional PEM format of its type (PKCS#1 for RSA
+	// keys, SEC 1 for ECDSA keys). Ed25519 keys have no traditional format and are returned in PKCS#8 format.
+	PrivateKeyPEM string `mapstructure:"private_key_pem"`
 }
 
 type HostConnection struct {
@@ -88,9 +156,13 @@ type HostConnection struct {
 
 type KeeperServerCredentials struct {
 	KeeperRecordField `mapstructure:",squash"`
-	// connection_details are the connection details to connect to the server.
+	// connection_details are the connection details to connect to the server. When the record has more
+	// than one host, `field_index` and `field_label` select which one is used.
 	// see [HostConnection](#nested-schema-for-hostconnection)
 	HostConnection HostConnection `mapstructure:"connection_details"`
+	// hosts are all the hosts of the record, from the standard host field followed by custom host fields.
+	// see [HostConnection](#nested-schema-for-hostconnection)
+	Hosts []HostConnection `mapstructure:"hosts"`
 	// login is the username used to connect to the server.
 	Login string `mapstructure:"login"`
 	// password is the password used to connect to the server.
@@ -99,9 +171,13 @@ type KeeperServerCredentials struct {
 
 type KeeperDataBaseCredentials struct {
 	KeeperRecordField `mapstructure:",squash"`
-	// connection_details are the connection details to connect to the server.
+	// connection_details are the connection details to connect to the server. When the record has more
+	// than one host, `field_index` and `field_label` select which one is used.
 	// see [HostConnection](#nested-schema-for-hostconnection)
 	HostConnection HostConnection `mapstructure:"connection_details"`
+	// hosts are all the hosts of the record, from the standard host field followed by custom host fields.
+	// see [HostConnection](#nested-schema-for-hostconnection)
+	Hosts []HostConnection `mapstructure:"hosts"`
 	// login is the username used to connect to the server.
 	Login string `mapstructure:"login"`
 	// password is the password used to connect to the server.
@@ -119,8 +195,337 @@ type KeeperAPIKey struct {
 	ClientSecret string `mapstructure:"client_secret"`
 }
 
+type KeeperRecord struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// fields contains every standard and custom field of the record in the order Keeper returns them.
+	// See [RecordField](#nested-schema-for-recordfield)
+	Fields []RecordField `mapstructure:"fields"`
+	// field_map maps the label of each field, or its type when it has no label, to the first value of the field.
+	// When more than one field has the same key the first field is used.
+	FieldMap map[string]string `mapstructure:"field_map"`
+}
+
+type RecordField struct {
+	// type is the type of the field (ex: login, password, host).
+	Type string `mapstructure:"type"`
+	// label is the label of the field, custom fields usually have one while standard fields often don't.
+	Label string `mapstructure:"label"`
+	// custom is true for fields in the custom section of the record.
+	Custom bool `mapstructure:"custom"`
+	// values are the values of the field. Values that aren't strings (ex: a host or a key pair) are JSON encoded.
+	Values []string `mapstructure:"values"`
+}
+
+type KeeperPamMachine struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// connection_details are the host name and port of the machine.
+	// see [HostConnection](#nested-schema-for-hostconnection)
+	HostConnection HostConnection `mapstructure:"connection_details"`
+	// operating_system is the operating system of the machine (ex: linux, windows).
+	OperatingSystem string `mapstructure:"operating_system"`
+	// ssl_verification is true when the certificate of the machine is verified when connecting to it.
+	SSLVerification bool `mapstructure:"ssl_verification"`
+	// provider_group is the cloud provider group (ex: an Azure resource group) of the machine.
+	ProviderGroup string `mapstructure:"provider_group"`
+	// provider_region is the cloud provider region of the machine.
+	ProviderRegion string `mapstructure:"provider_region"`
+	// rotation_schedule is the password rotation schedule of the record.
+	// See [RotationSchedule](#nested-schema-for-rotationschedule)
+	RotationSchedule RotationSchedule `mapstructure:"rotation_schedule"`
+	// users are the credentials of the PAM users linked to the machine that are shared with the KSM application.
+	// See [PamUser](#nested-schema-for-pamuser)
+	Users []PamUser `mapstructure:"users"`
+}
+
+type KeeperPamDatabase struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// connection_details are the host name and port of the database.
+	// see [HostConnection](#nested-schema-for-hostconnection)
+	HostConnection HostConnection `mapstructure:"connection_details"`
+	// database_type is the type of the database (ex: postgresql, mysql).
+	DatabaseType string `mapstructure:"database_type"`
+	// connect_database is the name of the database to connect to.
+	ConnectDatabase string `mapstructure:"connect_database"`
+	// ssl_verification is true when connections to the database use SSL.
+	SSLVerification bool `mapstructure:"ssl_verification"`
+	// provider_group is the cloud provider group (ex: an Azure resource group) of the database.
+	ProviderGroup string `mapstructure:"provider_group"`
+	// provider_region is the cloud provider region of the database.
+	ProviderRegion string `mapstructure:"provider_region"`
+	// rotation_schedule is the password rotation schedule of the record.
+	// See [RotationSchedule](#nested-schema-for-rotationschedule)
+	RotationSchedule RotationSchedule `mapstructure:"rotation_schedule"`
+	// users are the credentials of the PAM users linked to the database that are shared with the KSM application.
+	// See [PamUser](#nested-schema-for-pamuser)
+	Users []PamUser `mapstructure:"users"`
+}
+
+type KeeperPamDirectory struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// connection_details are the host name and port of the directory.
+	// see [HostConnection](#nested-schema-for-hostconnection)
+	HostConnection HostConnection `mapstructure:"connection_details"`
+	// directory_type is the type of the directory (ex: active_directory, openldap).
+	DirectoryType string `mapstructure:"directory_type"`
+	// domain_name is the domain name of the directory.
+	DomainName string `mapstructure:"domain_name"`
+	// ssl_verification is true when connections to the directory use SSL.
+	SSLVerification bool `mapstructure:"ssl_verification"`
+	// provider_group is the cloud provider group (ex: an Azure resource group) of the directory.
+	ProviderGroup string `mapstructure:"provider_group"`
+	// provider_region is the cloud provider region of the directory.
+	ProviderRegion string `mapstructure:"provider_region"`
+	// rotation_schedule is the password rotation schedule of the record.
+	// See [RotationSchedule](#nested-schema-for-rotationschedule)
+	RotationSchedule RotationSchedule `mapstructure:"rotation_schedule"`
+	// users are the credentials of the PAM users linked to the directory that are shared with the KSM application.
+	// See [PamUser](#nested-schema-for-pamuser)
+	Users []PamUser `mapstructure:"users"`
+}
+
+type KeeperPamUser struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// login is the username of the user.
+	Login string `mapstructure:"login"`
+	// password is the password of the user.
+	Password string `mapstructure:"password"`
+	// private_key is the PEM encoded private key of the user.
+	PrivateKey string `mapstructure:"private_key"`
+	// distinguished_name is the distinguished name of the user in its directory.
+	DistinguishedName string `mapstructure:"distinguished_name"`
+	// connect_database is the database the user connects to.
+	ConnectDatabase string `mapstructure:"connect_database"`
+	// managed is true when the user is managed by Keeper, which rotates its password.
+	Managed bool `mapstructure:"managed"`
+	// rotation_schedule is the password rotation schedule of the user.
+	// See [RotationSchedule](#nested-schema-for-rotationschedule)
+	RotationSchedule RotationSchedule `mapstructure:"rotation_schedule"`
+}
+
+type PamUser struct {
+	// uid is the unique identifier of the PAM user record.
+	Uid string `mapstructure:"uid"`
+	// title is the title of the PAM user record.
+	Title string `mapstructure:"title"`
+	// login is the username of the user.
+	Login string `mapstructure:"login"`
+	// password is the password of the user.
+	Password string `mapstructure:"password"`
+	// private_key is the PEM encoded private key of the user.
+	PrivateKey string `mapstructure:"private_key"`
+}
+
+type RotationSchedule struct {
+	// type is how often the password is rotated (ex: `DAILY`, `WEEKLY`, `MONTHLY` or `CRON`), empty when it isn't scheduled.
+	Type string `mapstructure:"type"`
+	// cron is the cron expression of `CRON` schedules.
+	Cron string `mapstructure:"cron"`
+	// time is the time of day the password is rotated at.
+	Time string `mapstructure:"time"`
+	// timezone is the time zone of `time`.
+	Timezone string `mapstructure:"timezone"`
+	// weekday is the day of the week of `WEEKLY` schedules.
+	Weekday string `mapstructure:"weekday"`
+	// interval_count is how many periods of `type` pass between rotations.
+	IntervalCount int `mapstructure:"interval_count"`
+}
+
+type KeeperCertificate struct {
+	KeeperRecordField `mapstructure:",squash"`
+	// file_name is the name of the attachment the certificate was read from.
+	FileName string `mapstructure:"file_name"`
+	// subject is the distinguished name of the certificate subject (ex: `CN=build.example.com,O=Example`).
+	Subject string `mapstructure:"subject"`
+	// issuer is the distinguished name of the certificate issuer.
+	Issuer string `mapstructure:"issuer"`
+	// serial_number is the hex encoded serial number of the certificate.
+	SerialNumber string `mapstructure:"serial_number"`
+	// sans are the subject alternative names of the certificate: DNS names, IP addresses, email addresses and URIs.
+	SANs []string `mapstructure:"sans"`
+	// not_before is the time the certificate becomes valid, in RFC 3339 format.
+	NotBefore string `mapstructure:"not_before"`
+	// not_after is the time the certificate expires, in RFC 3339 format.
+	NotAfter string `mapstructure:"not_after"`
+	// days_remaining is the number of whole days until the certificate expires.
+	DaysRemaining int `mapstructure:"days_remaining"`
+	// thumbprint_sha1 is the hex encoded SHA-1 digest of the certificate, as shown by the Windows certificate store.
+	ThumbprintSHA1 string `mapstructure:"thumbprint_sha1"`
+	// thumbprint_sha256 is the hex encoded SHA-256 digest of the certificate.
+	ThumbprintSHA256 string `mapstructure:"thumbprint_sha256"`
+	// certificate_pem is the PEM encoded certificate. When the attachment holds more than one certificate it is the
+	// certificate of the private key, or the first certificate.
+	Certificate string `mapstructure:"certificate_pem"`
+	// chain_pem are the other PEM encoded certificates of the attachment, usually the intermediate certificates.
+	Chain string `mapstructure:"chain_pem"`
+	// private_key_pem is the PEM encoded private key of the certificate, empty when the attachment has none.
+	// Keys of PKCS#12 bundles are returned unencrypted in PKCS#8 format.
+	PrivateKey string `mapstructure:"private_key_pem"`
+}
+
 type Config struct {
-	// Uid is the unique identifier for the record .
-	// required `true`
-	Uid *string `mapstructure:"uid" required:"true"`
+	ClientConfig `mapstructure:",squash"`
+	// Uid is the unique identifier for the record. Exactly one of `uid` or `title` must be set.
+	Uid *string `mapstructure:"uid"`
+	// Title is the title of the record, use it instead of `uid` to share templates across vaults
+	// where the same record has a different uid. The title must match exactly one record.
+	Title string `mapstructure:"title"`
+	// FolderUid limits the records matched by `title` to the ones in this folder.
+	FolderUid string `mapstructure:"folder_uid"`
+	// RecordType limits the records matched by `title` to the ones of this type (ex: `login`).
+	RecordType string `mapstructure:"record_type"`
+	// FieldIndex selects which entry of a multi-value field feeds the scalar output: the url of `keeper-login`,
+	// the `connection_details` of `keeper-server-credential` and `keeper-database-credential` or the `key_pair`
+	// of `keeper-ssh-key`. Entries are counted across the standard field and custom fields of the same type,
+	// after filtering by `field_label`. Defaults to `0`.
+	FieldIndex int `mapstructure:"field_index"`
+	// FieldLabel limits the entries `field_index` selects from to the ones of fields with this label.
+	FieldLabel string `mapstructure:"field_label"`
+	// StrictType makes datasources for a specific record type (ex: `keeper-login`) fail on records of other types.
+	// When `false` records of any type are read, outputs whose fields the record doesn't have are left empty.
+	// Defaults to `true`.
+	StrictType *bool `mapstructure:"strict_type"`
+	// AcceptTypes are record types accepted besides the type of the datasource (ex: `["login"]` for
+	// `keeper-server-credential`), for records converted between types or custom record types.
+	AcceptTypes []string `mapstructure:"accept_types"`
+	// DownloadFiles downloads the content of every attachment of the record. Defaults to `true` for `keeper-file`.
+	// Other datasources only list the metadata of attachments in `file_refs` unless `download_files`, `file_names`,
+	// `file_glob`, `max_file_size` or `destination_dir` is set.
+	DownloadFiles *bool `mapstructure:"download_files"`
+	// FileNames limits the attachments downloaded to the ones with these names.
+	FileNames []string `mapstructure:"file_names"`
+	// MaxFileSize skips downloading attachments larger than this size in bytes, their metadata is still listed.
+	// Downloads are also stopped once larger than this size, whatever size Keeper lists. Attachments are
+	// downloaded and decrypted in memory, so this is also the most memory a download can use.
+	MaxFileSize int64 `mapstructure:"max_file_size"`
+	// ExpectedSHA256 maps attachment names to the hex encoded SHA-256 digest of their approved content. The
+	// attachments are downloaded and the datasource fails when one is missing or its content doesn't match.
+	ExpectedSHA256 map[string]string `mapstructure:"expected_sha256"`
+	// VerifySize fails the datasource when the content downloaded for an attachment doesn't have the size
+	// Keeper lists for it.
+	VerifySize bool `mapstructure:"verify_size"`
+	// DestinationDir writes the attachments of the record to this directory instead of returning their content
+	// in `content_base64`, the directory is created when missing. The files are removed once Packer stops the
+	// plugin at the end of the build unless `keep` is set. Removal is best-effort, files can be left behind
+	// when the build is killed.
+	DestinationDir string `mapstructure:"destination_dir"`
+	// FileMode is the permissions of the files written to `destination_dir`. Defaults to `0600`.
+	FileMode string `mapstructure:"file_mode"`
+	// FileGlob limits the attachments downloaded, or written to `destination_dir`, to the ones whose name matches
+	// (ex: `*.msi`). When `file_names` is also set attachments matching either are downloaded.
+	FileGlob string `mapstructure:"file_glob"`
+	// Files set the name and permissions of the attachments they match. When `file` blocks are set only the
+	// attachments matching a block or `file_glob` are written. See [FileDestination](#nested-schema-for-filedestination)
+	Files []FileDestination `mapstructure:"file"`
+	// Keep leaves the files written to `destination_dir` on disk when the plugin exits.
+	Keep bool `mapstructure:"keep"`
+}
+
+type FileDestination struct {
+	// name is the name of the attachment, or a glob matching the names of attachments (ex: `*.pem`).
+	Name string `mapstructure:"name" required:"true"`
+	// destination_name is the name of the file the attachment is written to. Defaults to the name of the attachment.
+	DestinationName string `mapstructure:"destination_name"`
+	// mode is the permissions of the file, it overrides `file_mode`.
+	Mode string `mapstructure:"mode"`
+}
+
+// RecordQuery returns the query for the record the datasource reads.
+func (c *Config) RecordQuery() RecordQuery {
+	query := RecordQuery{
+		Title:      c.Title,
+		FolderUid:  c.FolderUid,
+		RecordType: c.RecordType,
+		Field: FieldSelector{
+			Index: c.FieldIndex,
+			Label: c.FieldLabel,
+		},
+		AcceptTypes: c.AcceptTypes,
+		AnyType:     c.StrictType != nil && !*c.StrictType,
+		Files:       c.fileOptions(),
+	}
+	if c.Uid != nil {
+		query.Uid = *c.Uid
+	}
+
+	return query
+}
+
+// ClientConfig contains the settings used to authenticate against Keeper Secrets Manager.
+// When none of the config attributes are set the plugin falls back to the
+// `KEEPER_CONFIG_FILE` and `KSM_CONFIG` environment variables.
+type ClientConfig struct {
+	// config_file is the path to a KSM config file. Takes precedence over the
+	// `KEEPER_CONFIG_FILE` and `KSM_CONFIG` environment variables.
+	ConfigFile string `mapstructure:"config_file"`
+	// config_read_only stops updates Keeper makes to the config (ex: a rotated server public key id)
+	// from being written back to `config_file`, `token_config_file` or `KEEPER_CONFIG_FILE`. Use this for configs on
+	// read-only mounts. Can also be enabled with the `KEEPER_CONFIG_READ_ONLY` environment variable.
+	ConfigReadOnly bool `mapstructure:"config_read_only"`
+	// config_base64 is the base64 encoded content of a KSM config. Takes precedence over the
+	// `KEEPER_CONFIG_FILE` and `KSM_CONFIG` environment variables.
+	ConfigBase64 string `mapstructure:"config_base64"`
+	// config_json is the raw JSON content of a KSM config. Takes precedence over the
+	// `KEEPER_CONFIG_FILE` and `KSM_CONFIG` environment variables.
+	ConfigJSON string `mapstructure:"config_json"`
+	// token is a KSM one-time access token (ex: `US:ONE_TIME_TOKEN`). The token is redeemed on first use
+	// and the resulting config is written to `token_config_file`. Falls back to the `KSM_TOKEN` environment variable.
+	Token string `mapstructure:"token"`
+	// token_config_file is the path the config redeemed from `token` is written to, with 0600 permissions.
+	// If the file already exists it is used instead of redeeming the token again, later builds can also
+	// load it with `config_file` or `KEEPER_CONFIG_FILE`. The SHA-256 of the token is written next to it, with
+	// a `.token-sha256` suffix, and builds configuring another token, or finding the file without its fingerprint, fail instead of reusing the file. Falls back to the `KEEPER_TOKEN_CONFIG_FILE` environment variable.
+	TokenConfigFile string `mapstructure:"token_config_file"`
+	// config_command is a command, and its arguments, that prints a KSM config to stdout as raw JSON or base64,
+	// similar to the AWS `credential_process` setting. The config is only kept in memory and is never logged.
+	// The command must not prompt for input, it runs without stdin in its own process group.
+	// Falls back to the `KEEPER_CONFIG_COMMAND` environment variable, whose value is split on whitespace.
+	ConfigCommand []string `mapstructure:"config_command"`
+	// config_command_timeout is how long `config_command` can run before it is killed. Defaults to `30s`.
+	// Falls back to the `KEEPER_CONFIG_COMMAND_TIMEOUT` environment variable.
+	ConfigCommandTimeout time.Duration `mapstructure:"config_command_timeout"`
+	// hostname overrides the Keeper server set in the KSM config, the override is never written back to the config.
+	// Accepts a region (`US`, `EU`, `AU`, `GOV`, `JP` or `CA`) or a host with an optional port (ex: `ksm.example.com:8443`).
+	// The `KSM_HOSTNAME` environment variable takes precedence when set.
+	Hostname string `mapstructure:"hostname"`
+	// proxy_url is the HTTP(S) or SOCKS5 proxy used to reach Keeper (ex: `http://proxy.example.com:3128`).
+	// Defaults to the `HTTPS_PROXY` and `NO_PROXY` environment variables.
+	ProxyURL string `mapstructure:"proxy_url"`
+	// ca_bundle_file is a PEM file with extra certificate authorities to trust when connecting to Keeper,
+	// for example the CA of a TLS inspecting proxy. The certificates are added to the system certificate pool.
+	CABundleFile string `mapstructure:"ca_bundle_file"`
+	// max_retries is how many times a Keeper request failing with a timeout, a refused or reset connection, a
+	// server error or throttling is retried. Defaults to `3`, set to `-1` to disable retries.
+	MaxRetries int `mapstructure:"max_retries"`
+	// retry_delay is the delay before the first retry, it doubles with each retry up to `retry_max_delay` and is
+	// randomized to spread out retries. Throttled requests wait as long as Keeper asks instead. Defaults to `1s`.
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+	// retry_max_delay is the longest delay between retries. Throttled requests Keeper asks to wait longer for fail
+	// instead of being retried. Defaults to `30s`.
+	RetryMaxDelay time.Duration `mapstructure:"retry_max_delay"`
+	// request_timeout is how long a single Keeper request, including reading the response, can take before it is
+	// cancelled and retried. Attachment downloads only wait this long for the download to start. Defaults to `30s`.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	// cache_dir enables the record cache and sets the directory it is stored in. Records fetched from Keeper are
+	// cached on disk, encrypted with a key derived from the KSM app key, so other datasources and later builds using
+	// the same KSM application reuse them. Falls back to the `KEEPER_CACHE_DIR` environment variable.
+	CacheDir string `mapstructure:"cache_dir"`
+	// cache_ttl is how long cached records are used before they are fetched from Keeper again. Defaults to `5m`.
+	// Falls back to the `KEEPER_CACHE_TTL` environment variable.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// cache_bypass fetches records from Keeper even when they are cached, the cache is still updated with
+	// the fetched records. Can also be enabled with the `KEEPER_CACHE_BYPASS` environment variable.
+	CacheBypass bool `mapstructure:"cache_bypass"`
+	// offline_fallback keeps an encrypted copy of the last successful Keeper response and serves records from it
+	// when Keeper can't be reached, logging a warning that stale secrets are used. The first datasource of the
+	// build, as told by the `PACKER_RUN_UUID` Packer sets for each run, fetches every record shared with the
+	// application so the copy holds all of them, later datasources only fetch the records they read. Without
+	// `PACKER_RUN_UUID` every datasource fetches every record. Can also be enabled with the
+	// `KEEPER_OFFLINE_FALLBACK` environment variable.
+	OfflineFallback bool `mapstructure:"offline_fallback"`
+	// offline_dir is the directory the offline copy is stored in. Defaults to `packer-plugin-keeper/offline` in the
+	// user cache directory. Falls back to the `KEEPER_OFFLINE_DIR` environment variable.
+	OfflineDir string `mapstructure:"offline_dir"`
+	// offline_max_staleness is the age past which the offline copy is no longer used. Defaults to `24h`.
+	// Falls back to the `KEEPER_OFFLINE_MAX_STALENESS` environment variable.
+	OfflineMaxStaleness time.Duration `mapstructure:"offline_max_staleness"`
 }
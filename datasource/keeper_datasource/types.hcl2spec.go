@@ -7,10 +7,111 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// FlatClientConfig is an auto-generated flat version of ClientConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatClientConfig struct {
+	ConfigFile           *string  `mapstructure:"config_file" cty:"config_file" hcl:"config_file"`
+	ConfigReadOnly       *bool    `mapstructure:"config_read_only" cty:"config_read_only" hcl:"config_read_only"`
+	ConfigBase64         *string  `mapstructure:"config_base64" cty:"config_base64" hcl:"config_base64"`
+	ConfigJSON           *string  `mapstructure:"config_json" cty:"config_json" hcl:"config_json"`
+	Token                *string  `mapstructure:"token" cty:"token" hcl:"token"`
+	TokenConfigFile      *string  `mapstructure:"token_config_file" cty:"token_config_file" hcl:"token_config_file"`
+	ConfigCommand        []string `mapstructure:"config_command" cty:"config_command" hcl:"config_command"`
+	ConfigCommandTimeout *string  `mapstructure:"config_command_timeout" cty:"config_command_timeout" hcl:"config_command_timeout"`
+	Hostname             *string  `mapstructure:"hostname" cty:"hostname" hcl:"hostname"`
+	ProxyURL             *string  `mapstructure:"proxy_url" cty:"proxy_url" hcl:"proxy_url"`
+	CABundleFile         *string  `mapstructure:"ca_bundle_file" cty:"ca_bundle_file" hcl:"ca_bundle_file"`
+	MaxRetries           *int     `mapstructure:"max_retries" cty:"max_retries" hcl:"max_retries"`
+	RetryDelay           *string  `mapstructure:"retry_delay" cty:"retry_delay" hcl:"retry_delay"`
+	RetryMaxDelay        *string  `mapstructure:"retry_max_delay" cty:"retry_max_delay" hcl:"retry_max_delay"`
+	RequestTimeout       *string  `mapstructure:"request_timeout" cty:"request_timeout" hcl:"request_timeout"`
+	CacheDir             *string  `mapstructure:"cache_dir" cty:"cache_dir" hcl:"cache_dir"`
+	CacheTTL             *string  `mapstructure:"cache_ttl" cty:"cache_ttl" hcl:"cache_ttl"`
+	CacheBypass          *bool    `mapstructure:"cache_bypass" cty:"cache_bypass" hcl:"cache_bypass"`
+	OfflineFallback      *bool    `mapstructure:"offline_fallback" cty:"offline_fallback" hcl:"offline_fallback"`
+	OfflineDir           *string  `mapstructure:"offline_dir" cty:"offline_dir" hcl:"offline_dir"`
+	OfflineMaxStaleness  *string  `mapstructure:"offline_max_staleness" cty:"offline_max_staleness" hcl:"offline_max_staleness"`
+}
+
+// FlatMapstructure returns a new FlatClientConfig.
+// FlatClientConfig is an auto-generated flat version of ClientConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ClientConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatClientConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ClientConfig.
+// This spec is used by HCL to read the fields of ClientConfig.
+// The decoded values from this spec will then be applied to a FlatClientConfig.
+func (*FlatClientConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"config_file":            &hcldec.AttrSpec{Name: "config_file", Type: cty.String, Required: false},
+		"config_read_only":       &hcldec.AttrSpec{Name: "config_read_only", Type: cty.Bool, Required: false},
+		"config_base64":          &hcldec.AttrSpec{Name: "config_base64", Type: cty.String, Required: false},
+		"config_json":            &hcldec.AttrSpec{Name: "config_json", Type: cty.String, Required: false},
+		"token":                  &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"token_config_file":      &hcldec.AttrSpec{Name: "token_config_file", Type: cty.String, Required: false},
+		"config_command":         &hcldec.AttrSpec{Name: "config_command", Type: cty.List(cty.String), Required: false},
+		"config_command_timeout": &hcldec.AttrSpec{Name: "config_command_timeout", Type: cty.String, Required: false},
+		"hostname":               &hcldec.AttrSpec{Name: "hostname", Type: cty.String, Required: false},
+		"proxy_url":              &hcldec.AttrSpec{Name: "proxy_url", Type: cty.String, Required: false},
+		"ca_bundle_file":         &hcldec.AttrSpec{Name: "ca_bundle_file", Type: cty.String, Required: false},
+		"max_retries":            &hcldec.AttrSpec{Name: "max_retries", Type: cty.Number, Required: false},
+		"retry_delay":            &hcldec.AttrSpec{Name: "retry_delay", Type: cty.String, Required: false},
+		"retry_max_delay":        &hcldec.AttrSpec{Name: "retry_max_delay", Type: cty.String, Required: false},
+		"request_timeout":        &hcldec.AttrSpec{Name: "request_timeout", Type: cty.String, Required: false},
+		"cache_dir":              &hcldec.AttrSpec{Name: "cache_dir", Type: cty.String, Required: false},
+		"cache_ttl":              &hcldec.AttrSpec{Name: "cache_ttl", Type: cty.String, Required: false},
+		"cache_bypass":           &hcldec.AttrSpec{Name: "cache_bypass", Type: cty.Bool, Required: false},
+		"offline_fallback":       &hcldec.AttrSpec{Name: "offline_fallback", Type: cty.Bool, Required: false},
+		"offline_dir":            &hcldec.AttrSpec{Name: "offline_dir", Type: cty.String, Required: false},
+		"offline_max_staleness":  &hcldec.AttrSpec{Name: "offline_max_staleness", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	Uid *string `mapstructure:"uid" required:"true" cty:"uid" hcl:"uid"`
+	ConfigFile           *string               `mapstructure:"config_file" cty:"config_file" hcl:"config_file"`
+	ConfigReadOnly       *bool                 `mapstructure:"config_read_only" cty:"config_read_only" hcl:"config_read_only"`
+	ConfigBase64         *string               `mapstructure:"config_base64" cty:"config_base64" hcl:"config_base64"`
+	ConfigJSON           *string               `mapstructure:"config_json" cty:"config_json" hcl:"config_json"`
+	Token                *string               `mapstructure:"token" cty:"token" hcl:"token"`
+	TokenConfigFile      *string               `mapstructure:"token_config_file" cty:"token_config_file" hcl:"token_config_file"`
+	ConfigCommand        []string              `mapstructure:"config_command" cty:"config_command" hcl:"config_command"`
+	ConfigCommandTimeout *string               `mapstructure:"config_command_timeout" cty:"config_command_timeout" hcl:"config_command_timeout"`
+	Hostname             *string               `mapstructure:"hostname" cty:"hostname" hcl:"hostname"`
+	ProxyURL             *string               `mapstructure:"proxy_url" cty:"proxy_url" hcl:"proxy_url"`
+	CABundleFile         *string               `mapstructure:"ca_bundle_file" cty:"ca_bundle_file" hcl:"ca_bundle_file"`
+	MaxRetries           *int                  `mapstructure:"max_retries" cty:"max_retries" hcl:"max_retries"`
+	RetryDelay           *string               `mapstructure:"retry_delay" cty:"retry_delay" hcl:"retry_delay"`
+	RetryMaxDelay        *string               `mapstructure:"retry_max_delay" cty:"retry_max_delay" hcl:"retry_max_delay"`
+	RequestTimeout       *string               `mapstructure:"request_timeout" cty:"request_timeout" hcl:"request_timeout"`
+	CacheDir             *string               `mapstructure:"cache_dir" cty:"cache_dir" hcl:"cache_dir"`
+	CacheTTL             *string               `mapstructure:"cache_ttl" cty:"cache_ttl" hcl:"cache_ttl"`
+	CacheBypass          *bool                 `mapstructure:"cache_bypass" cty:"cache_bypass" hcl:"cache_bypass"`
+	OfflineFallback      *bool                 `mapstructure:"offline_fallback" cty:"offline_fallback" hcl:"offline_fallback"`
+	OfflineDir           *string               `mapstructure:"offline_dir" cty:"offline_dir" hcl:"offline_dir"`
+	OfflineMaxStaleness  *string               `mapstructure:"offline_max_staleness" cty:"offline_max_staleness" hcl:"offline_max_staleness"`
+	Uid                  *string               `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Title                *string               `mapstructure:"title" cty:"title" hcl:"title"`
+	FolderUid            *string               `mapstructure:"folder_uid" cty:"folder_uid" hcl:"folder_uid"`
+	RecordType           *string               `mapstructure:"record_type" cty:"record_type" hcl:"record_type"`
+	FieldIndex           *int                  `mapstructure:"field_index" cty:"field_index" hcl:"field_index"`
+	FieldLabel           *string               `mapstructure:"field_label" cty:"field_label" hcl:"field_label"`
+	StrictType           *bool                 `mapstructure:"strict_type" cty:"strict_type" hcl:"strict_type"`
+	AcceptTypes          []string              `mapstructure:"accept_types" cty:"accept_types" hcl:"accept_types"`
+	DownloadFiles        *bool                 `mapstructure:"download_files" cty:"download_files" hcl:"download_files"`
+	FileNames            []string              `mapstructure:"file_names" cty:"file_names" hcl:"file_names"`
+	MaxFileSize          *int64                `mapstructure:"max_file_size" cty:"max_file_size" hcl:"max_file_size"`
+	ExpectedSHA256       map[string]string     `mapstructure:"expected_sha256" cty:"expected_sha256" hcl:"expected_sha256"`
+	VerifySize           *bool                 `mapstructure:"verify_size" cty:"verify_size" hcl:"verify_size"`
+	DestinationDir       *string               `mapstructure:"destination_dir" cty:"destination_dir" hcl:"destination_dir"`
+	FileMode             *string               `mapstructure:"file_mode" cty:"file_mode" hcl:"file_mode"`
+	FileGlob             *string               `mapstructure:"file_glob" cty:"file_glob" hcl:"file_glob"`
+	Files                []FlatFileDestination `mapstructure:"file" cty:"file" hcl:"file"`
+	Keep                 *bool                 `mapstructure:"keep" cty:"keep" hcl:"keep"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -25,7 +126,72 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid": &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"config_file":            &hcldec.AttrSpec{Name: "config_file", Type: cty.String, Required: false},
+		"config_read_only":       &hcldec.AttrSpec{Name: "config_read_only", Type: cty.Bool, Required: false},
+		"config_base64":          &hcldec.AttrSpec{Name: "config_base64", Type: cty.String, Required: false},
+		"config_json":            &hcldec.AttrSpec{Name: "config_json", Type: cty.String, Required: false},
+		"token":                  &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"token_config_file":      &hcldec.AttrSpec{Name: "token_config_file", Type: cty.String, Required: false},
+		"config_command":         &hcldec.AttrSpec{Name: "config_command", Type: cty.List(cty.String), Required: false},
+		"config_command_timeout": &hcldec.AttrSpec{Name: "config_command_timeout", Type: cty.String, Required: false},
+		"hostname":               &hcldec.AttrSpec{Name: "hostname", Type: cty.String, Required: false},
+		"proxy_url":              &hcldec.AttrSpec{Name: "proxy_url", Type: cty.String, Required: false},
+		"ca_bundle_file":         &hcldec.AttrSpec{Name: "ca_bundle_file", Type: cty.String, Required: false},
+		"max_retries":            &hcldec.AttrSpec{Name: "max_retries", Type: cty.Number, Required: false},
+		"retry_delay":            &hcldec.AttrSpec{Name: "retry_delay", Type: cty.String, Required: false},
+		"retry_max_delay":        &hcldec.AttrSpec{Name: "retry_max_delay", Type: cty.String, Required: false},
+		"request_timeout":        &hcldec.AttrSpec{Name: "request_timeout", Type: cty.String, Required: false},
+		"cache_dir":              &hcldec.AttrSpec{Name: "cache_dir", Type: cty.String, Required: false},
+		"cache_ttl":              &hcldec.AttrSpec{Name: "cache_ttl", Type: cty.String, Required: false},
+		"cache_bypass":           &hcldec.AttrSpec{Name: "cache_bypass", Type: cty.Bool, Required: false},
+		"offline_fallback":       &hcldec.AttrSpec{Name: "offline_fallback", Type: cty.Bool, Required: false},
+		"offline_dir":            &hcldec.AttrSpec{Name: "offline_dir", Type: cty.String, Required: false},
+		"offline_max_staleness":  &hcldec.AttrSpec{Name: "offline_max_staleness", Type: cty.String, Required: false},
+		"uid":                    &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"title":                  &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"folder_uid":             &hcldec.AttrSpec{Name: "folder_uid", Type: cty.String, Required: false},
+		"record_type":            &hcldec.AttrSpec{Name: "record_type", Type: cty.String, Required: false},
+		"field_index":            &hcldec.AttrSpec{Name: "field_index", Type: cty.Number, Required: false},
+		"field_label":            &hcldec.AttrSpec{Name: "field_label", Type: cty.String, Required: false},
+		"strict_type":            &hcldec.AttrSpec{Name: "strict_type", Type: cty.Bool, Required: false},
+		"accept_types":           &hcldec.AttrSpec{Name: "accept_types", Type: cty.List(cty.String), Required: false},
+		"download_files":         &hcldec.AttrSpec{Name: "download_files", Type: cty.Bool, Required: false},
+		"file_names":             &hcldec.AttrSpec{Name: "file_names", Type: cty.List(cty.String), Required: false},
+		"max_file_size":          &hcldec.AttrSpec{Name: "max_file_size", Type: cty.Number, Required: false},
+		"expected_sha256":        &hcldec.AttrSpec{Name: "expected_sha256", Type: cty.Map(cty.String), Required: false},
+		"verify_size":            &hcldec.AttrSpec{Name: "verify_size", Type: cty.Bool, Required: false},
+		"destination_dir":        &hcldec.AttrSpec{Name: "destination_dir", Type: cty.String, Required: false},
+		"file_mode":              &hcldec.AttrSpec{Name: "file_mode", Type: cty.String, Required: false},
+		"file_glob":              &hcldec.AttrSpec{Name: "file_glob", Type: cty.String, Required: false},
+		"file":                   &hcldec.BlockListSpec{TypeName: "file", Nested: hcldec.ObjectSpec((*FlatFileDestination)(nil).HCL2Spec())},
+		"keep":                   &hcldec.AttrSpec{Name: "keep", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatFileDestination is an auto-generated flat version of FileDestination.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatFileDestination struct {
+	Name            *string `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	DestinationName *string `mapstructure:"destination_name" cty:"destination_name" hcl:"destination_name"`
+	Mode            *string `mapstructure:"mode" cty:"mode" hcl:"mode"`
+}
+
+// FlatMapstructure returns a new FlatFileDestination.
+// FlatFileDestination is an auto-generated flat version of FileDestination.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*FileDestination) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatFileDestination)
+}
+
+// HCL2Spec returns the hcl spec of a FileDestination.
+// This spec is used by HCL to read the fields of FileDestination.
+// The decoded values from this spec will then be applied to a FlatFileDestination.
+func (*FlatFileDestination) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":             &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"destination_name": &hcldec.AttrSpec{Name: "destination_name", Type: cty.String, Required: false},
+		"mode":             &hcldec.AttrSpec{Name: "mode", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -40,6 +206,9 @@ type FlatFileRef struct {
 	Size         *int    `mapstructure:"size" cty:"size" hcl:"size"`
 	LastModified *int    `mapstructure:"last_modified" cty:"last_modified" hcl:"last_modified"`
 	Base64Data   *string `mapstructure:"content_base64" cty:"content_base64" hcl:"content_base64"`
+	Path         *string `mapstructure:"path" cty:"path" hcl:"path"`
+	SHA256       *string `mapstructure:"sha256" cty:"sha256" hcl:"sha256"`
+	SHA512       *string `mapstructure:"sha512" cty:"sha512" hcl:"sha512"`
 }
 
 // FlatMapstructure returns a new FlatFileRef.
@@ -61,6 +230,9 @@ func (*FlatFileRef) HCL2Spec() map[string]hcldec.Spec {
 		"size":           &hcldec.AttrSpec{Name: "size", Type: cty.Number, Required: false},
 		"last_modified":  &hcldec.AttrSpec{Name: "last_modified", Type: cty.Number, Required: false},
 		"content_base64": &hcldec.AttrSpec{Name: "content_base64", Type: cty.String, Required: false},
+		"path":           &hcldec.AttrSpec{Name: "path", Type: cty.String, Required: false},
+		"sha256":         &hcldec.AttrSpec{Name: "sha256", Type: cty.String, Required: false},
+		"sha512":         &hcldec.AttrSpec{Name: "sha512", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -90,18 +262,82 @@ func (*FlatHostConnection) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatKeeperCertificate is an auto-generated flat version of KeeperCertificate.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperCertificate struct {
+	Uid              *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type             *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title            *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes            *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs         []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths        map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields     CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	FileName         *string           `mapstructure:"file_name" cty:"file_name" hcl:"file_name"`
+	Subject          *string           `mapstructure:"subject" cty:"subject" hcl:"subject"`
+	Issuer           *string           `mapstructure:"issuer" cty:"issuer" hcl:"issuer"`
+	SerialNumber     *string           `mapstructure:"serial_number" cty:"serial_number" hcl:"serial_number"`
+	SANs             []string          `mapstructure:"sans" cty:"sans" hcl:"sans"`
+	NotBefore        *string           `mapstructure:"not_before" cty:"not_before" hcl:"not_before"`
+	NotAfter         *string           `mapstructure:"not_after" cty:"not_after" hcl:"not_after"`
+	DaysRemaining    *int              `mapstructure:"days_remaining" cty:"days_remaining" hcl:"days_remaining"`
+	ThumbprintSHA1   *string           `mapstructure:"thumbprint_sha1" cty:"thumbprint_sha1" hcl:"thumbprint_sha1"`
+	ThumbprintSHA256 *string           `mapstructure:"thumbprint_sha256" cty:"thumbprint_sha256" hcl:"thumbprint_sha256"`
+	Certificate      *string           `mapstructure:"certificate_pem" cty:"certificate_pem" hcl:"certificate_pem"`
+	Chain            *string           `mapstructure:"chain_pem" cty:"chain_pem" hcl:"chain_pem"`
+	PrivateKey       *string           `mapstructure:"private_key_pem" cty:"private_key_pem" hcl:"private_key_pem"`
+}
+
+// FlatMapstructure returns a new FlatKeeperCertificate.
+// FlatKeeperCertificate is an auto-generated flat version of KeeperCertificate.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperCertificate) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperCertificate)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperCertificate.
+// This spec is used by HCL to read the fields of KeeperCertificate.
+// The decoded values from this spec will then be applied to a FlatKeeperCertificate.
+func (*FlatKeeperCertificate) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":               &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":              &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":             &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":             &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":         &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":        &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":     (&CustomFields{}).HCL2Spec(),
+		"file_name":         &hcldec.AttrSpec{Name: "file_name", Type: cty.String, Required: false},
+		"subject":           &hcldec.AttrSpec{Name: "subject", Type: cty.String, Required: false},
+		"issuer":            &hcldec.AttrSpec{Name: "issuer", Type: cty.String, Required: false},
+		"serial_number":     &hcldec.AttrSpec{Name: "serial_number", Type: cty.String, Required: false},
+		"sans":              &hcldec.AttrSpec{Name: "sans", Type: cty.List(cty.String), Required: false},
+		"not_before":        &hcldec.AttrSpec{Name: "not_before", Type: cty.String, Required: false},
+		"not_after":         &hcldec.AttrSpec{Name: "not_after", Type: cty.String, Required: false},
+		"days_remaining":    &hcldec.AttrSpec{Name: "days_remaining", Type: cty.Number, Required: false},
+		"thumbprint_sha1":   &hcldec.AttrSpec{Name: "thumbprint_sha1", Type: cty.String, Required: false},
+		"thumbprint_sha256": &hcldec.AttrSpec{Name: "thumbprint_sha256", Type: cty.String, Required: false},
+		"certificate_pem":   &hcldec.AttrSpec{Name: "certificate_pem", Type: cty.String, Required: false},
+		"chain_pem":         &hcldec.AttrSpec{Name: "chain_pem", Type: cty.String, Required: false},
+		"private_key_pem":   &hcldec.AttrSpec{Name: "private_key_pem", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatKeeperDataBaseCredentials is an auto-generated flat version of KeeperDataBaseCredentials.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperDataBaseCredentials struct {
-	Uid            *string             `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type           *string             `mapstructure:"type" cty:"type" hcl:"type"`
-	Title          *string             `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes          *string             `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs       []FlatFileRef       `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	HostConnection *FlatHostConnection `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
-	Login          *string             `mapstructure:"login" cty:"login" hcl:"login"`
-	Password       *string             `mapstructure:"password" cty:"password" hcl:"password"`
-	DbType         *string             `mapstructure:"db_type" cty:"db_type" hcl:"db_type"`
+	Uid            *string              `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type           *string              `mapstructure:"type" cty:"type" hcl:"type"`
+	Title          *string              `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes          *string              `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs       []FlatFileRef        `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string    `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   CustomFields         `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection *FlatHostConnection  `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	Hosts          []FlatHostConnection `mapstructure:"hosts" cty:"hosts" hcl:"hosts"`
+	Login          *string              `mapstructure:"login" cty:"login" hcl:"login"`
+	Password       *string              `mapstructure:"password" cty:"password" hcl:"password"`
+	DbType         *string              `mapstructure:"db_type" cty:"db_type" hcl:"db_type"`
 }
 
 // FlatMapstructure returns a new FlatKeeperDataBaseCredentials.
@@ -121,7 +357,10 @@ func (*FlatKeeperDataBaseCredentials) HCL2Spec() map[string]hcldec.Spec {
 		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
 		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"hosts":              &hcldec.BlockListSpec{TypeName: "hosts", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
 		"login":              &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
 		"password":           &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
 		"db_type":            &hcldec.AttrSpec{Name: "db_type", Type: cty.String, Required: false},
@@ -132,13 +371,15 @@ func (*FlatKeeperDataBaseCredentials) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperEncryptedNote is an auto-generated flat version of KeeperEncryptedNote.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperEncryptedNote struct {
-	Uid      *string       `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string       `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string       `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string       `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	Note     *string       `mapstructure:"note" cty:"note" hcl:"note"`
-	Date     *string       `mapstructure:"date" cty:"date" hcl:"date"`
+	Uid          *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type         *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title        *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes        *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs     []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Note         *string           `mapstructure:"note" cty:"note" hcl:"note"`
+	Date         *string           `mapstructure:"date" cty:"date" hcl:"date"`
 }
 
 // FlatMapstructure returns a new FlatKeeperEncryptedNote.
@@ -153,13 +394,15 @@ func (*KeeperEncryptedNote) FlatMapstructure() interface{ HCL2Spec() map[string]
 // The decoded values from this spec will then be applied to a FlatKeeperEncryptedNote.
 func (*FlatKeeperEncryptedNote) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
-		"note":      &hcldec.AttrSpec{Name: "note", Type: cty.String, Required: false},
-		"date":      &hcldec.AttrSpec{Name: "date", Type: cty.String, Required: false},
+		"uid":           &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":          &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&CustomFields{}).HCL2Spec(),
+		"note":          &hcldec.AttrSpec{Name: "note", Type: cty.String, Required: false},
+		"date":          &hcldec.AttrSpec{Name: "date", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -167,11 +410,13 @@ func (*FlatKeeperEncryptedNote) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperFile is an auto-generated flat version of KeeperFile.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperFile struct {
-	Uid      *string       `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string       `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string       `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string       `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	Uid          *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type         *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title        *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes        *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs     []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
 }
 
 // FlatMapstructure returns a new FlatKeeperFile.
@@ -186,11 +431,13 @@ func (*KeeperFile) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Sp
 // The decoded values from this spec will then be applied to a FlatKeeperFile.
 func (*FlatKeeperFile) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"uid":           &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":          &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&CustomFields{}).HCL2Spec(),
 	}
 	return s
 }
@@ -198,14 +445,20 @@ func (*FlatKeeperFile) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperLogin is an auto-generated flat version of KeeperLogin.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperLogin struct {
-	Uid      *string       `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string       `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string       `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string       `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	Login    *string       `mapstructure:"login" cty:"login" hcl:"login"`
-	Password *string       `mapstructure:"password" cty:"password" hcl:"password"`
-	Url      *string       `mapstructure:"url" cty:"url" hcl:"url"`
+	Uid                  *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type                 *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title                *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes                *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs             []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths            map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields         CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Login                *string           `mapstructure:"login" cty:"login" hcl:"login"`
+	Password             *string           `mapstructure:"password" cty:"password" hcl:"password"`
+	Url                  *string           `mapstructure:"url" cty:"url" hcl:"url"`
+	Urls                 []string          `mapstructure:"urls" cty:"urls" hcl:"urls"`
+	TOTP                 *FlatTOTP         `mapstructure:"totp" cty:"totp" hcl:"totp"`
+	TOTPCode             *string           `mapstructure:"totp_code" cty:"totp_code" hcl:"totp_code"`
+	TOTPSecondsRemaining *int              `mapstructure:"totp_seconds_remaining" cty:"totp_seconds_remaining" hcl:"totp_seconds_remaining"`
 }
 
 // FlatMapstructure returns a new FlatKeeperLogin.
@@ -220,14 +473,259 @@ func (*KeeperLogin) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.S
 // The decoded values from this spec will then be applied to a FlatKeeperLogin.
 func (*FlatKeeperLogin) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
-		"login":     &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
-		"password":  &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
-		"url":       &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"uid":                    &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":                   &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":                  &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":                  &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":              &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":             &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":          (&CustomFields{}).HCL2Spec(),
+		"login":                  &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
+		"password":               &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
+		"url":                    &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"urls":                   &hcldec.AttrSpec{Name: "urls", Type: cty.List(cty.String), Required: false},
+		"totp":                   &hcldec.BlockSpec{TypeName: "totp", Nested: hcldec.ObjectSpec((*FlatTOTP)(nil).HCL2Spec())},
+		"totp_code":              &hcldec.AttrSpec{Name: "totp_code", Type: cty.String, Required: false},
+		"totp_seconds_remaining": &hcldec.AttrSpec{Name: "totp_seconds_remaining", Type: cty.Number, Required: false},
+	}
+	return s
+}
+
+// FlatKeeperPamDatabase is an auto-generated flat version of KeeperPamDatabase.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperPamDatabase struct {
+	Uid              *string               `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type             *string               `mapstructure:"type" cty:"type" hcl:"type"`
+	Title            *string               `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes            *string               `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs         []FlatFileRef         `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths        map[string]string     `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields     CustomFields          `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection   *FlatHostConnection   `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	DatabaseType     *string               `mapstructure:"database_type" cty:"database_type" hcl:"database_type"`
+	ConnectDatabase  *string               `mapstructure:"connect_database" cty:"connect_database" hcl:"connect_database"`
+	SSLVerification  *bool                 `mapstructure:"ssl_verification" cty:"ssl_verification" hcl:"ssl_verification"`
+	ProviderGroup    *string               `mapstructure:"provider_group" cty:"provider_group" hcl:"provider_group"`
+	ProviderRegion   *string               `mapstructure:"provider_region" cty:"provider_region" hcl:"provider_region"`
+	RotationSchedule *FlatRotationSchedule `mapstructure:"rotation_schedule" cty:"rotation_schedule" hcl:"rotation_schedule"`
+	Users            []FlatPamUser         `mapstructure:"users" cty:"users" hcl:"users"`
+}
+
+// FlatMapstructure returns a new FlatKeeperPamDatabase.
+// FlatKeeperPamDatabase is an auto-generated flat version of KeeperPamDatabase.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperPamDatabase) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperPamDatabase)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperPamDatabase.
+// This spec is used by HCL to read the fields of KeeperPamDatabase.
+// The decoded values from this spec will then be applied to a FlatKeeperPamDatabase.
+func (*FlatKeeperPamDatabase) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":                &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":               &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
+		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"database_type":      &hcldec.AttrSpec{Name: "database_type", Type: cty.String, Required: false},
+		"connect_database":   &hcldec.AttrSpec{Name: "connect_database", Type: cty.String, Required: false},
+		"ssl_verification":   &hcldec.AttrSpec{Name: "ssl_verification", Type: cty.Bool, Required: false},
+		"provider_group":     &hcldec.AttrSpec{Name: "provider_group", Type: cty.String, Required: false},
+		"provider_region":    &hcldec.AttrSpec{Name: "provider_region", Type: cty.String, Required: false},
+		"rotation_schedule":  &hcldec.BlockSpec{TypeName: "rotation_schedule", Nested: hcldec.ObjectSpec((*FlatRotationSchedule)(nil).HCL2Spec())},
+		"users":              &hcldec.BlockListSpec{TypeName: "users", Nested: hcldec.ObjectSpec((*FlatPamUser)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatKeeperPamDirectory is an auto-generated flat version of KeeperPamDirectory.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperPamDirectory struct {
+	Uid              *string               `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type             *string               `mapstructure:"type" cty:"type" hcl:"type"`
+	Title            *string               `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes            *string               `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs         []FlatFileRef         `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths        map[string]string     `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields     CustomFields          `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection   *FlatHostConnection   `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	DirectoryType    *string               `mapstructure:"directory_type" cty:"directory_type" hcl:"directory_type"`
+	DomainName       *string               `mapstructure:"domain_name" cty:"domain_name" hcl:"domain_name"`
+	SSLVerification  *bool                 `mapstructure:"ssl_verification" cty:"ssl_verification" hcl:"ssl_verification"`
+	ProviderGroup    *string               `mapstructure:"provider_group" cty:"provider_group" hcl:"provider_group"`
+	ProviderRegion   *string               `mapstructure:"provider_region" cty:"provider_region" hcl:"provider_region"`
+	RotationSchedule *FlatRotationSchedule `mapstructure:"rotation_schedule" cty:"rotation_schedule" hcl:"rotation_schedule"`
+	Users            []FlatPamUser         `mapstructure:"users" cty:"users" hcl:"users"`
+}
+
+// FlatMapstructure returns a new FlatKeeperPamDirectory.
+// FlatKeeperPamDirectory is an auto-generated flat version of KeeperPamDirectory.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperPamDirectory) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperPamDirectory)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperPamDirectory.
+// This spec is used by HCL to read the fields of KeeperPamDirectory.
+// The decoded values from this spec will then be applied to a FlatKeeperPamDirectory.
+func (*FlatKeeperPamDirectory) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":                &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":               &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
+		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"directory_type":     &hcldec.AttrSpec{Name: "directory_type", Type: cty.String, Required: false},
+		"domain_name":        &hcldec.AttrSpec{Name: "domain_name", Type: cty.String, Required: false},
+		"ssl_verification":   &hcldec.AttrSpec{Name: "ssl_verification", Type: cty.Bool, Required: false},
+		"provider_group":     &hcldec.AttrSpec{Name: "provider_group", Type: cty.String, Required: false},
+		"provider_region":    &hcldec.AttrSpec{Name: "provider_region", Type: cty.String, Required: false},
+		"rotation_schedule":  &hcldec.BlockSpec{TypeName: "rotation_schedule", Nested: hcldec.ObjectSpec((*FlatRotationSchedule)(nil).HCL2Spec())},
+		"users":              &hcldec.BlockListSpec{TypeName: "users", Nested: hcldec.ObjectSpec((*FlatPamUser)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatKeeperPamMachine is an auto-generated flat version of KeeperPamMachine.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperPamMachine struct {
+	Uid              *string               `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type             *string               `mapstructure:"type" cty:"type" hcl:"type"`
+	Title            *string               `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes            *string               `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs         []FlatFileRef         `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths        map[string]string     `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields     CustomFields          `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection   *FlatHostConnection   `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	OperatingSystem  *string               `mapstructure:"operating_system" cty:"operating_system" hcl:"operating_system"`
+	SSLVerification  *bool                 `mapstructure:"ssl_verification" cty:"ssl_verification" hcl:"ssl_verification"`
+	ProviderGroup    *string               `mapstructure:"provider_group" cty:"provider_group" hcl:"provider_group"`
+	ProviderRegion   *string               `mapstructure:"provider_region" cty:"provider_region" hcl:"provider_region"`
+	RotationSchedule *FlatRotationSchedule `mapstructure:"rotation_schedule" cty:"rotation_schedule" hcl:"rotation_schedule"`
+	Users            []FlatPamUser         `mapstructure:"users" cty:"users" hcl:"users"`
+}
+
+// FlatMapstructure returns a new FlatKeeperPamMachine.
+// FlatKeeperPamMachine is an auto-generated flat version of KeeperPamMachine.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperPamMachine) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperPamMachine)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperPamMachine.
+// This spec is used by HCL to read the fields of KeeperPamMachine.
+// The decoded values from this spec will then be applied to a FlatKeeperPamMachine.
+func (*FlatKeeperPamMachine) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":                &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":               &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
+		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"operating_system":   &hcldec.AttrSpec{Name: "operating_system", Type: cty.String, Required: false},
+		"ssl_verification":   &hcldec.AttrSpec{Name: "ssl_verification", Type: cty.Bool, Required: false},
+		"provider_group":     &hcldec.AttrSpec{Name: "provider_group", Type: cty.String, Required: false},
+		"provider_region":    &hcldec.AttrSpec{Name: "provider_region", Type: cty.String, Required: false},
+		"rotation_schedule":  &hcldec.BlockSpec{TypeName: "rotation_schedule", Nested: hcldec.ObjectSpec((*FlatRotationSchedule)(nil).HCL2Spec())},
+		"users":              &hcldec.BlockListSpec{TypeName: "users", Nested: hcldec.ObjectSpec((*FlatPamUser)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatKeeperPamUser is an auto-generated flat version of KeeperPamUser.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperPamUser struct {
+	Uid               *string               `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type              *string               `mapstructure:"type" cty:"type" hcl:"type"`
+	Title             *string               `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes             *string               `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs          []FlatFileRef         `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths         map[string]string     `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields      CustomFields          `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Login             *string               `mapstructure:"login" cty:"login" hcl:"login"`
+	Password          *string               `mapstructure:"password" cty:"password" hcl:"password"`
+	PrivateKey        *string               `mapstructure:"private_key" cty:"private_key" hcl:"private_key"`
+	DistinguishedName *string               `mapstructure:"distinguished_name" cty:"distinguished_name" hcl:"distinguished_name"`
+	ConnectDatabase   *string               `mapstructure:"connect_database" cty:"connect_database" hcl:"connect_database"`
+	Managed           *bool                 `mapstructure:"managed" cty:"managed" hcl:"managed"`
+	RotationSchedule  *FlatRotationSchedule `mapstructure:"rotation_schedule" cty:"rotation_schedule" hcl:"rotation_schedule"`
+}
+
+// FlatMapstructure returns a new FlatKeeperPamUser.
+// FlatKeeperPamUser is an auto-generated flat version of KeeperPamUser.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperPamUser) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperPamUser)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperPamUser.
+// This spec is used by HCL to read the fields of KeeperPamUser.
+// The decoded values from this spec will then be applied to a FlatKeeperPamUser.
+func (*FlatKeeperPamUser) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":                &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":               &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
+		"login":              &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
+		"password":           &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
+		"private_key":        &hcldec.AttrSpec{Name: "private_key", Type: cty.String, Required: false},
+		"distinguished_name": &hcldec.AttrSpec{Name: "distinguished_name", Type: cty.String, Required: false},
+		"connect_database":   &hcldec.AttrSpec{Name: "connect_database", Type: cty.String, Required: false},
+		"managed":            &hcldec.AttrSpec{Name: "managed", Type: cty.Bool, Required: false},
+		"rotation_schedule":  &hcldec.BlockSpec{TypeName: "rotation_schedule", Nested: hcldec.ObjectSpec((*FlatRotationSchedule)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatKeeperRecord is an auto-generated flat version of KeeperRecord.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatKeeperRecord struct {
+	Uid          *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type         *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title        *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes        *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs     []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Fields       []FlatRecordField `mapstructure:"fields" cty:"fields" hcl:"fields"`
+	FieldMap     map[string]string `mapstructure:"field_map" cty:"field_map" hcl:"field_map"`
+}
+
+// FlatMapstructure returns a new FlatKeeperRecord.
+// FlatKeeperRecord is an auto-generated flat version of KeeperRecord.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*KeeperRecord) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatKeeperRecord)
+}
+
+// HCL2Spec returns the hcl spec of a KeeperRecord.
+// This spec is used by HCL to read the fields of KeeperRecord.
+// The decoded values from this spec will then be applied to a FlatKeeperRecord.
+func (*FlatKeeperRecord) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":           &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":          &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&CustomFields{}).HCL2Spec(),
+		"fields":        &hcldec.BlockListSpec{TypeName: "fields", Nested: hcldec.ObjectSpec((*FlatRecordField)(nil).HCL2Spec())},
+		"field_map":     &hcldec.AttrSpec{Name: "field_map", Type: cty.Map(cty.String), Required: false},
 	}
 	return s
 }
@@ -235,11 +733,13 @@ func (*FlatKeeperLogin) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperRecordField is an auto-generated flat version of KeeperRecordField.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperRecordField struct {
-	Uid      *string       `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type     *string       `mapstructure:"type" cty:"type" hcl:"type"`
-	Title    *string       `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes    *string       `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs []FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	Uid          *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type         *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title        *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes        *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs     []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths    map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
 }
 
 // FlatMapstructure returns a new FlatKeeperRecordField.
@@ -254,11 +754,13 @@ func (*KeeperRecordField) FlatMapstructure() interface{ HCL2Spec() map[string]hc
 // The decoded values from this spec will then be applied to a FlatKeeperRecordField.
 func (*FlatKeeperRecordField) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"uid":       &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
-		"type":      &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
-		"title":     &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
-		"notes":     &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
-		"file_refs": &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"uid":           &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"type":          &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"title":         &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"notes":         &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
+		"file_refs":     &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":    &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields": (&CustomFields{}).HCL2Spec(),
 	}
 	return s
 }
@@ -266,15 +768,19 @@ func (*FlatKeeperRecordField) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperSSHKey is an auto-generated flat version of KeeperSSHKey.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperSSHKey struct {
-	Uid            *string             `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type           *string             `mapstructure:"type" cty:"type" hcl:"type"`
-	Title          *string             `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes          *string             `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs       []FlatFileRef       `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	Login          *string             `mapstructure:"login" cty:"login" hcl:"login"`
-	Passphrase     *string             `mapstructure:"passphrase" cty:"passphrase" hcl:"passphrase"`
-	KeyPair        *FlatKeyPair        `mapstructure:"key_pair" cty:"key_pair" hcl:"key_pair"`
-	HostConnection *FlatHostConnection `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	Uid            *string              `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type           *string              `mapstructure:"type" cty:"type" hcl:"type"`
+	Title          *string              `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes          *string              `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs       []FlatFileRef        `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string    `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   CustomFields         `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	Login          *string              `mapstructure:"login" cty:"login" hcl:"login"`
+	Passphrase     *string              `mapstructure:"passphrase" cty:"passphrase" hcl:"passphrase"`
+	KeyPair        *FlatKeyPair         `mapstructure:"key_pair" cty:"key_pair" hcl:"key_pair"`
+	HostConnection *FlatHostConnection  `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	KeyPairs       []FlatKeyPair        `mapstructure:"key_pairs" cty:"key_pairs" hcl:"key_pairs"`
+	Hosts          []FlatHostConnection `mapstructure:"hosts" cty:"hosts" hcl:"hosts"`
 }
 
 // FlatMapstructure returns a new FlatKeeperSSHKey.
@@ -294,10 +800,14 @@ func (*FlatKeeperSSHKey) HCL2Spec() map[string]hcldec.Spec {
 		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
 		"login":              &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
 		"passphrase":         &hcldec.AttrSpec{Name: "passphrase", Type: cty.String, Required: false},
 		"key_pair":           &hcldec.BlockSpec{TypeName: "key_pair", Nested: hcldec.ObjectSpec((*FlatKeyPair)(nil).HCL2Spec())},
 		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"key_pairs":          &hcldec.BlockListSpec{TypeName: "key_pairs", Nested: hcldec.ObjectSpec((*FlatKeyPair)(nil).HCL2Spec())},
+		"hosts":              &hcldec.BlockListSpec{TypeName: "hosts", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -305,14 +815,17 @@ func (*FlatKeeperSSHKey) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperServerCredentials is an auto-generated flat version of KeeperServerCredentials.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperServerCredentials struct {
-	Uid            *string             `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type           *string             `mapstructure:"type" cty:"type" hcl:"type"`
-	Title          *string             `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes          *string             `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs       []FlatFileRef       `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	HostConnection *FlatHostConnection `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
-	Login          *string             `mapstructure:"login" cty:"login" hcl:"login"`
-	Password       *string             `mapstructure:"password" cty:"password" hcl:"password"`
+	Uid            *string              `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type           *string              `mapstructure:"type" cty:"type" hcl:"type"`
+	Title          *string              `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes          *string              `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs       []FlatFileRef        `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string    `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   CustomFields         `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	HostConnection *FlatHostConnection  `mapstructure:"connection_details" cty:"connection_details" hcl:"connection_details"`
+	Hosts          []FlatHostConnection `mapstructure:"hosts" cty:"hosts" hcl:"hosts"`
+	Login          *string              `mapstructure:"login" cty:"login" hcl:"login"`
+	Password       *string              `mapstructure:"password" cty:"password" hcl:"password"`
 }
 
 // FlatMapstructure returns a new FlatKeeperServerCredentials.
@@ -332,7 +845,10 @@ func (*FlatKeeperServerCredentials) HCL2Spec() map[string]hcldec.Spec {
 		"title":              &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":              &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":          &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":         &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":      (&CustomFields{}).HCL2Spec(),
 		"connection_details": &hcldec.BlockSpec{TypeName: "connection_details", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
+		"hosts":              &hcldec.BlockListSpec{TypeName: "hosts", Nested: hcldec.ObjectSpec((*FlatHostConnection)(nil).HCL2Spec())},
 		"login":              &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
 		"password":           &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
 	}
@@ -342,14 +858,16 @@ func (*FlatKeeperServerCredentials) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeeperSoftwareLicense is an auto-generated flat version of KeeperSoftwareLicense.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeeperSoftwareLicense struct {
-	Uid            *string       `mapstructure:"uid" cty:"uid" hcl:"uid"`
-	Type           *string       `mapstructure:"type" cty:"type" hcl:"type"`
-	Title          *string       `mapstructure:"title" cty:"title" hcl:"title"`
-	Notes          *string       `mapstructure:"notes" cty:"notes" hcl:"notes"`
-	FileRefs       []FlatFileRef `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
-	LicenseNumber  *string       `mapstructure:"license_number" cty:"license_number" hcl:"license_number"`
-	ActivationDate *string       `mapstructure:"activation_date" cty:"activation_date" hcl:"activation_date"`
-	ExpirationDate *string       `mapstructure:"expiration_date" cty:"expiration_date" hcl:"expiration_date"`
+	Uid            *string           `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Type           *string           `mapstructure:"type" cty:"type" hcl:"type"`
+	Title          *string           `mapstructure:"title" cty:"title" hcl:"title"`
+	Notes          *string           `mapstructure:"notes" cty:"notes" hcl:"notes"`
+	FileRefs       []FlatFileRef     `mapstructure:"file_refs" cty:"file_refs" hcl:"file_refs"`
+	FilePaths      map[string]string `mapstructure:"file_paths" cty:"file_paths" hcl:"file_paths"`
+	CustomFields   CustomFields      `mapstructure:"custom_fields" mapstructure-to-hcl2:",self-defined" cty:"custom_fields" hcl:"custom_fields"`
+	LicenseNumber  *string           `mapstructure:"license_number" cty:"license_number" hcl:"license_number"`
+	ActivationDate *string           `mapstructure:"activation_date" cty:"activation_date" hcl:"activation_date"`
+	ExpirationDate *string           `mapstructure:"expiration_date" cty:"expiration_date" hcl:"expiration_date"`
 }
 
 // FlatMapstructure returns a new FlatKeeperSoftwareLicense.
@@ -369,6 +887,8 @@ func (*FlatKeeperSoftwareLicense) HCL2Spec() map[string]hcldec.Spec {
 		"title":           &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
 		"notes":           &hcldec.AttrSpec{Name: "notes", Type: cty.String, Required: false},
 		"file_refs":       &hcldec.BlockListSpec{TypeName: "file_refs", Nested: hcldec.ObjectSpec((*FlatFileRef)(nil).HCL2Spec())},
+		"file_paths":      &hcldec.AttrSpec{Name: "file_paths", Type: cty.Map(cty.String), Required: false},
+		"custom_fields":   (&CustomFields{}).HCL2Spec(),
 		"license_number":  &hcldec.AttrSpec{Name: "license_number", Type: cty.String, Required: false},
 		"activation_date": &hcldec.AttrSpec{Name: "activation_date", Type: cty.String, Required: false},
 		"expiration_date": &hcldec.AttrSpec{Name: "expiration_date", Type: cty.String, Required: false},
@@ -379,8 +899,15 @@ func (*FlatKeeperSoftwareLicense) HCL2Spec() map[string]hcldec.Spec {
 // FlatKeyPair is an auto-generated flat version of KeyPair.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatKeyPair struct {
-	PublicKey  *string `mapstructure:"public_key" cty:"public_key" hcl:"public_key"`
-	PrivateKey *string `mapstructure:"private_key" cty:"private_key" hcl:"private_key"`
+	PublicKey         *string `mapstructure:"public_key" cty:"public_key" hcl:"public_key"`
+	PrivateKey        *string `mapstructure:"private_key" cty:"private_key" hcl:"private_key"`
+	KeyType           *string `mapstructure:"key_type" cty:"key_type" hcl:"key_type"`
+	Bits              *int    `mapstructure:"bits" cty:"bits" hcl:"bits"`
+	FingerprintSHA256 *string `mapstructure:"fingerprint_sha256" cty:"fingerprint_sha256" hcl:"fingerprint_sha256"`
+	FingerprintMD5    *string `mapstructure:"fingerprint_md5" cty:"fingerprint_md5" hcl:"fingerprint_md5"`
+	PrivateKeyOpenSSH *string `mapstructure:"private_key_openssh" cty:"private_key_openssh" hcl:"private_key_openssh"`
+	PrivateKeyPKCS8   *string `mapstructure:"private_key_pkcs8" cty:"private_key_pkcs8" hcl:"private_key_pkcs8"`
+	PrivateKeyPEM     *string `mapstructure:"private_key_pem" cty:"private_key_pem" hcl:"private_key_pem"`
 }
 
 // FlatMapstructure returns a new FlatKeyPair.
@@ -395,8 +922,141 @@ func (*KeyPair) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec
 // The decoded values from this spec will then be applied to a FlatKeyPair.
 func (*FlatKeyPair) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"public_key":  &hcldec.AttrSpec{Name: "public_key", Type: cty.String, Required: false},
+		"public_key":          &hcldec.AttrSpec{Name: "public_key", Type: cty.String, Required: false},
+		"private_key":         &hcldec.AttrSpec{Name: "private_key", Type: cty.String, Required: false},
+		"key_type":            &hcldec.AttrSpec{Name: "key_type", Type: cty.String, Required: false},
+		"bits":                &hcldec.AttrSpec{Name: "bits", Type: cty.Number, Required: false},
+		"fingerprint_sha256":  &hcldec.AttrSpec{Name: "fingerprint_sha256", Type: cty.String, Required: false},
+		"fingerprint_md5":     &hcldec.AttrSpec{Name: "fingerprint_md5", Type: cty.String, Required: false},
+		"private_key_openssh": &hcldec.AttrSpec{Name: "private_key_openssh", Type: cty.String, Required: false},
+		"private_key_pkcs8":   &hcldec.AttrSpec{Name: "private_key_pkcs8", Type: cty.String, Required: false},
+		"private_key_pem":     &hcldec.AttrSpec{Name: "private_key_pem", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatPamUser is an auto-generated flat version of PamUser.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatPamUser struct {
+	Uid        *string `mapstructure:"uid" cty:"uid" hcl:"uid"`
+	Title      *string `mapstructure:"title" cty:"title" hcl:"title"`
+	Login      *string `mapstructure:"login" cty:"login" hcl:"login"`
+	Password   *string `mapstructure:"password" cty:"password" hcl:"password"`
+	PrivateKey *string `mapstructure:"private_key" cty:"private_key" hcl:"private_key"`
+}
+
+// FlatMapstructure returns a new FlatPamUser.
+// FlatPamUser is an auto-generated flat version of PamUser.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*PamUser) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatPamUser)
+}
+
+// HCL2Spec returns the hcl spec of a PamUser.
+// This spec is used by HCL to read the fields of PamUser.
+// The decoded values from this spec will then be applied to a FlatPamUser.
+func (*FlatPamUser) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"uid":         &hcldec.AttrSpec{Name: "uid", Type: cty.String, Required: false},
+		"title":       &hcldec.AttrSpec{Name: "title", Type: cty.String, Required: false},
+		"login":       &hcldec.AttrSpec{Name: "login", Type: cty.String, Required: false},
+		"password":    &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
 		"private_key": &hcldec.AttrSpec{Name: "private_key", Type: cty.String, Required: false},
 	}
 	return s
 }
+
+// FlatRecordField is an auto-generated flat version of RecordField.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatRecordField struct {
+	Type   *string  `mapstructure:"type" cty:"type" hcl:"type"`
+	Label  *string  `mapstructure:"label" cty:"label" hcl:"label"`
+	Custom *bool    `mapstructure:"custom" cty:"custom" hcl:"custom"`
+	Values []string `mapstructure:"values" cty:"values" hcl:"values"`
+}
+
+// FlatMapstructure returns a new FlatRecordField.
+// FlatRecordField is an auto-generated flat version of RecordField.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*RecordField) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatRecordField)
+}
+
+// HCL2Spec returns the hcl spec of a RecordField.
+// This spec is used by HCL to read the fields of RecordField.
+// The decoded values from this spec will then be applied to a FlatRecordField.
+func (*FlatRecordField) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"type":   &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"label":  &hcldec.AttrSpec{Name: "label", Type: cty.String, Required: false},
+		"custom": &hcldec.AttrSpec{Name: "custom", Type: cty.Bool, Required: false},
+		"values": &hcldec.AttrSpec{Name: "values", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatRotationSchedule is an auto-generated flat version of RotationSchedule.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatRotationSchedule struct {
+	Type          *string `mapstructure:"type" cty:"type" hcl:"type"`
+	Cron          *string `mapstructure:"cron" cty:"cron" hcl:"cron"`
+	Time          *string `mapstructure:"time" cty:"time" hcl:"time"`
+	Timezone      *string `mapstructure:"timezone" cty:"timezone" hcl:"timezone"`
+	Weekday       *string `mapstructure:"weekday" cty:"weekday" hcl:"weekday"`
+	IntervalCount *int    `mapstructure:"interval_count" cty:"interval_count" hcl:"interval_count"`
+}
+
+// FlatMapstructure returns a new FlatRotationSchedule.
+// FlatRotationSchedule is an auto-generated flat version of RotationSchedule.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*RotationSchedule) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatRotationSchedule)
+}
+
+// HCL2Spec returns the hcl spec of a RotationSchedule.
+// This spec is used by HCL to read the fields of RotationSchedule.
+// The decoded values from this spec will then be applied to a FlatRotationSchedule.
+func (*FlatRotationSchedule) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"type":           &hcldec.AttrSpec{Name: "type", Type: cty.String, Required: false},
+		"cron":           &hcldec.AttrSpec{Name: "cron", Type: cty.String, Required: false},
+		"time":           &hcldec.AttrSpec{Name: "time", Type: cty.String, Required: false},
+		"timezone":       &hcldec.AttrSpec{Name: "timezone", Type: cty.String, Required: false},
+		"weekday":        &hcldec.AttrSpec{Name: "weekday", Type: cty.String, Required: false},
+		"interval_count": &hcldec.AttrSpec{Name: "interval_count", Type: cty.Number, Required: false},
+	}
+	return s
+}
+
+// FlatTOTP is an auto-generated flat version of TOTP.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatTOTP struct {
+	Issuer      *string `mapstructure:"issuer" cty:"issuer" hcl:"issuer"`
+	AccountName *string `mapstructure:"account_name" cty:"account_name" hcl:"account_name"`
+	Algorithm   *string `mapstructure:"algorithm" cty:"algorithm" hcl:"algorithm"`
+	Digits      *int    `mapstructure:"digits" cty:"digits" hcl:"digits"`
+	Period      *int    `mapstructure:"period" cty:"period" hcl:"period"`
+	Secret      *string `mapstructure:"secret" cty:"secret" hcl:"secret"`
+}
+
+// FlatMapstructure returns a new FlatTOTP.
+// FlatTOTP is an auto-generated flat version of TOTP.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*TOTP) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatTOTP)
+}
+
+// HCL2Spec returns the hcl spec of a TOTP.
+// This spec is used by HCL to read the fields of TOTP.
+// The decoded values from this spec will then be applied to a FlatTOTP.
+func (*FlatTOTP) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"issuer":       &hcldec.AttrSpec{Name: "issuer", Type: cty.String, Required: false},
+		"account_name": &hcldec.AttrSpec{Name: "account_name", Type: cty.String, Required: false},
+		"algorithm":    &hcldec.AttrSpec{Name: "algorithm", Type: cty.String, Required: false},
+		"digits":       &hcldec.AttrSpec{Name: "digits", Type: cty.Number, Required: false},
+		"period":       &hcldec.AttrSpec{Name: "period", Type: cty.Number, Required: false},
+		"secret":       &hcldec.AttrSpec{Name: "secret", Type: cty.String, Required: false},
+	}
+	return s
+}
@@ -49,3 +49,31 @@ data "keeper-database-credential" "my_database_credential" {
 data "keeper-encrypted-note" "my_encrypted_note" {
   uid = "my-uid"
 }
+
+// Retrieve any record type with all of its fields
+data "keeper-record" "my_record" {
+  uid = "my-uid"
+}
+
+// Retrieve values from any record using Keeper notation
+data "keeper-notation" "my_notation" {
+  notations = {
+    password = "keeper://my-uid/field/password"
+  }
+}
+
+// Retrieve a PAM machine record along with its linked users
+data "keeper-pam-machine" "my_pam_machine" {
+  uid = "my-uid"
+}
+
+// Retrieve a PAM user record
+data "keeper-pam-user" "my_pam_user" {
+  uid = "my-uid"
+}
+
+// Retrieve the certificate attached to a record, failing the build when it expires within 30 days
+data "keeper-certificate" "my_certificate" {
+  uid            = "my-uid"
+  min_days_valid = 30
+}
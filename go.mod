@@ -8,6 +8,8 @@ require (
 	github.com/keeper-security/secrets-manager-go/core v1.7.0
 	github.com/stretchr/testify v1.11.1
 	github.com/zclconf/go-cty v1.16.3
+	golang.org/x/crypto v0.46.0
+	software.sslmate.com/src/go-pkcs12 v0.7.3
 )
 
 require (
@@ -7,11 +7,19 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource"
 	keeper_api_key "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-api-key"
+	keeper_certificate "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-certificate"
 	keeper_database_credentials "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-database-credentials"
 	keeper_encrypted_note "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-encrypted-note"
 	keeper_file "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-file"
 	keeper_login "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-login"
+	keeper_notation "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-notation"
+	keeper_pam_database "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-database"
+	keeper_pam_directory "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-directory"
+	keeper_pam_machine "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-machine"
+	keeper_pam_user "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-pam-user"
+	keeper_record "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-record"
 	keeper_server_credentials "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-server-credentials"
 	keeper_software_license "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-software-license"
 	keeper_ssh_key "github.com/aidanleuck/packer-plugin-keeper/datasource/keeper_datasource/keeper-ssh-key"
@@ -21,6 +29,18 @@ import (
 )
 
 func main() {
+	// Removes the attachments written to disk once the plugin process starting it exits.
+	if len(os.Args) > 1 && os.Args[1] == keeper_datasource.FileCleanupCommand {
+		os.Exit(keeper_datasource.RunFileCleanup(os.Stdin))
+	}
+
+	// Config helper commands are run by users directly and never by Packer.
+	if len(os.Args) > 1 {
+		if _, ok := configCommands[os.Args[1]]; ok {
+			os.Exit(runConfigCommand(os.Args[1], os.Args[2:]))
+		}
+	}
+
 	pps := plugin.NewSet()
 	pps.RegisterDatasource("login", new(keeper_login.Datasource))
 	pps.RegisterDatasource("software-license", new(keeper_software_license.Datasource))
@@ -30,9 +50,20 @@ func main() {
 	pps.RegisterDatasource("api-key", new(keeper_api_key.Datasource))
 	pps.RegisterDatasource("database-credential", new(keeper_database_credentials.Datasource))
 	pps.RegisterDatasource("server-credential", new(keeper_server_credentials.Datasource))
+	pps.RegisterDatasource("notation", new(keeper_notation.Datasource))
+	pps.RegisterDatasource("record", new(keeper_record.Datasource))
+	pps.RegisterDatasource("pam-machine", new(keeper_pam_machine.Datasource))
+	pps.RegisterDatasource("pam-database", new(keeper_pam_database.Datasource))
+	pps.RegisterDatasource("pam-directory", new(keeper_pam_directory.Datasource))
+	pps.RegisterDatasource("pam-user", new(keeper_pam_user.Datasource))
+	pps.RegisterDatasource("certificate", new(keeper_certificate.Datasource))
 
 	pps.SetVersion(version.PluginVersion)
 	err := pps.Run()
+
+	// Attachments written to disk only live as long as the build using them. Packer usually kills
+	// the plugin instead, the cleanup process removes them then.
+	keeper_datasource.CleanupFiles()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)